@@ -1,40 +1,49 @@
 package client
 
 import (
+	"adfs/common"
 	"adfs/helpers"
 	m "adfs/messages"
 	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
 type Actions interface {
-	Upload(localDirname, remoteDirname string)
+	Upload(localDirname, remoteDirname string, replication int, storagePolicy, codec string)
 	Download(localDirname, remoteDirname string)
 	Delete(filename string)
-	List() ([]*m.File, error)
-	GetClusterStats() ([]*m.Node, error)
-	Compute(localJobName, remoteFilename, outputFilename string)
+	List() ([]*m.File, []*m.Quota, error)
+	GetClusterStats() ([]*m.Node, *m.SafeMode, error)
+	Compute(localJobName, remoteFilename, outputFilename string, job *m.Plugin)
+	SetReplication(remoteFilename string, replication int)
+	SetStoragePolicy(remoteDirname, storagePolicy string)
+	Decommission(node string)
+	SetSafeMode(action string)
+	Fsck(path string, repair bool)
+	SetQuota(remoteDirname string, maxFiles, maxBytes int64)
 }
 
 type ActionsImpl struct {
-	controllerAddr string
-	storageDir     string
+	controllerAddrs []string
+	storageDir      string
 }
 
-func NewActions(controllerAddr, storageDir string) Actions {
-	return &ActionsImpl{controllerAddr, storageDir}
+func NewActions(controllerAddrs []string, storageDir string) Actions {
+	return &ActionsImpl{controllerAddrs, storageDir}
 }
 
-func (a *ActionsImpl) Upload(localDirname, remoteDirname string) {
-	msgHandler, err := m.GetMessageHandlerFor(a.controllerAddr)
+func (a *ActionsImpl) Upload(localDirname, remoteDirname string, replication int, storagePolicy, codec string) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
 	if err != nil {
 		dialog(fail(CONNECTION_ERROR_MSG))
 		return
 	}
-	msgHandler.SendPUTRequest(remoteDirname)
+	msgHandler.SendPUTRequest(remoteDirname, int32(replication), storagePolicy, int64(getFileSize(localDirname)))
 	wrapper, _ := msgHandler.Receive()
 
 	switch msg := wrapper.Msg.(type) {
@@ -43,9 +52,13 @@ func (a *ActionsImpl) Upload(localDirname, remoteDirname string) {
 		dialog("\n\n\t❌" + errorMsg + " ❌")
 	case *m.Wrapper_StorageNodesMessage:
 		storageNodes := msg.StorageNodesMessage.Nodes
-		chunkinator := NewChunkinator(localDirname, remoteDirname)
+		chunkinator, err := NewChunkinatorFor(localDirname, remoteDirname, int32(replication), msg.StorageNodesMessage.StoragePolicy, codec)
+		if err != nil {
+			dialogAppend(fail("Upload error! " + err.Error()))
+			return
+		}
 		uploader := NewUploader(storageNodes, chunkinator)
-		err := uploader.Upload()
+		err = uploader.Upload()
 		if err != nil {
 			dialogAppend(fail("Upload error! " + err.Error()))
 		} else {
@@ -61,7 +74,7 @@ func (a *ActionsImpl) Upload(localDirname, remoteDirname string) {
 }
 
 func (a *ActionsImpl) Download(saveAs, remoteDirname string) {
-	msgHandler, err := m.GetMessageHandlerFor(a.controllerAddr)
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
 	if err != nil {
 		dialog(fail(CONNECTION_ERROR_MSG))
 		return
@@ -94,7 +107,7 @@ func (a *ActionsImpl) Download(saveAs, remoteDirname string) {
 }
 
 func (a *ActionsImpl) Delete(remoteFilename string) {
-	msgHandler, err := m.GetMessageHandlerFor(a.controllerAddr)
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
 	if err != nil {
 		dialog(fail(err.Error()))
 		return
@@ -116,45 +129,211 @@ func (a *ActionsImpl) Delete(remoteFilename string) {
 	}
 }
 
-func (a *ActionsImpl) List() ([]*m.File, error) {
-	msgHandler, err := m.GetMessageHandlerFor(a.controllerAddr)
+func (a *ActionsImpl) SetReplication(remoteFilename string, replication int) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
 	if err != nil {
-		return nil, err
+		dialog(fail(CONNECTION_ERROR_MSG))
+		return
+	}
+	defer msgHandler.Close()
+	msgHandler.SendSetRepRequest(remoteFilename, int32(replication))
+	wrapper, _ := msgHandler.Receive()
+
+	switch msg := wrapper.Msg.(type) {
+	case *m.Wrapper_AckMessage:
+		if msg.AckMessage.Ok {
+			dialog(success("Replication factor updated"))
+		} else {
+			dialog(fail(msg.AckMessage.ErrorMessage))
+		}
+	default:
+		dialog(centered("Unrecognized response from server"))
+	}
+}
+
+func (a *ActionsImpl) SetStoragePolicy(remoteDirname, storagePolicy string) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
+	if err != nil {
+		dialog(fail(CONNECTION_ERROR_MSG))
+		return
+	}
+	defer msgHandler.Close()
+	msgHandler.SendSetPolicyRequest(remoteDirname, storagePolicy)
+	wrapper, _ := msgHandler.Receive()
+
+	switch msg := wrapper.Msg.(type) {
+	case *m.Wrapper_AckMessage:
+		if msg.AckMessage.Ok {
+			dialog(success("Storage policy updated"))
+		} else {
+			dialog(fail(msg.AckMessage.ErrorMessage))
+		}
+	default:
+		dialog(centered("Unrecognized response from server"))
+	}
+}
+
+/** 0 lifts a limit; lifting both removes the quota */
+func (a *ActionsImpl) SetQuota(remoteDirname string, maxFiles, maxBytes int64) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
+	if err != nil {
+		dialog(fail(CONNECTION_ERROR_MSG))
+		return
+	}
+	defer msgHandler.Close()
+	msgHandler.SendSetQuotaRequest(remoteDirname, maxFiles, maxBytes)
+	wrapper, _ := msgHandler.Receive()
+
+	switch msg := wrapper.Msg.(type) {
+	case *m.Wrapper_AckMessage:
+		if msg.AckMessage.Ok {
+			dialog(success("Quota updated"))
+		} else {
+			dialog(fail(msg.AckMessage.ErrorMessage))
+		}
+	default:
+		dialog(centered("Unrecognized response from server"))
+	}
+}
+
+/** Starts draining the node and follows its progress until it can be shut down */
+func (a *ActionsImpl) Decommission(node string) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
+	if err != nil {
+		dialog(fail(CONNECTION_ERROR_MSG))
+		return
+	}
+	msgHandler.SendDecommissionRequest(node)
+	wrapper, _ := msgHandler.Receive()
+	msgHandler.Close()
+
+	switch msg := wrapper.Msg.(type) {
+	case *m.Wrapper_AckMessage:
+		if !msg.AckMessage.Ok {
+			dialog(fail(msg.AckMessage.ErrorMessage))
+			return
+		}
+	default:
+		dialog(centered("Unrecognized response from server"))
+		return
+	}
+
+	helpers.ClearTerminal()
+	for {
+		storageNodes, _, err := a.GetClusterStats()
+		if err != nil {
+			dialog(fail(err.Error()))
+			return
+		}
+		var sn *m.Node
+		for _, n := range storageNodes {
+			if n.Uuid == node {
+				sn = n
+			}
+		}
+		if sn == nil {
+			dialog(fail("Storage Node went offline before being drained"))
+			return
+		}
+		if sn.State == common.NODE_DECOMMISSIONED {
+			dialog(success("Storage Node drained. Safe to shut down"))
+			return
+		}
+		fmt.Printf("\rDraining %s... %d chunks left   ", node, sn.ChunksToDrain)
+		<-time.After(DECOMMISSION_POLL_S * time.Second)
+	}
+}
+
+func (a *ActionsImpl) Fsck(path string, repair bool) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
+	if err != nil {
+		dialog(fail(CONNECTION_ERROR_MSG))
+		return
+	}
+	defer msgHandler.Close()
+	msgHandler.SendFsckRequest(path, repair)
+	wrapper, _ := msgHandler.Receive()
+
+	switch msg := wrapper.Msg.(type) {
+	case *m.Wrapper_FsckReportMessage:
+		helpers.ClearTerminal()
+		printFsckReport(path, msg.FsckReportMessage)
+		selectPrompt("FSCK "+path, []*Item{{displayName: MAIN_MENU}}, 0)
+	case *m.Wrapper_AckMessage:
+		dialog(fail(msg.AckMessage.ErrorMessage))
+	default:
+		dialog(centered("Unrecognized response from server"))
+	}
+}
+
+func (a *ActionsImpl) List() ([]*m.File, []*m.Quota, error) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
+	if err != nil {
+		return nil, nil, err
 	}
 	msgHandler.SendLSRequest()
 	wrapper, _ := msgHandler.Receive()
 
 	switch msg := wrapper.Msg.(type) {
 	case *m.Wrapper_FilesMessage:
-		return msg.FilesMessage.Files, nil
+		return msg.FilesMessage.Files, msg.FilesMessage.Quotas, nil
 	default:
-		return nil, errors.New("something went wrong retrieving files")
+		return nil, nil, errors.New("something went wrong retrieving files")
 	}
 }
 
-func (a *ActionsImpl) GetClusterStats() ([]*m.Node, error) {
-	msgHandler, err := m.GetMessageHandlerFor(a.controllerAddr)
+func (a *ActionsImpl) GetClusterStats() ([]*m.Node, *m.SafeMode, error) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
 	if err != nil {
-		return nil, errors.New("A-DFS is not online")
+		return nil, nil, errors.New("A-DFS is not online")
 	}
 	msgHandler.SendClusterStatsRequest()
 	wrapper, _ := msgHandler.Receive()
 
 	switch msg := wrapper.Msg.(type) {
 	case *m.Wrapper_StorageNodesMessage:
-		return msg.StorageNodesMessage.Nodes, nil
+		return msg.StorageNodesMessage.Nodes, msg.StorageNodesMessage.SafeMode, nil
 	}
 
-	return nil, errors.New("something went wrong")
+	return nil, nil, errors.New("something went wrong")
 }
 
-func (a *ActionsImpl) Compute(localJobName, remoteFilename, outputFilename string) {
-	plugin, err := os.ReadFile(localJobName)
+func (a *ActionsImpl) SetSafeMode(action string) {
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
+	if err != nil {
+		dialog(fail(CONNECTION_ERROR_MSG))
+		return
+	}
+	defer msgHandler.Close()
+	msgHandler.SendSafeModeRequest(action)
+	wrapper, _ := msgHandler.Receive()
+
+	switch msg := wrapper.Msg.(type) {
+	case *m.Wrapper_AckMessage:
+		if !msg.AckMessage.Ok {
+			dialog(fail(msg.AckMessage.ErrorMessage))
+		} else if action == common.SAFE_MODE_ENTER {
+			dialog(success("Safe mode on"))
+		} else {
+			dialog(success("Safe mode off"))
+		}
+	default:
+		dialog(centered("Unrecognized response from server"))
+	}
+}
+
+/**
+* job holds the job settings. Streaming jobs only carry their mapper and
+* reducer commands; otherwise localJobName is the plugin. Go source jobs are
+* built into plugins the storage nodes run in process.
+ */
+func (a *ActionsImpl) Compute(localJobName, remoteFilename, outputFilename string, job *m.Plugin) {
+	plugin, err := getPlugin(localJobName, job)
 	if err != nil {
 		dialog(fail(err.Error()))
 		return
 	}
-	msgHandler, err := m.GetMessageHandlerFor(a.controllerAddr)
+	msgHandler, err := m.GetLeaderMessageHandler(a.controllerAddrs)
 	if err != nil {
 		dialog(fail(err.Error()))
 		return
@@ -163,19 +342,24 @@ func (a *ActionsImpl) Compute(localJobName, remoteFilename, outputFilename strin
 	logrus.Info("Computation Request Initiated.")
 	msgHandler.SendComputeRequest(
 		remoteFilename,
-		"/"+helpers.GetFilename(localJobName),
-		outputFilename,
 		plugin,
+		outputFilename,
 		m.ComputeType_MAP,
 		nil, // these are reducers - determined by compute engine resource manager
 	)
+	jobId := ""
 	for {
 		wrapper, _ := msgHandler.Receive()
 		switch msg := wrapper.Msg.(type) {
 		case *m.Wrapper_ComputationStatusMessage:
 			if msg.ComputationStatusMessage.Ok {
+				if msg.ComputationStatusMessage.Status == m.JobStatus_job_queued {
+					jobId = msg.ComputationStatusMessage.JobId
+					dialogAppend("Job " + jobId + " queued, " + msg.ComputationStatusMessage.ErrorMessage)
+					continue
+				}
 				if msg.ComputationStatusMessage.Status == m.JobStatus_job_done {
-					dialog(success("Computation Job Successful"))
+					dialog(success("Computation Job " + jobId + " Successful" + combinerSummary(msg.ComputationStatusMessage.Counters)))
 					return
 				}
 				logrus.WithFields(logrus.Fields{
@@ -196,3 +380,33 @@ func (a *ActionsImpl) Compute(localJobName, remoteFilename, outputFilename strin
 		}
 	}
 }
+
+/** Combiners are optional: jobs without one report no records */
+func combinerSummary(counters *m.Counters) string {
+	if counters.GetCombineInputRecords() == 0 {
+		return ""
+	}
+	return fmt.Sprintf(". Combiners: %d records in, %d out", counters.CombineInputRecords, counters.CombineOutputRecords)
+}
+
+func getPlugin(localJobName string, job *m.Plugin) (*m.Plugin, error) {
+	if job.Streaming != nil {
+		job.Name = STREAMING_PLUGIN_NAME
+		return job, nil
+	}
+	if strings.HasSuffix(localJobName, ".go") {
+		logrus.WithFields(logrus.Fields{"Job": localJobName}).Info("Building Go plugin")
+		pluginPath, err := helpers.BuildGoPlugin(localJobName)
+		if err != nil {
+			return nil, errors.New("Plugin build error! " + err.Error())
+		}
+		localJobName = pluginPath
+	}
+	plugin, err := os.ReadFile(localJobName)
+	if err != nil {
+		return nil, err
+	}
+	job.Name = "/" + helpers.GetFilename(localJobName)
+	job.Plugin = plugin
+	return job, nil
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"adfs/common"
+	"adfs/compression"
 	m "adfs/messages"
 	"bufio"
 	"io"
@@ -16,23 +17,34 @@ type Chunkinator interface {
 type ChunkinatorImpl struct {
 	localFilename       string
 	destinationFilename string
-	numChunks           int
+	totalChunks         int32
 	serial              int32
 	offset              int
 	fileSize            int32
+	replication         int32
+	codec               string
 }
 
-func NewChunkinator(localFilename, destinationFilename string) Chunkinator {
+func NewChunkinator(localFilename, destinationFilename string, replication int32, codec string) Chunkinator {
 	c := &ChunkinatorImpl{
 		localFilename:       localFilename,
 		destinationFilename: destinationFilename,
 		serial:              0,
 		offset:              0,
+		replication:         replication,
+		codec:               codec,
 	}
 	return c
 }
 
 func (c *ChunkinatorImpl) Chunk() (*m.Chunk, error) {
+	if c.serial == 0 {
+		totalChunks, err := countChunks(c.localFilename)
+		if err != nil {
+			return nil, err
+		}
+		c.totalChunks = totalChunks
+	}
 	data, err := read(c.localFilename, int64(c.offset))
 	if err != nil {
 		return nil, err
@@ -40,14 +52,26 @@ func (c *ChunkinatorImpl) Chunk() (*m.Chunk, error) {
 	if data == nil {
 		return nil, nil
 	}
+	codec, err := compression.GetCodec(c.codec)
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := codec.Compress(data)
+	if err != nil {
+		return nil, err
+	}
 	chunk := &m.Chunk{
-		FileName:  c.destinationFilename,
-		ChunkName: c.destinationFilename + "-" + strconv.Itoa(int(c.serial)),
-		Serial:    c.serial,
-		Size:      int64(len(data)),
-		Data:      data,
-		Offset:    int32(c.offset),
-		FileSize:  int32(c.fileSize),
+		FileName:    c.destinationFilename,
+		ChunkName:   c.destinationFilename + "-" + strconv.Itoa(int(c.serial)),
+		Serial:      c.serial,
+		Size:        int64(len(compressed)),
+		Data:        compressed,
+		Offset:      int32(c.offset),
+		FileSize:    int32(c.fileSize),
+		Replication: c.replication,
+		Codec:       c.codec,
+		RawSize:     int64(len(data)),
+		TotalChunks: c.totalChunks,
 	}
 	c.serial++
 	c.offset = c.offset + len(data)
@@ -55,6 +79,26 @@ func (c *ChunkinatorImpl) Chunk() (*m.Chunk, error) {
 	return chunk, nil
 }
 
+/**
+* Chunks end at the first newline past CHUNK_SIZE, so the file size alone
+* doesn't tell how many there are: the boundaries are walked once up front.
+ */
+func countChunks(filename string) (int32, error) {
+	var chunks int32 = 0
+	offset := 0
+	for {
+		data, err := read(filename, int64(offset))
+		if err != nil {
+			return 0, err
+		}
+		if data == nil {
+			return chunks, nil
+		}
+		chunks++
+		offset += len(data)
+	}
+}
+
 func read(filename string, offset int64) ([]byte, error) {
 	file, err := os.Open(filename)
 	defer file.Close()
@@ -1,10 +1,15 @@
 package client
 
 import (
+	"adfs/common"
+	"adfs/compression"
+	ec "adfs/erasure_coding"
 	"adfs/helpers"
 	m "adfs/messages"
+	"errors"
 	"math"
 	"os"
+	"strconv"
 	"strings"
 
 	pui "github.com/manifoldco/promptui"
@@ -17,6 +22,11 @@ type Cli interface {
 	Get(dir string) *UserAction // refactor: cursor pos should not be part of interface
 	Put(dir string) *UserAction // refactor: cursor pos should not be part of interface
 	Rm(dir string) *UserAction  // refactor: cursor pos should not be part of interface
+	SetRep(dir string) *UserAction
+	SetQuota() *UserAction
+	Decommission() *UserAction
+	SafeMode() *UserAction
+	Fsck() *UserAction
 	GetClusterStats() *UserAction
 	Reset()
 }
@@ -28,8 +38,10 @@ type CliImpl struct {
 	filePaths             []string
 	homeDir               string
 	storageDir            string
-	ls                    func() ([]*m.File, error)
-	getClusterInformation func() ([]*m.Node, error)
+	replication           int    // default replication factor for uploads
+	codec                 string // default compression codec for uploads
+	ls                    func() ([]*m.File, []*m.Quota, error)
+	getClusterInformation func() ([]*m.Node, *m.SafeMode, error)
 }
 
 type UserAction struct {
@@ -37,6 +49,15 @@ type UserAction struct {
 	remoteFilename string
 	localFilename  string
 	outputFilename string
+	replication    int
+	storagePolicy  string
+	codec          string
+	node           string // uuid of the storage node to decommission
+	safeMode       string // common.SAFE_MODE_ENTER/SAFE_MODE_LEAVE
+	repair         bool   // fsck
+	maxFiles       int64  // quota; 0 is no limit
+	maxBytes       int64
+	job            *m.Plugin // compute settings; the plugin itself is read from localFilename
 }
 
 type Item struct {
@@ -48,12 +69,16 @@ type Item struct {
 func NewCli(
 	homeDir string,
 	storageDir string,
-	ls func() ([]*m.File, error),
-	getClusterInformation func() ([]*m.Node, error),
+	replication int,
+	codec string,
+	ls func() ([]*m.File, []*m.Quota, error),
+	getClusterInformation func() ([]*m.Node, *m.SafeMode, error),
 ) Cli {
 	return &CliImpl{
 		homeDir:               homeDir,
 		storageDir:            storageDir,
+		replication:           replication,
+		codec:                 codec,
 		cursorPos:             make([]int, 1),
 		ls:                    ls,
 		getClusterInformation: getClusterInformation,
@@ -70,6 +95,12 @@ func (c *CliImpl) Start() *UserAction {
 		{displayName: UPLOAD_FILE},
 		{displayName: DELETE_FILE},
 		{displayName: COMPUTE_FILE},
+		{displayName: SET_REPLICATION},
+		{displayName: SET_STORAGE_POLICY},
+		{displayName: SET_QUOTA},
+		{displayName: DECOMMISSION_NODE},
+		{displayName: SAFE_MODE},
+		{displayName: FSCK},
 		{displayName: GET_CLUSTER_STATS},
 		{displayName: EXIT},
 	}
@@ -83,6 +114,18 @@ func (c *CliImpl) Start() *UserAction {
 		return c.Rm("/")
 	case COMPUTE_FILE:
 		return c.Compute(c.homeDir)
+	case SET_REPLICATION:
+		return c.SetRep("/")
+	case SET_STORAGE_POLICY:
+		return c.SetPolicy()
+	case SET_QUOTA:
+		return c.SetQuota()
+	case DECOMMISSION_NODE:
+		return c.Decommission()
+	case SAFE_MODE:
+		return c.SafeMode()
+	case FSCK:
+		return c.Fsck()
 	case GET_CLUSTER_STATS:
 		return c.GetClusterStats()
 	case EXIT:
@@ -131,6 +174,11 @@ func (c *CliImpl) Put(dirname string) *UserAction {
 		remoteFilename = "/" + remoteFilename
 	}
 	userAction.remoteFilename = remoteFilename
+	userAction.storagePolicy = policyPrompt(true)
+	if !ec.IsErasureCoded(userAction.storagePolicy) {
+		userAction.replication = replicationPrompt(c.replication)
+	}
+	userAction.codec = codecPrompt(c.codec)
 	return userAction
 }
 
@@ -144,33 +192,160 @@ func (c *CliImpl) Rm(dir string) *UserAction {
 	return userAction
 }
 
+func (c *CliImpl) SetRep(dir string) *UserAction {
+	label := "Select remote file to change its replication factor"
+	userAction := c.handleRemoteFiles(label, dir, 0)
+	if userAction == nil {
+		return c.Start()
+	}
+	userAction.action = SET_REPLICATION
+	userAction.replication = replicationPrompt(0)
+	return userAction
+}
+
+func (c *CliImpl) SetPolicy() *UserAction {
+	dirnameLabel := "Directory. Ex: /<f1>/<f2>"
+	dirname := inputPrompt(dirnameLabel)
+	if string(dirname[0]) != "/" {
+		dirname = "/" + dirname
+	}
+	return &UserAction{
+		action:         SET_STORAGE_POLICY,
+		remoteFilename: dirname,
+		storagePolicy:  policyPrompt(false),
+	}
+}
+
+/** Shows the directory's current quota and usage before asking for the new limits */
+func (c *CliImpl) SetQuota() *UserAction {
+	dirname := inputPrompt("Directory. Ex: /<f1>/<f2>")
+	if string(dirname[0]) != "/" {
+		dirname = "/" + dirname
+	}
+	if _, quotas, err := c.ls(); err == nil {
+		helpers.ClearTerminal()
+		printQuota(dirname, quotas)
+	}
+	return &UserAction{
+		action:         SET_QUOTA,
+		remoteFilename: dirname,
+		maxFiles:       limitPrompt("Max files (0 for no limit)", 1),
+		maxBytes:       limitPrompt("Max MB, replicas included (0 for no limit)", 1<<20),
+	}
+}
+
+func (c *CliImpl) Decommission() *UserAction {
+	storageNodes, _, err := c.getClusterInformation()
+	if err != nil {
+		dialog(fail(err.Error()))
+		return c.Start()
+	}
+	choices := []*Item{}
+	for _, sn := range storageNodes {
+		if sn.State != common.NODE_ONLINE {
+			continue
+		}
+		choices = append(choices, &Item{
+			displayName: sn.Uuid + " (" + helpers.GetAddr(sn.Hostname, int(sn.Port)) + ")",
+			name:        sn.Uuid,
+		})
+	}
+	choices = append(choices, &Item{displayName: MAIN_MENU})
+	selected, _ := selectPrompt("Select storage node to decommission", choices, 0)
+	if selected.displayName == MAIN_MENU {
+		return c.Start()
+	}
+	return &UserAction{
+		action: DECOMMISSION_NODE,
+		node:   selected.name,
+	}
+}
+
+func (c *CliImpl) SafeMode() *UserAction {
+	choices := []*Item{
+		{displayName: ENTER_SAFE_MODE, name: common.SAFE_MODE_ENTER},
+		{displayName: LEAVE_SAFE_MODE, name: common.SAFE_MODE_LEAVE},
+		{displayName: MAIN_MENU},
+	}
+	selected, _ := selectPrompt("Safe mode keeps the file system read-only", choices, 0)
+	if selected.displayName == MAIN_MENU {
+		return c.Start()
+	}
+	return &UserAction{
+		action:   SAFE_MODE,
+		safeMode: selected.name,
+	}
+}
+
+func (c *CliImpl) Fsck() *UserAction {
+	path := inputPrompt("Path to check. Ex: / or /<f1>/<f2>")
+	if string(path[0]) != "/" {
+		path = "/" + path
+	}
+	choices := []*Item{
+		{displayName: FSCK_REPORT_ONLY},
+		{displayName: FSCK_REPAIR},
+	}
+	selected, _ := selectPrompt("Repair what can be repaired?", choices, 0)
+	return &UserAction{
+		action:         FSCK,
+		remoteFilename: path,
+		repair:         selected.displayName == FSCK_REPAIR,
+	}
+}
+
 func (c *CliImpl) Compute(homeDir string) *UserAction {
 	targetFile := c.handleRemoteFiles("Select file to compute", "/", 0)
 	if targetFile == nil {
 		return c.Start()
 	}
 	c.filePaths = []string{}
-	selectJobLabel := "Select compute file"
-	jobFile := c.handleLocalFiles(selectJobLabel, homeDir, 0)
+	userAction := &UserAction{
+		action:         COMPUTE_FILE,
+		remoteFilename: targetFile.remoteFilename,
+		job:            &m.Plugin{},
+	}
+	choices := []*Item{
+		{displayName: PLUGIN_JOB},
+		{displayName: STREAMING_JOB},
+	}
+	if selected, _ := selectPrompt("Job type", choices, 0); selected.displayName == STREAMING_JOB {
+		userAction.job.Streaming = &m.Streaming{
+			Mapper:  inputPrompt("Mapper command. Reads lines on stdin, prints <key>\\t<value> lines"),
+			Reducer: inputPrompt("Reducer command. Reads sorted <key>\\t<value> lines on stdin"),
+		}
+		userAction.job.Streaming.Combiner = optionalInputPrompt("Combiner command, run on each mapper's output. Leave empty for none")
+	} else {
+		selectJobLabel := "Select compute file"
+		jobFile := c.handleLocalFiles(selectJobLabel, homeDir, 0)
+		if jobFile == nil {
+			return c.Start()
+		}
+		userAction.localFilename = jobFile.localFilename
+		choices := []*Item{
+			{displayName: NO_COMBINER},
+			{displayName: REDUCER_AS_COMBINER},
+		}
+		selected, _ := selectPrompt("Combine mapper output before shuffling it?", choices, 0)
+		userAction.job.Combine = selected.displayName == REDUCER_AS_COMBINER
+	}
+	userAction.job.Reducers = int32(limitPrompt("Number of reducers (0 lets the compute engine pick)", 1))
+	userAction.job.Partitioner = partitionerPrompt(userAction.job.Reducers)
 	saveAsLabel := "Save output file as. Ex: /<f1>/<f2>/<filename>"
 	saveAs := inputPrompt(saveAsLabel)
 	if string(saveAs[0]) != "/" {
 		saveAs = "/" + saveAs
 	}
-	return &UserAction{
-		action:         COMPUTE_FILE,
-		remoteFilename: targetFile.remoteFilename,
-		localFilename:  jobFile.localFilename,
-		outputFilename: saveAs,
-	}
+	userAction.outputFilename = saveAs
+	return userAction
 }
 
 func (c *CliImpl) GetClusterStats() *UserAction {
 	label := "Cluster Summary"
-	if storageNodes, err := c.getClusterInformation(); err != nil {
+	if storageNodes, safeMode, err := c.getClusterInformation(); err != nil {
 		dialog(fail(err.Error()))
 	} else {
-		printClusterInformation(storageNodes)
+		printClusterInformation(storageNodes, safeMode)
 	}
 	choices := []*Item{{
 		displayName: MAIN_MENU,
@@ -196,12 +371,12 @@ func (c *CliImpl) handleRemoteFiles(
 	if dirname == "" {
 		dirname = "/"
 	}
-	remoteFiles, err := c.ls()
+	remoteFiles, quotas, err := c.ls()
 	if err != nil {
 		dialog(fail("A-DFS is not online"))
 		return nil
 	}
-	choices := getRemoteChoicesFor(dirname, remoteFiles)
+	choices := getRemoteChoicesFor(dirname, remoteFiles, quotas)
 	choices = setChoices(choices)
 	selected, pos := selectPrompt(label, choices, cursorPos)
 
@@ -305,6 +480,140 @@ func inputPrompt(label string) string {
 	return selected
 }
 
+/** Like inputPrompt, but an empty answer is valid */
+func optionalInputPrompt(label string) string {
+	prompt := pui.Prompt{
+		Label: label,
+	}
+	selected, err := prompt.Run()
+	if err != nil {
+		logrus.Error(err.Error())
+		os.Exit(1)
+	}
+	return strings.TrimSpace(selected)
+}
+
+/** Returns the selected storage policy; "" means inherit it from the directory */
+func policyPrompt(allowInherit bool) string {
+	choices := []*Item{}
+	if allowInherit {
+		choices = append(choices, &Item{displayName: INHERIT_POLICY})
+	}
+	choices = append(choices,
+		&Item{displayName: REPLICATED_POLICY},
+		&Item{displayName: ERASURE_CODED_POLICY},
+	)
+	selected, _ := selectPrompt("Storage policy", choices, 0)
+	switch selected.displayName {
+	case REPLICATED_POLICY:
+		return ec.REPLICATED_POLICY
+	case ERASURE_CODED_POLICY:
+		return ec.DEFAULT_EC_POLICY
+	}
+	return ""
+}
+
+/** Lets the user pick the compression codec; the default codec is listed first */
+func codecPrompt(defaultCodec string) string {
+	codecs := append([]string{defaultCodec}, compression.NONE)
+	codecs = append(codecs, compression.CODECS...)
+	choices := []*Item{}
+	added := make(map[string]bool)
+	for _, codec := range codecs {
+		if added[codec] {
+			continue
+		}
+		added[codec] = true
+		displayName := codec
+		if codec == compression.NONE {
+			displayName = NO_COMPRESSION
+		}
+		choices = append(choices, &Item{displayName: displayName, name: codec})
+	}
+	selected, _ := selectPrompt("Compression", choices, 0)
+	return selected.name
+}
+
+/** Prompts for a replication factor. Empty input falls back to defaultReplication */
+func replicationPrompt(defaultReplication int) int {
+	label := "Replication factor"
+	if defaultReplication > 0 {
+		label += " (default " + strconv.Itoa(defaultReplication) + ")"
+	}
+	prompt := pui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			if input == "" && defaultReplication > 0 {
+				return nil
+			}
+			_, err := strconv.Atoi(input)
+			return err
+		},
+	}
+	selected, err := prompt.Run()
+	if err != nil {
+		logrus.Error(err.Error())
+		os.Exit(1)
+	}
+	if selected == "" {
+		return defaultReplication
+	}
+	replication, _ := strconv.Atoi(selected)
+	return replication
+}
+
+/** Prompts for a non negative number and returns it times unit */
+func limitPrompt(label string, unit int64) int64 {
+	prompt := pui.Prompt{
+		Label: label,
+		Validate: func(input string) error {
+			limit, err := strconv.ParseInt(input, 10, 64)
+			if err == nil && limit < 0 {
+				return errors.New("must not be negative")
+			}
+			return err
+		},
+	}
+	selected, err := prompt.Run()
+	if err != nil {
+		logrus.Error(err.Error())
+		os.Exit(1)
+	}
+	limit, _ := strconv.ParseInt(selected, 10, 64)
+	return limit * unit
+}
+
+/** Range boundaries split the key space in as many ranges as reducers, when a count was given */
+func partitionerPrompt(reducers int32) *m.Partitioner {
+	choices := []*Item{
+		{displayName: HASH_PARTITIONER},
+		{displayName: PREFIX_PARTITIONER},
+		{displayName: RANGE_PARTITIONER},
+		{displayName: TOTAL_ORDER_PARTITIONER},
+	}
+	selected, _ := selectPrompt("Partitioner. Decides which reducer each key goes to", choices, 0)
+	switch selected.displayName {
+	case PREFIX_PARTITIONER:
+		return &m.Partitioner{
+			Type:         m.PartitionerType_partition_key_prefix,
+			PrefixLength: int32(limitPrompt("Prefix length, in bytes", 1)),
+		}
+	case RANGE_PARTITIONER:
+		label := "Comma separated boundary keys, sorted. Reducer i gets keys from boundary i-1 up to boundary i"
+		if reducers > 0 {
+			label += " (" + strconv.Itoa(int(reducers-1)) + " boundaries)"
+		}
+		boundaries := strings.Split(inputPrompt(label), ",")
+		for i := range boundaries {
+			boundaries[i] = strings.TrimSpace(boundaries[i])
+		}
+		return &m.Partitioner{Type: m.PartitionerType_partition_range, Boundaries: boundaries}
+	case TOTAL_ORDER_PARTITIONER:
+		return &m.Partitioner{Type: m.PartitionerType_partition_total_order}
+	}
+	return &m.Partitioner{Type: m.PartitionerType_partition_hash}
+}
+
 func selectPrompt(label string, choices []*Item, cursorPos int) (*Item, int) {
 	searcher := func(input string, i int) bool {
 		choice := choices[i]
@@ -9,6 +9,7 @@ import (
 const TITLE = "       A-DFS"
 const EOT = "---END OF TRANSMISSION---"
 const PRINT_TIME_S = 3
+const DECOMMISSION_POLL_S = 2
 const CONNECTION_ERROR_MSG = "Oops! Seems like you are not connected to the Controller"
 
 type Client interface {
@@ -36,12 +37,24 @@ func (c *ClientImpl) Start() {
 		if userAction.action == DOWNLOAD_FILE {
 			c.actions.Download(localFilename, remoteFilename)
 		} else if userAction.action == UPLOAD_FILE {
-			c.actions.Upload(localFilename, remoteFilename)
+			c.actions.Upload(localFilename, remoteFilename, userAction.replication, userAction.storagePolicy, userAction.codec)
 		} else if userAction.action == DELETE_FILE {
 			c.actions.Delete(remoteFilename)
+		} else if userAction.action == SET_REPLICATION {
+			c.actions.SetReplication(remoteFilename, userAction.replication)
+		} else if userAction.action == SET_STORAGE_POLICY {
+			c.actions.SetStoragePolicy(remoteFilename, userAction.storagePolicy)
+		} else if userAction.action == SET_QUOTA {
+			c.actions.SetQuota(remoteFilename, userAction.maxFiles, userAction.maxBytes)
+		} else if userAction.action == DECOMMISSION_NODE {
+			c.actions.Decommission(userAction.node)
+		} else if userAction.action == SAFE_MODE {
+			c.actions.SetSafeMode(userAction.safeMode)
+		} else if userAction.action == FSCK {
+			c.actions.Fsck(userAction.remoteFilename, userAction.repair)
 		} else if userAction.action == COMPUTE_FILE {
 			outputFilename := userAction.outputFilename
-			c.actions.Compute(localFilename, remoteFilename, outputFilename)
+			c.actions.Compute(localFilename, remoteFilename, outputFilename, userAction.job)
 		} else if userAction.action == EOT {
 			c.Stop()
 		}
@@ -1,13 +1,48 @@
 package client
 
+import ec "adfs/erasure_coding"
+
 // user actions
 const DOWNLOAD_FILE = "⬇️ Download file"
 const UPLOAD_FILE = "⬆️ Upload file"
 const DELETE_FILE = "❌Delete file"
 const COMPUTE_FILE = "⚙️ Compute Engine"
+const SET_REPLICATION = "🧬Set replication factor"
+const SET_STORAGE_POLICY = "🧩Set directory storage policy"
+const SET_QUOTA = "📦Set directory quota"
+const DECOMMISSION_NODE = "🚧Decommission storage node"
+const SAFE_MODE = "🛟Safe mode"
+const FSCK = "🩺Check file system health"
 const GET_CLUSTER_STATS = "📈Cluster information"
 const EXIT = "🚪Exit"
 
 // cli menus
 const MAIN_MENU = "Go back to main menu"
 const PREV_FOLDER = "../"
+
+// safe mode
+const ENTER_SAFE_MODE = "Enter safe mode"
+const LEAVE_SAFE_MODE = "Leave safe mode"
+
+// compute jobs
+const PLUGIN_JOB = "Plugin (executable or .go file)"
+const STREAMING_JOB = "Streaming (mapper and reducer commands)"
+const STREAMING_PLUGIN_NAME = "/streaming"
+const NO_COMBINER = "No combiner"
+const REDUCER_AS_COMBINER = "Use the reducer as combiner (sums, counts, max...)"
+const HASH_PARTITIONER = "Hash of the key"
+const PREFIX_PARTITIONER = "Hash of a key prefix"
+const RANGE_PARTITIONER = "Key ranges"
+const TOTAL_ORDER_PARTITIONER = "Total order (key ranges sampled from the input)"
+
+// fsck
+const FSCK_REPORT_ONLY = "Report only"
+const FSCK_REPAIR = "Report and repair"
+
+// storage policies
+const INHERIT_POLICY = "Inherit from directory"
+const REPLICATED_POLICY = "Replicated"
+const ERASURE_CODED_POLICY = "Erasure coded (" + ec.DEFAULT_EC_POLICY + ")"
+
+// compression
+const NO_COMPRESSION = "No compression"
@@ -1,10 +1,12 @@
 package client
 
 import (
+	ec "adfs/erasure_coding"
 	"adfs/helpers"
 	m "adfs/messages"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -93,7 +95,7 @@ func getFileSize(filename string) int {
 	return int(fi.Size())
 }
 
-func getRemoteChoicesFor(dirname string, files []*m.File) []*Item {
+func getRemoteChoicesFor(dirname string, files []*m.File, quotas []*m.Quota) []*Item {
 	if dirname != "/" {
 		dirname += "/"
 	}
@@ -110,7 +112,7 @@ func getRemoteChoicesFor(dirname string, files []*m.File) []*Item {
 				continue
 			}
 			item := &Item{
-				displayName: prependFolderEmoji(foldername),
+				displayName: prependFolderEmoji(foldername) + getQuotaDescription(dirname+foldername, quotas),
 				name:        foldername,
 				isDir:       true,
 			}
@@ -130,7 +132,7 @@ func getRemoteChoicesFor(dirname string, files []*m.File) []*Item {
 		if strings.Contains(f.Dirname, dirname) &&
 			len(remotePaths) == len(localPaths) { // is file
 			item := &Item{
-				displayName: prependFileEmoji(helpers.GetFilename(f.Dirname)),
+				displayName: prependFileEmoji(helpers.GetFilename(f.Dirname)) + " " + getFileSizeDescription(f),
 				name:        helpers.GetFilename(f.Dirname),
 				isDir:       false,
 			}
@@ -143,3 +145,57 @@ func getRemoteChoicesFor(dirname string, files []*m.File) []*Item {
 	choices = append(choices, sortedFiles...)
 	return choices
 }
+
+/** Ex: (12.4 MB, 3.1 MB compressed with gzip) */
+func getFileSizeDescription(file *m.File) string {
+	var rawSize, storedSize int64
+	codec := ""
+	for _, chunk := range file.Chunks {
+		if ec.IsParity(chunk) {
+			continue
+		}
+		storedSize += chunk.Size
+		if chunk.RawSize > 0 {
+			rawSize += chunk.RawSize
+		} else {
+			rawSize += chunk.Size
+		}
+		codec = chunk.Codec
+	}
+	if codec == "" {
+		return "(" + formatBytes(rawSize) + ")"
+	}
+	return "(" + formatBytes(rawSize) + ", " + formatBytes(storedSize) + " compressed with " + codec + ")"
+}
+
+/** Ex: " [quota: 3/10 files, 1.2 MB/5.0 MB]"; empty when the directory has no quota */
+func getQuotaDescription(dirname string, quotas []*m.Quota) string {
+	for _, quota := range quotas {
+		if quota.Dirname != dirname {
+			continue
+		}
+		limits := []string{}
+		if quota.MaxFiles > 0 {
+			limits = append(limits, strconv.FormatInt(quota.Files, 10)+"/"+strconv.FormatInt(quota.MaxFiles, 10)+" files")
+		}
+		if quota.MaxBytes > 0 {
+			limits = append(limits, formatBytes(quota.Bytes)+"/"+formatBytes(quota.MaxBytes))
+		}
+		return " [quota: " + strings.Join(limits, ", ") + "]"
+	}
+	return ""
+}
+
+func formatBytes(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	i := 0
+	for value >= 1024 && i < len(units)-1 {
+		value /= 1024
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatInt(size, 10) + " " + units[i]
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + units[i]
+}
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"adfs/compression"
+	ec "adfs/erasure_coding"
 	"adfs/helpers"
 	m "adfs/messages"
 	"bufio"
@@ -26,6 +28,7 @@ type DownloaderImpl struct {
 	filename            string     // filename of file to be downloaded
 	dirname             string     // complete dirname of file to be downloaded
 	chunks              []*m.Chunk // chunks to download
+	allChunks           []*m.Chunk // chunks to download plus parity chunks (erasure coding)
 	chunksDownloaded    int
 	tempChunksPersisted chan bool // to wait for all chunks to be persisted before merging
 	chunksCh            chan *m.Chunk
@@ -37,7 +40,8 @@ func NewDownloader(storageDir string, filename string, chunks []*m.Chunk) Downlo
 		storageDir:          storageDir,
 		tempDir:             storageDir + TEMP_DIR,
 		filename:            filename,
-		chunks:              chunks,
+		chunks:              getDataChunks(chunks),
+		allChunks:           chunks,
 		chunksDownloaded:    0,
 		tempChunksPersisted: make(chan bool),
 		chunksCh:            make(chan *m.Chunk),
@@ -97,41 +101,18 @@ func (d *DownloaderImpl) downloadChunks() error {
 	wg.Add(len(d.chunks))
 	for _, c := range d.chunks {
 		chunk := c
-		storageNodes := chunk.StorageNodes
 		go func() {
 			defer wg.Done()
-			if len(storageNodes) == 0 {
-				err = errors.New("chunk doesn't have available Storage Nodes")
-				return
+			downloaded, e := d.fetchChunk(chunk)
+			if e != nil && chunk.DataShards > 0 {
+				// erasure coded: rebuild the chunk from the rest of its stripe
+				downloaded, e = d.reconstructChunk(chunk)
 			}
-			// sorts storage nodes in terms of how much data they've transferred
-			// with the attempt to have them all equally busy
-			//sort.Slice(storageNodes, func(i, j int) bool {
-			//	return storageNodes[i].Stats.Downloaded > storageNodes[j].Stats.Downloaded
-			//})
-			for _, sn := range storageNodes {
-				addr := helpers.GetAddr(sn.Hostname, int(sn.Port))
-				msgHandler, err := m.GetMessageHandlerFor(addr)
-				if err != nil {
-					return
-				}
-				err = msgHandler.SendChunkDownloadRequest(chunk.ChunkName)
-				wrapper, _ := msgHandler.Receive()
-				switch msg := wrapper.Msg.(type) {
-				case *m.Wrapper_AckMessage:
-					if !msg.AckMessage.Ok {
-						fmt.Println(fail("Error from " + sn.Hostname + ":" + strconv.Itoa(int(sn.Port)) + ": " + msg.AckMessage.ErrorMessage))
-					}
-				case *m.Wrapper_ChunkMessage:
-					d.chunksCh <- msg.ChunkMessage
-					return
-				}
-				if err = msgHandler.Close(); err != nil {
-					logrus.Error(err.Error())
-				}
+			if e != nil {
+				err = errors.New("Error trying to retrieve chunk " + chunk.ChunkName + "\n" + e.Error())
+				return
 			}
-			// chunk was not downloaded
-			err = errors.New("Error trying to retrieve chunk " + chunk.ChunkName + "\n" + err.Error())
+			d.chunksCh <- downloaded
 		}()
 	}
 	wg.Wait()
@@ -141,6 +122,137 @@ func (d *DownloaderImpl) downloadChunks() error {
 	return nil
 }
 
+/** Tries every Storage Node owning the chunk until one of them sends it */
+func (d *DownloaderImpl) fetchChunk(chunk *m.Chunk) (*m.Chunk, error) {
+	storageNodes := chunk.StorageNodes
+	if len(storageNodes) == 0 {
+		return nil, errors.New("chunk doesn't have available Storage Nodes")
+	}
+	// sorts storage nodes in terms of how much data they've transferred
+	// with the attempt to have them all equally busy
+	//sort.Slice(storageNodes, func(i, j int) bool {
+	//	return storageNodes[i].Stats.Downloaded > storageNodes[j].Stats.Downloaded
+	//})
+	err := errors.New("no Storage Node could send the chunk")
+	for _, sn := range storageNodes {
+		addr := helpers.GetAddr(sn.Hostname, int(sn.Port))
+		msgHandler, e := m.GetMessageHandlerFor(addr)
+		if e != nil {
+			err = e
+			continue
+		}
+		msgHandler.SendChunkDownloadRequest(chunk.ChunkName)
+		wrapper, _ := msgHandler.Receive()
+		switch msg := wrapper.Msg.(type) {
+		case *m.Wrapper_AckMessage:
+			if !msg.AckMessage.Ok {
+				fmt.Println(fail("Error from " + sn.Hostname + ":" + strconv.Itoa(int(sn.Port)) + ": " + msg.AckMessage.ErrorMessage))
+				err = errors.New(msg.AckMessage.ErrorMessage)
+			}
+		case *m.Wrapper_ChunkMessage:
+			msgHandler.Close()
+			return msg.ChunkMessage, nil
+		}
+		if e = msgHandler.Close(); e != nil {
+			logrus.Error(e.Error())
+		}
+	}
+	return nil, err
+}
+
+/**
+* Rebuilds a data chunk of an erasure coded file out of any DataShards chunks
+* of its stripe. Data chunks past the stripe width are known zero shards.
+ */
+func (d *DownloaderImpl) reconstructChunk(chunk *m.Chunk) (*m.Chunk, error) {
+	dataShards := int(chunk.DataShards)
+	coder, err := ec.NewCoder(dataShards, int(chunk.ParityShards))
+	if err != nil {
+		return nil, err
+	}
+	logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName, "Stripe": chunk.Stripe}).Warn("Reconstructing chunk")
+	shards := make([][]byte, dataShards+int(chunk.ParityShards))
+	available := 0
+	for i := int(chunk.StripeWidth); i < dataShards; i++ {
+		shards[i] = make([]byte, chunk.ShardSize)
+		available++
+	}
+	for _, c := range d.allChunks {
+		if available == dataShards {
+			break
+		}
+		if c.Stripe != chunk.Stripe || c.ChunkName == chunk.ChunkName || shards[c.StripeIndex] != nil {
+			continue
+		}
+		shard, err := d.fetchChunk(c)
+		if err != nil {
+			continue
+		}
+		padded := make([]byte, chunk.ShardSize)
+		copy(padded, shard.Data)
+		shards[c.StripeIndex] = padded
+		available++
+	}
+	if available < dataShards {
+		return nil, errors.New("not enough chunks left in stripe " + strconv.Itoa(int(chunk.Stripe)) + " to reconstruct it")
+	}
+	if err := coder.Reconstruct(shards); err != nil {
+		return nil, err
+	}
+	size := chunk.StripeSizes[chunk.StripeIndex]
+	return &m.Chunk{
+		FileName:  chunk.FileName,
+		ChunkName: chunk.ChunkName,
+		Serial:    chunk.Serial,
+		Size:      size,
+		Data:      shards[chunk.StripeIndex][:size],
+		Codec:     chunk.Codec,
+	}, nil
+}
+
+/**
+* Parity chunks are only downloaded to reconstruct data chunks. Data chunks
+* the Controller doesn't know about (every owner lost them) are inferred
+* from the stripe information of the chunks that are left.
+ */
+func getDataChunks(chunks []*m.Chunk) []*m.Chunk {
+	dataChunks := make([]*m.Chunk, 0)
+	present := make(map[string]bool)
+	for _, chunk := range chunks {
+		if !ec.IsParity(chunk) {
+			dataChunks = append(dataChunks, chunk)
+			present[chunk.ChunkName] = true
+		}
+	}
+	for _, chunk := range chunks {
+		if chunk.DataShards == 0 {
+			continue
+		}
+		for i := int32(0); i < chunk.StripeWidth; i++ {
+			serial := chunk.Stripe*chunk.DataShards + i
+			chunkName := chunk.FileName + "-" + strconv.Itoa(int(serial))
+			if present[chunkName] {
+				continue
+			}
+			present[chunkName] = true
+			dataChunks = append(dataChunks, &m.Chunk{
+				FileName:     chunk.FileName,
+				ChunkName:    chunkName,
+				Serial:       serial,
+				Stripe:       chunk.Stripe,
+				StripeIndex:  i,
+				StripeWidth:  chunk.StripeWidth,
+				StripeSizes:  chunk.StripeSizes,
+				DataShards:   chunk.DataShards,
+				ParityShards: chunk.ParityShards,
+				ShardSize:    chunk.ShardSize,
+				Codec:        chunk.Codec,
+			})
+		}
+	}
+	return dataChunks
+}
+
 func (d *DownloaderImpl) mergeChunks() error {
 	sort.Slice(d.chunks, func(i, j int) bool {
 		return d.chunks[i].Serial < d.chunks[j].Serial
@@ -206,8 +318,13 @@ func (d *DownloaderImpl) worker() {
 }
 
 func (d *DownloaderImpl) writeChunk(chunk *m.Chunk) error {
+	data, err := compression.Decompress(chunk.Codec, chunk.Data)
+	if err != nil {
+		logrus.Error("ERROR: " + err.Error())
+		return err
+	}
 	dirname := d.tempDir + "/" + helpers.GetFilename(chunk.ChunkName)
-	err := os.WriteFile(dirname, chunk.Data, os.ModePerm)
+	err = os.WriteFile(dirname, data, os.ModePerm)
 	if err != nil {
 		logrus.Error("ERROR: " + err.Error())
 		return err
@@ -0,0 +1,119 @@
+package client
+
+import (
+	ec "adfs/erasure_coding"
+	m "adfs/messages"
+	"strconv"
+)
+
+/**
+* Wraps a Chunkinator and, for every stripe of DataShards() chunks, emits the
+* data chunks followed by the stripe's parity chunks. Chunks are not
+* replicated: the parity chunks provide the redundancy.
+ */
+type ErasureChunkinatorImpl struct {
+	chunkinator Chunkinator
+	coder       ec.Coder
+	stripe      int32
+	pending     []*m.Chunk // chunks of the current stripe waiting to be handed out
+	done        bool
+}
+
+func NewErasureChunkinator(chunkinator Chunkinator, coder ec.Coder) Chunkinator {
+	return &ErasureChunkinatorImpl{
+		chunkinator: chunkinator,
+		coder:       coder,
+		pending:     make([]*m.Chunk, 0),
+	}
+}
+
+/** Returns the Chunkinator matching the storage policy the Controller assigned */
+func NewChunkinatorFor(localFilename, destinationFilename string, replication int32, policy, codec string) (Chunkinator, error) {
+	if !ec.IsErasureCoded(policy) {
+		return NewChunkinator(localFilename, destinationFilename, replication, codec), nil
+	}
+	coder, err := ec.NewCoderFor(policy)
+	if err != nil {
+		return nil, err
+	}
+	return NewErasureChunkinator(NewChunkinator(localFilename, destinationFilename, 1, codec), coder), nil
+}
+
+func (e *ErasureChunkinatorImpl) Chunk() (*m.Chunk, error) {
+	if len(e.pending) == 0 && !e.done {
+		if err := e.nextStripe(); err != nil {
+			return nil, err
+		}
+	}
+	if len(e.pending) == 0 {
+		return nil, nil
+	}
+	chunk := e.pending[0]
+	e.pending = e.pending[1:]
+	return chunk, nil
+}
+
+func (e *ErasureChunkinatorImpl) nextStripe() error {
+	dataChunks := make([]*m.Chunk, 0)
+	for len(dataChunks) < e.coder.DataShards() {
+		chunk, err := e.chunkinator.Chunk()
+		if err != nil {
+			return err
+		}
+		if chunk == nil || chunk.Size == 0 {
+			e.done = true
+			break
+		}
+		dataChunks = append(dataChunks, chunk)
+	}
+	if len(dataChunks) == 0 {
+		return nil
+	}
+
+	data := make([][]byte, len(dataChunks))
+	for i, chunk := range dataChunks {
+		data[i] = chunk.Data
+	}
+	parity, shardSize, err := e.coder.Encode(data)
+	if err != nil {
+		return err
+	}
+
+	first := dataChunks[0]
+	sizes := make([]int64, len(dataChunks))
+	for i, chunk := range dataChunks {
+		sizes[i] = chunk.Size
+	}
+	for i, chunk := range dataChunks {
+		e.setStripeInfo(chunk, int32(i), sizes, shardSize)
+	}
+	for i, p := range parity {
+		stripeIndex := int32(e.coder.DataShards() + i)
+		parityChunk := &m.Chunk{
+			FileName:    first.FileName,
+			ChunkName:   first.FileName + "-s" + strconv.Itoa(int(e.stripe)) + "-p" + strconv.Itoa(i),
+			Serial:      -1,
+			Size:        int64(len(p)),
+			Data:        p,
+			FileSize:    first.FileSize,
+			Codec:       first.Codec,
+			TotalChunks: first.TotalChunks,
+		}
+		e.setStripeInfo(parityChunk, stripeIndex, sizes, shardSize)
+		dataChunks = append(dataChunks, parityChunk)
+	}
+	e.pending = dataChunks
+	e.stripe++
+	return nil
+}
+
+func (e *ErasureChunkinatorImpl) setStripeInfo(chunk *m.Chunk, stripeIndex int32, sizes []int64, shardSize int) {
+	chunk.Replication = 1
+	chunk.Stripe = e.stripe
+	chunk.StripeIndex = stripeIndex
+	chunk.StripeWidth = int32(len(sizes))
+	chunk.StripeSizes = sizes
+	chunk.DataShards = int32(e.coder.DataShards())
+	chunk.ParityShards = int32(e.coder.ParityShards())
+	chunk.ShardSize = int64(shardSize)
+}
@@ -1,12 +1,18 @@
 package client
 
-import h "adfs/helpers"
+import (
+	"adfs/common"
+	"adfs/compression"
+
+	"github.com/sirupsen/logrus"
+)
 
 type Config struct {
-	HomeDir        string
-	ControllerHost string
-	ControllerPort int
-	StorageDir     string
+	HomeDir         string
+	ControllerAddrs []string // every controller; requests go to the leader
+	StorageDir      string
+	Replication     int    // default replication factor for uploads
+	Compression     string // default compression codec for uploads
 }
 
 /**
@@ -17,13 +23,21 @@ type Config struct {
  *	 and rendering the corresopnding results.
  */
 func Init(config Config) {
+	if config.Replication == 0 {
+		config.Replication = int(common.DEFAULT_REPLICATION)
+	}
+	if _, err := compression.GetCodec(config.Compression); err != nil {
+		logrus.Fatal(err.Error())
+	}
 	actions := NewActions(
-		h.GetAddr(config.ControllerHost, config.ControllerPort),
+		config.ControllerAddrs,
 		config.StorageDir,
 	)
 	cli := NewCli(
 		config.HomeDir,
 		config.StorageDir,
+		config.Replication,
+		config.Compression,
 		actions.List,            // is passed to CLI so it can retrieve remote files
 		actions.GetClusterStats, // is passed to CLI so it can render Cluster information
 	)
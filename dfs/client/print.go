@@ -1,26 +1,119 @@
 package client
 
 import (
+	"adfs/common"
 	h "adfs/helpers"
 	m "adfs/messages"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
-func printClusterInformation(storageNodes []*m.Node) {
+func printClusterInformation(storageNodes []*m.Node, safeMode *m.SafeMode) {
+	printSafeMode(safeMode)
 	for _, sn := range storageNodes {
 		stats := sn.Stats
 		fmt.Println("")
 		fmt.Println("StorageNode UUID: " + sn.Uuid)
+		if sn.State == common.NODE_DRAINING {
+			fmt.Println("State....................................." + sn.State + " (" + strconv.Itoa(int(sn.ChunksToDrain)) + " chunks left)")
+		} else if sn.State != "" {
+			fmt.Println("State....................................." + sn.State)
+		}
 		fmt.Println("Transferred chunks........................" + strconv.Itoa(int(stats.Downloaded)))
 		fmt.Println("Stored chunks............................." + strconv.Itoa(int(stats.Uploaded)))
 		fmt.Println("Replicated chunks........................." + strconv.Itoa(int(stats.Replicated)))
 		fmt.Println("Free space................................" + strconv.Itoa(int(stats.FreeSpace)) + " GB")
+		for _, disk := range stats.Disks {
+			fmt.Println("  " + disk.Dir + ": " + formatBytes(disk.Used) + " used of " + formatBytes(disk.Capacity) +
+				", " + formatBytes(disk.Available) + " available (" + formatBytes(disk.Reserved) + " reserved)")
+		}
 		fmt.Println("------------------------------------------------------")
 	}
 }
 
+func printSafeMode(safeMode *m.SafeMode) {
+	if safeMode == nil {
+		return
+	}
+	state := "off"
+	if safeMode.On && safeMode.Manual {
+		state = "on (entered by admin)"
+	} else if safeMode.On {
+		state = "on (waiting for " + strconv.FormatFloat(safeMode.Threshold*100, 'f', 1, 64) + "% of chunks)"
+	}
+	fmt.Println("Safe mode................................." + state)
+	fmt.Println("Reported chunks..........................." + strconv.FormatInt(safeMode.ReportedChunks, 10) +
+		" of " + strconv.FormatInt(safeMode.ExpectedChunks, 10))
+	fmt.Println("------------------------------------------------------")
+}
+
+/** Current quota of dirname and how much of it is used */
+func printQuota(dirname string, quotas []*m.Quota) {
+	dirname = strings.TrimSuffix(dirname, "/")
+	if dirname == "" {
+		dirname = "/"
+	}
+	fmt.Println("Directory................................." + dirname)
+	for _, quota := range quotas {
+		if quota.Dirname != dirname {
+			continue
+		}
+		fmt.Println("Files....................................." + strconv.FormatInt(quota.Files, 10) + " of " + formatLimit(strconv.FormatInt(quota.MaxFiles, 10), quota.MaxFiles))
+		fmt.Println("Space....................................." + formatBytes(quota.Bytes) + " of " + formatLimit(formatBytes(quota.MaxBytes), quota.MaxBytes))
+		fmt.Println("------------------------------------------------------")
+		return
+	}
+	fmt.Println("Quota.....................................none")
+	fmt.Println("------------------------------------------------------")
+}
+
+func formatLimit(formatted string, limit int64) string {
+	if limit <= 0 {
+		return "unlimited"
+	}
+	return formatted
+}
+
+func printFsckReport(path string, report *m.FsckReport) {
+	for _, file := range report.Files {
+		fmt.Println("")
+		fmt.Println(file.FileName + " (" + file.StoragePolicy + ", replication " + strconv.Itoa(int(file.Replication)) + ")")
+		printFsckList("Missing chunks", file.MissingChunks)
+		printFsckList("Under-replicated chunks", file.UnderReplicated)
+		printFsckList("Over-replicated chunks", file.OverReplicated)
+		printFsckList("Corrupt replicas", file.CorruptReplicas)
+		if len(file.MissingSerials) > 0 {
+			serials := make([]string, len(file.MissingSerials))
+			for i, serial := range file.MissingSerials {
+				serials[i] = strconv.Itoa(int(serial))
+			}
+			printFsckList("Gaps in chunk serials", serials)
+		}
+		fmt.Println("------------------------------------------------------")
+	}
+	for _, repair := range report.Repairs {
+		fmt.Println("Repair: " + repair)
+	}
+	fmt.Println("")
+	status := "HEALTHY"
+	if len(report.Files) > 0 {
+		status = "UNHEALTHY"
+	}
+	fmt.Println("Status of " + path + "........................." + status)
+	fmt.Println("Files checked............................." + strconv.Itoa(int(report.FilesChecked)))
+	fmt.Println("Chunks checked............................" + strconv.FormatInt(report.ChunksChecked, 10))
+	fmt.Println("Unhealthy files..........................." + strconv.Itoa(len(report.Files)))
+}
+
+func printFsckList(label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Println("  " + label + " (" + strconv.Itoa(len(items)) + "): " + strings.Join(items, ", "))
+}
+
 func dialog(msg string) {
 	h.ClearTerminal()
 	fmt.Println(msg)
@@ -3,6 +3,8 @@ package client
 import (
 	h "adfs/helpers"
 	m "adfs/messages"
+	"errors"
+	"fmt"
 	"sort"
 	"sync"
 
@@ -17,6 +19,8 @@ type UploaderImpl struct {
 	chunkinator  Chunkinator
 	storageNodes []*m.Node
 	msgHandlers  []*m.MessageHandler
+	err          error // first chunk refused by a storage node
+	errMutex     sync.Mutex
 }
 
 func NewUploader(
@@ -62,17 +66,29 @@ func (u *UploaderImpl) handleFileUpload() error {
 		}
 		if chunk == nil || chunk.Size == 0 {
 			wg.Wait()
-			return nil // we are done!
+			return u.err // we are done!
+		}
+		// consecutive chunks land on distinct nodes, so a stripe no wider than
+		// the node list never puts two of its shards on the same node
+		if width := int(chunk.DataShards + chunk.ParityShards); width > len(u.msgHandlers) {
+			wg.Wait()
+			return fmt.Errorf("%d shards per stripe need as many Storage Nodes, only %d available", width, len(u.msgHandlers))
 		}
 		msgHandler := u.msgHandlers[i]
-		if msgHandler != nil {
-			wg.Add(1)
-			go u.sendChunk(msgHandler, chunk, &wg)
+		if msgHandler == nil {
+			wg.Wait()
+			sn := u.storageNodes[i]
+			return errors.New("Storage Node " + h.GetAddr(sn.Hostname, int(sn.Port)) + " is unreachable")
 		}
+		wg.Add(1)
+		go u.sendChunk(msgHandler, chunk, &wg)
 		chunkCounter++
 		i++
 		if i >= len(u.msgHandlers) {
 			wg.Wait()
+			if u.err != nil {
+				return u.err
+			}
 			i = 0
 		}
 	}
@@ -84,19 +100,45 @@ func (u *UploaderImpl) sendChunk(
 	wg *sync.WaitGroup,
 ) {
 	c := &m.Chunk{
-		FileName:  chunk.FileName,
-		ChunkName: chunk.ChunkName,
-		Serial:    chunk.Serial,
-		Size:      chunk.Size,
-		Data:      chunk.Data,
-		Offset:    chunk.Offset,
-		FileSize:  chunk.FileSize,
+		FileName:     chunk.FileName,
+		ChunkName:    chunk.ChunkName,
+		Serial:       chunk.Serial,
+		Size:         chunk.Size,
+		Data:         chunk.Data,
+		Offset:       chunk.Offset,
+		FileSize:     chunk.FileSize,
+		Replication:  chunk.Replication,
+		Stripe:       chunk.Stripe,
+		StripeIndex:  chunk.StripeIndex,
+		StripeWidth:  chunk.StripeWidth,
+		DataShards:   chunk.DataShards,
+		ParityShards: chunk.ParityShards,
+		ShardSize:    chunk.ShardSize,
+		StripeSizes:  chunk.StripeSizes,
+		Codec:        chunk.Codec,
+		RawSize:      chunk.RawSize,
+		TotalChunks:  chunk.TotalChunks,
 	}
+	defer wg.Done()
 	err := msgHandler.SendChunkUploadRequest(c)
 	if err != nil {
-		dialog(err.Error())
-		// TODO: handle error sending chunk
-	} else {
-		wg.Done()
+		u.setErr(err)
+		return
+	}
+	wrapper, err := msgHandler.Receive()
+	if err != nil {
+		u.setErr(err)
+		return
+	}
+	if ack, ok := wrapper.Msg.(*m.Wrapper_AckMessage); ok && !ack.AckMessage.Ok {
+		u.setErr(errors.New(ack.AckMessage.ErrorMessage))
+	}
+}
+
+func (u *UploaderImpl) setErr(err error) {
+	u.errMutex.Lock()
+	defer u.errMutex.Unlock()
+	if u.err == nil {
+		u.err = err
 	}
 }
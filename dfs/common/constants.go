@@ -7,9 +7,35 @@ const PUT = "PUT"
 const RM = "RM"
 const COMPUTE = "COMPUTE"
 const CLUSTER_STATS = "CLUSTER-STATS"
+const SETREP = "SETREP"
+const DECOMMISSION = "DECOMMISSION"
+const SAFEMODE = "SAFEMODE"
+const FSCK = "FSCK"
+const SETQUOTA = "SETQUOTA"
 
 // modify this for bigger chunks
 const CHUNK_SIZE int64 = 1 << 18 // 1MB
 //const CHUNK_SIZE int64 = (1 << 20) * 100 // 1MB * 100 = 100MB
 
 const COMPUTE_ENGINE = "COMPUTE_ENGINE"
+
+// number of copies of each chunk kept in the cluster when
+// the client does not specify a replication factor
+const DEFAULT_REPLICATION int32 = 3
+const MAX_REPLICATION int32 = 10
+
+// standby controllers answer everything but election traffic with this, followed by the leader's address
+const NOT_LEADER_ERROR_MSG = "Controller is on standby. Leader: "
+
+// heartbeats from storage nodes the controller has no record of get this; they must register again
+const UNKNOWN_NODE_ERROR_MSG = "Unknown storage node. Register again"
+
+// storage node states; draining nodes get no new chunks
+const NODE_ONLINE = "online"
+const NODE_DRAINING = "draining"
+const NODE_DECOMMISSIONED = "decommissioned"
+
+// safe mode: the namespace is read-only until enough chunks have been reported
+const SAFE_MODE_ENTER = "enter"
+const SAFE_MODE_LEAVE = "leave"
+const SAFE_MODE_ERROR_MSG = "Controller is in safe mode (read-only). Try again later"
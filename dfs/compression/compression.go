@@ -0,0 +1,110 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// supported codecs; NONE leaves chunk data as is
+const NONE = ""
+const GZIP = "gzip"
+const ZSTD = "zstd"
+const SNAPPY = "snappy"
+
+var CODECS = []string{GZIP, ZSTD, SNAPPY}
+
+type Codec interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+func GetCodec(name string) (Codec, error) {
+	switch name {
+	case NONE:
+		return &NoneCodec{}, nil
+	case GZIP:
+		return &GzipCodec{}, nil
+	case ZSTD:
+		return &ZstdCodec{}, nil
+	case SNAPPY:
+		return &SnappyCodec{}, nil
+	}
+	return nil, errors.New("unknown compression codec " + name)
+}
+
+/** Shortcut for readers of chunks: data is returned untouched when codec is NONE */
+func Decompress(codec string, data []byte) ([]byte, error) {
+	c, err := GetCodec(codec)
+	if err != nil {
+		return nil, err
+	}
+	return c.Decompress(data)
+}
+
+type NoneCodec struct{}
+
+func (c *NoneCodec) Compress(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (c *NoneCodec) Decompress(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+type GzipCodec struct{}
+
+func (c *GzipCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *GzipCodec) Decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+type ZstdCodec struct{}
+
+func (c *ZstdCodec) Compress(data []byte) ([]byte, error) {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil), nil
+}
+
+func (c *ZstdCodec) Decompress(data []byte) ([]byte, error) {
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+	return decoder.DecodeAll(data, nil)
+}
+
+type SnappyCodec struct{}
+
+func (c *SnappyCodec) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (c *SnappyCodec) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
@@ -0,0 +1,127 @@
+package compute_engine
+
+import (
+	extsort "adfs/external_sort"
+	"adfs/messages"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+/**
+* Runs the plugin's reducer over each sorted mapper partition so fewer records
+* are shuffled. Only right for reducers whose output can be reduced again,
+* like sums, counts or maxima.
+ */
+func (ce *ComputeEngineImpl) RunCombiner(pluginPath string) (*messages.Counters, error) {
+	return ce.combine(func(combiner ComputeEngine, partitionPath string) error {
+		groupedPath := partitionPath + "-grouped"
+		defer os.Remove(groupedPath)
+		if err := GroupByKey(partitionPath, groupedPath); err != nil {
+			return err
+		}
+		return combiner.RunReducer(pluginPath, groupedPath)
+	})
+}
+
+/** Streaming combiners read the sorted key\tvalue lines of a partition, like streaming reducers */
+func (ce *ComputeEngineImpl) RunStreamingCombiner(command string) (*messages.Counters, error) {
+	return ce.combine(func(combiner ComputeEngine, partitionPath string) error {
+		return combiner.RunStreamingReducer(command, partitionPath)
+	})
+}
+
+/** Replaces every mapper output file with its combined output, sorted again for the reducers' merge */
+func (ce *ComputeEngineImpl) combine(reduce func(combiner ComputeEngine, partitionPath string) error) (*messages.Counters, error) {
+	counters := &messages.Counters{}
+	for partitionPath := range ce.context.GetMapperOutputFiles() {
+		/** combiner output goes through its own context so Emit doesn't write to the job output */
+		context := NewContext(ce.context.GetStorageDir())
+		context.SetComputeOutputFilename(partitionPath + "-combined")
+		combinedPath := context.GetComputeOutputFilename()
+		// created upfront: a combiner may emit nothing
+		if err := os.WriteFile(combinedPath, nil, os.ModePerm); err != nil {
+			return nil, err
+		}
+		if err := reduce(NewComputeEngine(context), partitionPath); err != nil {
+			os.Remove(combinedPath)
+			return nil, err
+		}
+		if err := extsort.NewExtSort().Sort(combinedPath); err != nil {
+			return nil, err
+		}
+
+		inputRecords, err := countLines(partitionPath)
+		if err != nil {
+			return nil, err
+		}
+		outputRecords, err := countLines(combinedPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Rename(combinedPath, partitionPath); err != nil {
+			return nil, err
+		}
+		logrus.WithFields(logrus.Fields{
+			"Partition":     partitionPath,
+			"InputRecords":  inputRecords,
+			"OutputRecords": outputRecords,
+		}).Info("Combined mapper output")
+		counters.CombineInputRecords += inputRecords
+		counters.CombineOutputRecords += outputRecords
+	}
+	return counters, nil
+}
+
+func countLines(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	var lines int64
+	scanner := extsort.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines, scanner.Err()
+}
+
+/** Turns sorted key\tvalue lines into one key\tvalue\tvalue... line per key */
+func GroupByKey(mergedFilePath, sortedFilePath string) error {
+	sortedFile, err := os.Create(sortedFilePath)
+	if err != nil {
+		return err
+	}
+	defer sortedFile.Close()
+	mergedFile, err := os.Open(mergedFilePath)
+	if err != nil {
+		return err
+	}
+	defer mergedFile.Close()
+	scanner := extsort.NewScanner(mergedFile)
+	var currentOutputKey string
+	var outputLine string
+	grouping := false // keys may be empty, so outputLine can't tell
+	for scanner.Scan() {
+		// keys end at the first tab, values may hold more, as in streaming.go
+		inputLineKey, inputLineValue, _ := strings.Cut(scanner.Text(), "\t")
+
+		// TODO: create a slice to store values list instead of string concatenation
+		if grouping && strings.Compare(inputLineKey, currentOutputKey) == 0 {
+			outputLine += "\t" + inputLineValue
+		} else {
+			currentOutputKey = inputLineKey
+			if grouping {
+				sortedFile.WriteString(outputLine + "\n")
+			}
+			outputLine = inputLineKey + "\t" + inputLineValue
+			grouping = true
+		}
+	}
+	if grouping {
+		sortedFile.WriteString(outputLine + "\n")
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,28 @@
+package compute_engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGroupByKeySplitsAtFirstTab(t *testing.T) {
+	dir := t.TempDir()
+	merged := filepath.Join(dir, "merged")
+	grouped := filepath.Join(dir, "grouped")
+	input := "a\t1\na\tx\ty\n\tempty key\nb\t2\tz\nb\t3\n"
+	if err := os.WriteFile(merged, []byte(input), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := GroupByKey(merged, grouped); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(grouped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "a\t1\tx\ty\n\tempty key\nb\t2\tz\t3\n"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"adfs/helpers"
 	"adfs/messages"
 	"bufio"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -14,11 +16,20 @@ import (
 )
 
 type ComputeEngine interface {
-	RunMapper(pluginDir, dataPath string) error
+	RunMapper(pluginDir string, input io.Reader) error
 	RunReducer(pluginDir, dataPath string) error
-	Shuffle() map[string]*messages.Node
+	RunStreamingMapper(command string, input io.Reader) error
+	RunStreamingReducer(command, dataPath string) error
+	RunCombiner(pluginPath string) (*messages.Counters, error)
+	RunStreamingCombiner(command string) (*messages.Counters, error)
+	Shuffle(partitions []int32) (map[string]*messages.Node, error)
 }
 
+/**
+* Implemented by Go plugins (-buildmode=plugin) run in process. Map writes its
+* pairs with context.Write; Reduce gets a key with its values separated by
+* tabs and emits results with context.Emit.
+ */
 type MapReduce interface {
 	Map(lineNumber int, lineText string, context Context)
 	Reduce(key, value []byte, context Context)
@@ -32,19 +43,56 @@ func NewComputeEngine(context Context) ComputeEngine {
 	return &ComputeEngineImpl{context}
 }
 
-func (ce *ComputeEngineImpl) RunMapper(pluginPath, dataPath string) error {
-	file, err := os.Open(dataPath)
+/**
+* Runs the plugin over every line of input. Go plugins are called in
+* process, plugins speaking the worker protocol are started once for the whole
+* chunk and any other plugin is executed once per line with the line in its
+* arguments.
+ */
+func (ce *ComputeEngineImpl) RunMapper(pluginPath string, input io.Reader) error {
+	var err error
+	if isGoPlugin(pluginPath) {
+		logrus.WithFields(logrus.Fields{"Plugin": pluginPath}).Info("Running mapper in process")
+		err = ce.runGoMapper(pluginPath, input)
+	} else if worker, workerErr := startWorker(pluginPath, MAP); workerErr == nil {
+		logrus.WithFields(logrus.Fields{"Plugin": pluginPath}).Info("Running mapper as plugin worker")
+		err = worker.run(func(send func(key, value []byte) error) error {
+			scanner := extsort.NewScanner(input)
+			for i := 0; scanner.Scan(); i++ {
+				if err := send([]byte(strconv.Itoa(i)), scanner.Bytes()); err != nil {
+					return err
+				}
+			}
+			return scanner.Err()
+		}, ce.context.Write)
+	} else if errors.Is(workerErr, errNotWorker) {
+		logrus.WithFields(logrus.Fields{"Plugin": pluginPath}).Info("Plugin is not a worker, running it once per line")
+		err = ce.execMapper(pluginPath, input)
+	} else {
+		err = workerErr
+	}
+	ce.context.ClearKeysTracker()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error(), "File": dataPath}).Error("Error opening file")
+		logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Error executing plugin")
 		return err
 	}
-	defer func(file *os.File) {
-		err := file.Close()
+
+	/** Sort mapper output files before */
+	for filename := range ce.context.GetMapperOutputFiles() {
+		logrus.WithFields(logrus.Fields{"Filename": filename}).Info("External Sort")
+		err = extsort.NewExtSort().Sort(filename)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Error closing mapper output file")
+			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("External Sort error")
+			return err
 		}
-	}(file)
-	scanner := bufio.NewScanner(file)
+	}
+
+	return nil
+}
+
+/** Fallback for plugins that only take one record in their arguments */
+func (ce *ComputeEngineImpl) execMapper(pluginPath string, input io.Reader) error {
+	scanner := bufio.NewScanner(input)
 	i := 0
 	for scanner.Scan() {
 		/** Run external plugin and get stdout */
@@ -68,28 +116,10 @@ func (ce *ComputeEngineImpl) RunMapper(pluginPath, dataPath string) error {
 		}
 		i++
 	}
-	ce.context.ClearKeysTracker()
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	/** Sort mapper output files before */
-	for filename := range ce.context.GetMapperOutputFiles() {
-		logrus.WithFields(logrus.Fields{"Filename": filename}).Info("External Sort")
-		err = extsort.NewExtSort().Sort(filename)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("External Sort error")
-			return err
-		}
-	}
-
-	/** Remove unnecessary plugin/files */
-	os.Remove(pluginPath)
-
-	return nil
+	return scanner.Err()
 }
 
+/** Runs the plugin over each line of dataPath: a key followed by all its values, tab separated */
 func (ce *ComputeEngineImpl) RunReducer(pluginPath, dataPath string) error {
 	file, err := os.Open(dataPath)
 	if err != nil {
@@ -105,9 +135,41 @@ func (ce *ComputeEngineImpl) RunReducer(pluginPath, dataPath string) error {
 		"IsDir": stat.IsDir(),
 	}).Warn("Sorted File")
 	logrus.Warn("Running reducer on: " + dataPath)
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
+	if isGoPlugin(pluginPath) {
+		logrus.WithFields(logrus.Fields{"Plugin": pluginPath}).Info("Running reducer in process")
+		err = ce.runGoReducer(pluginPath, file)
+	} else if worker, workerErr := startWorker(pluginPath, REDUCE); workerErr == nil {
+		logrus.WithFields(logrus.Fields{"Plugin": pluginPath}).Info("Running reducer as plugin worker")
+		err = worker.run(func(send func(key, value []byte) error) error {
+			scanner := extsort.NewScanner(file)
+			for scanner.Scan() {
+				key, values, _ := strings.Cut(scanner.Text(), "\t")
+				if err := send([]byte(key), []byte(values)); err != nil {
+					return err
+				}
+			}
+			return scanner.Err()
+		}, func(key, value []byte) {
+			if len(key) > 0 && len(value) > 0 {
+				ce.context.Emit(key, value)
+			}
+		})
+	} else if errors.Is(workerErr, errNotWorker) {
+		logrus.WithFields(logrus.Fields{"Plugin": pluginPath}).Info("Plugin is not a worker, running it once per key")
+		err = ce.execReducer(pluginPath, file)
+	} else {
+		err = workerErr
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Error running reducer in Compute Engine - Run Reducer")
+		return err
+	}
+
+	return nil
+}
+
+func (ce *ComputeEngineImpl) execReducer(pluginPath string, file *os.File) error {
+	scanner := extsort.NewScanner(file)
 	for scanner.Scan() {
 		/** Run external plugin and get stdout */
 		line := scanner.Text()
@@ -123,35 +185,65 @@ func (ce *ComputeEngineImpl) RunReducer(pluginPath, dataPath string) error {
 		if err != nil {
 			return err
 		}
-		key, value, ok := parse(string(out), "\t")
+		key, value, ok := parse(strings.TrimSuffix(string(out), "\n"), "\t")
 		if !ok || key == "" || value == "" {
 			continue
 		}
 		ce.context.Emit([]byte(key), []byte(value))
 	}
+	return scanner.Err()
+}
 
-	if err := scanner.Err(); err != nil {
-		logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Error reading sorted file in Compute Engine - Run Redcuer")
+func (ce *ComputeEngineImpl) runGoMapper(pluginPath string, input io.Reader) (err error) {
+	mapReduce, err := loadGoPlugin(pluginPath)
+	if err != nil {
 		return err
 	}
+	defer recoverPlugin(&err)
+	scanner := extsort.NewScanner(input)
+	for i := 0; scanner.Scan(); i++ {
+		mapReduce.Map(i, scanner.Text(), ce.context)
+	}
+	return scanner.Err()
+}
 
-	return nil
+func (ce *ComputeEngineImpl) runGoReducer(pluginPath string, file *os.File) (err error) {
+	mapReduce, err := loadGoPlugin(pluginPath)
+	if err != nil {
+		return err
+	}
+	defer recoverPlugin(&err)
+	scanner := extsort.NewScanner(file)
+	for scanner.Scan() {
+		key, values, _ := strings.Cut(scanner.Text(), "\t")
+		mapReduce.Reduce([]byte(key), []byte(values), ce.context)
+	}
+	return scanner.Err()
 }
 
-/** Sends each mapper output file to the corresponding partition. Once sent, removes the local files */
-func (ce *ComputeEngineImpl) Shuffle() map[string]*messages.Node {
+/**
+* Sends each mapper output file to the corresponding partition. Once sent, removes the local files.
+* With partitions given, only those are sent and the others dropped: their reducers already have them.
+ */
+func (ce *ComputeEngineImpl) Shuffle(partitions []int32) (map[string]*messages.Node, error) {
 	filesTable := make(map[string]*messages.Node)
 	logrus.WithFields(logrus.Fields{"MapperOutputFiles": ce.context.GetMapperOutputFiles()}).Info("Mapper output files")
 	for filePath, partitionIndex := range ce.context.GetMapperOutputFiles() {
-		file, _ := os.ReadFile(filePath)
+		if len(partitions) > 0 && !containsPartition(partitions, partitionIndex) {
+			os.Remove(filePath)
+			continue
+		}
+		file, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
 		reducerNode := ce.context.GetReducers()[partitionIndex]
 		addr := helpers.GetAddr(reducerNode.Hostname, int(reducerNode.Port))
-		msgHandler, _ := messages.GetMessageHandlerFor(addr)
-		err := msgHandler.SendComputeStore(helpers.GetFilename(filePath), file)
 		logrus.WithFields(logrus.Fields{"Filename": filePath, "ReducerHostname": reducerNode.Hostname, "ReducerPort": reducerNode.Port}).Info("Shuffling")
+		err = sendMapperOutput(addr, ce.context.GetJobId(), helpers.GetFilename(filePath), file)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Error sending mapper output file to reducer")
-			return nil
+			return nil, errors.New("could not send mapper output to reducer " + reducerNode.Uuid + ": " + err.Error())
 		}
 		filesTable[helpers.GetFilename(filePath)] = reducerNode
 		if ce.context.GetNodeUuid() != reducerNode.Uuid {
@@ -159,7 +251,36 @@ func (ce *ComputeEngineImpl) Shuffle() map[string]*messages.Node {
 			os.Remove(filePath)
 		}
 	}
-	return filesTable
+	return filesTable, nil
+}
+
+func containsPartition(partitions []int32, partitionIndex int) bool {
+	for _, partition := range partitions {
+		if int(partition) == partitionIndex {
+			return true
+		}
+	}
+	return false
+}
+
+/** Waits for the reducer's ack so the file is complete before the reduce phase starts */
+func sendMapperOutput(addr, jobId, filename string, file []byte) error {
+	msgHandler, err := messages.GetMessageHandlerFor(addr)
+	if err != nil {
+		return err
+	}
+	defer msgHandler.Close()
+	if err := msgHandler.SendComputeStore(jobId, filename, file); err != nil {
+		return err
+	}
+	wrapper, err := msgHandler.Receive()
+	if err != nil {
+		return err
+	}
+	if ack := wrapper.GetAckMessage(); ack == nil || !ack.Ok {
+		return errors.New("reducer did not store " + filename)
+	}
+	return nil
 }
 
 func parse(str, delimiter string) (string, string, bool) {
@@ -2,15 +2,26 @@ package compute_engine
 
 import (
 	"adfs/common"
+	ec "adfs/erasure_coding"
 	"adfs/helpers"
 	"adfs/messages"
 	"adfs/server"
+	"errors"
+	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+const CONTROLLER_CHECK_DELAY_S = 5
+
+// runs of a map or reduce task before its job fails, unless started with --max-task-attempts
+const DEFAULT_MAX_TASK_ATTEMPTS = 3
+
 type ComputeEngineResourceManager interface {
 	Start()
 	Stop()
@@ -19,38 +30,85 @@ type ComputeEngineResourceManager interface {
 // CERM == ComputeEngineResourceManager
 // The longer the name the more PRO you are
 type CERMImpl struct {
-	controllerAddr string
-	server         server.Server
+	controllerAddrs []string
+	server          server.Server
+	scheduler       Scheduler
+	maxTaskAttempts int
+	mapAttempts     int32 // ids handed out to map attempts so far
+	jobQueue        JobQueue
+	jobs            int32 // ids handed out to jobs so far
+	startedAt       time.Time
+	quit            chan bool
 }
 
-func NewComputeEngineResourceManager(controllerAddr string, server server.Server) ComputeEngineResourceManager {
+func NewComputeEngineResourceManager(
+	controllerAddrs []string,
+	server server.Server,
+	taskSlots int,
+	maxTaskAttempts int,
+	concurrentJobs int,
+) ComputeEngineResourceManager {
 	return &CERMImpl{
-		controllerAddr: controllerAddr,
-		server:         server,
+		controllerAddrs: controllerAddrs,
+		server:          server,
+		scheduler:       NewScheduler(taskSlots),
+		maxTaskAttempts: maxTaskAttempts,
+		jobQueue:        NewJobQueue(concurrentJobs),
+		startedAt:       time.Now(),
+		quit:            make(chan bool),
 	}
 }
 
 func (cerm *CERMImpl) Start() {
 	logrus.Info("Registering to Controller")
-	cerm.sendRegistrationMessage()
+	helpers.RetryWithBackoff("Controller", cerm.sendRegistrationMessage, cerm.quit)
+	go cerm.watchController()
 	cerm.server.Start(cerm.handleConnection)
 }
 
-func (cerm *CERMImpl) Stop() {}
+func (cerm *CERMImpl) Stop() {
+	close(cerm.quit)
+}
 
-func (cerm *CERMImpl) sendRegistrationMessage() {
-	msgHandler, err := messages.GetMessageHandlerFor(cerm.controllerAddr)
+func (cerm *CERMImpl) sendRegistrationMessage() error {
+	msgHandler, err := messages.GetLeaderMessageHandler(cerm.controllerAddrs)
 	if err != nil {
-		logrus.Error("A-DFS Controller OFFLINE")
-		return
+		return err
 	}
-	msgHandler.SendRegistrationMessage(
+	defer msgHandler.Close()
+	return msgHandler.SendRegistrationMessage(
 		common.COMPUTE_ENGINE,
 		cerm.server.GetHostname(),
 		cerm.server.GetPort(),
+		nil,
 	)
 }
 
+/**
+* Registers again on every tick: a controller restarted (or failed over)
+* between two ticks may not know where the compute engine is, and
+* registering is idempotent.
+ */
+func (cerm *CERMImpl) watchController() {
+	ticker := time.NewTicker(CONTROLLER_CHECK_DELAY_S * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cerm.quit:
+			return
+		case <-ticker.C:
+			if cerm.sendRegistrationMessage() == nil {
+				continue
+			}
+			logrus.Warn("A-DFS Controller OFFLINE. Reconnecting...")
+			if !helpers.RetryWithBackoff("Controller", cerm.sendRegistrationMessage, cerm.quit) {
+				return
+			}
+			logrus.Info("Registered again to Controller")
+		}
+	}
+}
+
 func (cerm *CERMImpl) handleConnection(messageHandler *messages.MessageHandler) {
 	wrapper, _ := messageHandler.Receive()
 
@@ -65,20 +123,27 @@ func (cerm *CERMImpl) handleConnection(messageHandler *messages.MessageHandler)
 			"OutputFilename": outputFilename,
 			"hasPlugin":      len(plugin.Plugin) > 0,
 		}).Info("New Compute Request")
-		cerm.handleNewJob(messageHandler, filename, plugin, outputFilename)
+		job := cerm.newJob(messageHandler, filename, plugin, outputFilename)
+		/** The client hears about the queue before the job can send anything else */
+		queued := make(chan bool)
+		ahead := cerm.jobQueue.Submit(func() {
+			<-queued
+			cerm.handleNewJob(job)
+		})
+		logrus.WithFields(logrus.Fields{"JobId": job.id, "Ahead": ahead}).Info("Job queued")
+		messageHandler.SendJobQueued(job.id, ahead)
+		close(queued)
 	}
 }
 
-func (cerm *CERMImpl) handleNewJob(
-	statusUpdateConn *messages.MessageHandler,
-	filename string,
-	plugin *messages.Plugin,
-	outputFilename string,
-) {
+func (cerm *CERMImpl) handleNewJob(job *job) {
+	statusUpdateConn, filename, plugin := job.statusUpdateConn, job.filename, job.plugin
 	defer statusUpdateConn.Close()
-	controllerConn, err := messages.GetMessageHandlerFor(cerm.controllerAddr)
+	/** Frees the job slot right away; attempts still running are waited for in the background */
+	defer func() { go cerm.endJob(job) }()
+	controllerConn, err := messages.GetLeaderMessageHandler(cerm.controllerAddrs)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"ControllerAddr": cerm.controllerAddr}).Error("Could not connect to controller")
+		logrus.WithFields(logrus.Fields{"ControllerAddrs": cerm.controllerAddrs}).Error("Could not connect to controller")
 		return
 	}
 
@@ -90,84 +155,62 @@ func (cerm *CERMImpl) handleNewJob(
 	case *messages.Wrapper_FileMessage:
 		statusUpdateConn.SendComputationStatus(messages.JobStatus_job_accepted, true, "")
 		file := msg.FileMessage
+		/** Parity chunks of erasure coded files hold no input data */
+		chunks := make([]*messages.Chunk, 0)
+		for _, chunk := range file.Chunks {
+			if !ec.IsParity(chunk) {
+				chunks = append(chunks, chunk)
+			}
+		}
 		logrus.WithFields(logrus.Fields{
 			"Filename":  file.Name,
 			"Dirname":   file.Dirname,
-			"numChunks": len(file.Chunks),
+			"numChunks": len(chunks),
 		}).Info("Target file information")
-		/** Reducers assignment */
-		reducers := getReducers(file.Chunks)
-		logrus.WithFields(logrus.Fields{"Reducers": reducers}).Info("Auto-assigned reducers")
-		/** Send computation jobs */
-		filesTableChan := make(chan map[string]*messages.Node)
-		for _, chunk := range msg.FileMessage.Chunks {
-			go cerm.sendComputationJob(statusUpdateConn, chunk, plugin, outputFilename, reducers, filesTableChan)
-		}
-		reducersMap := make(map[string]*messages.Node)
-		filesTable := make(map[string][]string)
-		for i := 0; i < len(msg.FileMessage.Chunks); i++ {
-			mapperOutputFilesTable := <-filesTableChan
-			if mapperOutputFilesTable == nil {
-				logrus.WithFields(logrus.Fields{"ErrorMsg": "no files table"}).Error("Mapper phase failed")
-				statusUpdateConn.SendComputationStatus(
-					messages.JobStatus_job_mappers,
-					false,
-					"Something went wrong in one of the mappers")
+		/** Total order jobs run range partitioned over boundaries sampled from the input */
+		if plugin.Partitioner.GetType() == messages.PartitionerType_partition_total_order {
+			statusUpdateConn.SendComputationStatus(messages.JobStatus_job_accepted, true, "Sampling input keys")
+			if err := cerm.resolveTotalOrder(chunks, plugin); err != nil {
+				logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Sampling failed")
+				statusUpdateConn.SendComputationStatus(messages.JobStatus_job_accepted, false, "Sampling failed: "+err.Error())
 				return
 			}
-			for filename, node := range mapperOutputFilesTable {
-				reducersMap[node.Uuid] = node
-				if filenames, ok := filesTable[node.Uuid]; ok {
-					filesTable[node.Uuid] = append(filenames, filename)
-				} else {
-					filesTable[node.Uuid] = []string{filename}
-				}
-			}
-			statusMsg := "Mappers completed " + strconv.Itoa(i+1) + "/" + strconv.Itoa(len(msg.FileMessage.Chunks))
-			statusUpdateConn.SendComputationStatus(
-				messages.JobStatus_job_mappers,
-				true,
-				statusMsg)
-			logrus.Info(statusMsg)
 		}
-
-		/** Get files table with the form map[Node] []string */
-		reducerMap := make(map[*messages.Node][]string)
-		for uuid, filenames := range filesTable {
-			reducerMap[reducersMap[uuid]] = filenames
+		/** Reducers assignment */
+		reducers, err := cerm.getReducers(chunks, plugin)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Cannot assign reducers")
+			statusUpdateConn.SendComputationStatus(messages.JobStatus_job_accepted, false, err.Error())
+			return
 		}
-		for node, filenames := range reducerMap {
-			logrus.WithFields(logrus.Fields{"UUID": node.Uuid, "Files": filenames}).Info("Partitions")
+		logrus.WithFields(logrus.Fields{"Reducers": reducers}).Info("Assigned reducers")
+		/** Send computation jobs */
+		filesTable, counters, err := cerm.runMappers(job, chunks, reducers, nil)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Mapper phase failed")
+			statusUpdateConn.SendComputationStatus(messages.JobStatus_job_mappers, false, err.Error())
+			return
 		}
-		statusUpdateConn.SendComputationStatus(messages.JobStatus_job_reducers, true, "Initiating Reduce phase")
-
-		reducerChannel := make(chan bool)
-		for reducerNumber, reducer := range reducers {
-			go cerm.sendReduceJob(reducer, statusUpdateConn, filesTable[reducer.Uuid], plugin, reducerChannel, int32(reducerNumber), outputFilename)
+		if plugin.Combine || plugin.Streaming.GetCombiner() != "" {
+			logrus.WithFields(logrus.Fields{
+				"InputRecords":  counters.CombineInputRecords,
+				"OutputRecords": counters.CombineOutputRecords,
+			}).Info("Combiners complete")
 		}
 
-		reducersFailed := 0
-		for i := 0; i < len(reducers); i++ {
-			ok := <-reducerChannel
-			if ok {
-				statusUpdateConn.SendComputationStatus(
-					messages.JobStatus_job_reducers,
-					true,
-					"Reducers completed "+strconv.Itoa(i+1)+"/"+strconv.Itoa(len(msg.FileMessage.Chunks)))
-			} else {
-				reducersFailed++
-			}
+		for partitionIndex, filenames := range filesTable {
+			logrus.WithFields(logrus.Fields{"UUID": reducers[partitionIndex].Uuid, "Partition": partitionIndex, "Files": filenames}).Info("Partitions")
 		}
-		statusUpdateConn.SendComputationStatus(
-			messages.JobStatus_job_reducers,
-			true,
-			"Reducers successful "+strconv.Itoa(len(reducers)-reducersFailed)+
-				"/"+strconv.Itoa(len(reducers))+
-				"; Failed "+strconv.Itoa(reducersFailed)+"/"+strconv.Itoa(len(reducers)))
+		statusUpdateConn.SendComputationStatus(messages.JobStatus_job_reducers, true, "Initiating Reduce phase")
 
+		if err := cerm.runReducers(job, chunks, reducers, filesTable); err != nil {
+			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Reduce phase failed")
+			statusUpdateConn.SendComputationStatus(messages.JobStatus_job_reducers, false, err.Error())
+			return
+		}
 		logrus.Info("Reduce Phase complete")
 
-		statusUpdateConn.SendComputationStatus(messages.JobStatus_job_done, true, "")
+		statusUpdateConn.SendJobDone(counters)
 
 	/** Something went wrong */
 	case *messages.Wrapper_AckMessage:
@@ -178,146 +221,482 @@ func (cerm *CERMImpl) handleNewJob(
 	}
 }
 
-func getReducers(chunks []*messages.Chunk) []*messages.Node {
-	storageNodes := make(map[string]*messages.Node)
+/**
+* Spreads the job's reducer slots over the online storage nodes, nodes holding
+* the input first, several slots per node when the job asks for more reducers
+* than there are nodes.
+ */
+func (cerm *CERMImpl) getReducers(chunks []*messages.Chunk, plugin *messages.Plugin) ([]*messages.Node, error) {
+	holders := make(map[string]bool)
 	for _, chunk := range chunks {
-		for uuid, storageNode := range chunk.StorageNodes {
-			storageNodes[uuid] = storageNode
+		for uuid := range chunk.StorageNodes {
+			holders[uuid] = true
 		}
 	}
-	var reducers []*messages.Node
-	// we gonna set the max amount of reducers to 3 and min 1
-	var numReducers int
-	if len(storageNodes) > 5 {
-		numReducers = 2
-	} else {
-		numReducers = 1
-	}
-	i := 0
-	for _, storageNode := range storageNodes {
-		if i == numReducers {
-			break
+	numReducers := int(plugin.Reducers)
+	if numReducers == 0 {
+		numReducers = defaultNumReducers(len(holders), plugin.Partitioner)
+	}
+	if _, err := NewPartitioner(plugin.Partitioner, numReducers); err != nil {
+		return nil, err
+	}
+
+	nodes, err := cerm.getOnlineNodes()
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("no storage nodes online to run reducers")
+	}
+	rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return holders[nodes[i].Uuid] && !holders[nodes[j].Uuid]
+	})
+	reducers := make([]*messages.Node, numReducers)
+	for i := range reducers {
+		reducers[i] = nodes[i%len(nodes)]
+	}
+	return reducers, nil
+}
+
+/** One reducer, or two on bigger inputs; range partitioning implies its own count */
+func defaultNumReducers(numHolders int, partitioner *messages.Partitioner) int {
+	if partitioner.GetType() == messages.PartitionerType_partition_range {
+		return len(partitioner.Boundaries) + 1
+	}
+	if numHolders > 5 {
+		return 2
+	}
+	return 1
+}
+
+func (cerm *CERMImpl) getOnlineNodes() ([]*messages.Node, error) {
+	msgHandler, err := messages.GetLeaderMessageHandler(cerm.controllerAddrs)
+	if err != nil {
+		return nil, err
+	}
+	defer msgHandler.Close()
+	if err := msgHandler.SendClusterStatsRequest(); err != nil {
+		return nil, err
+	}
+	wrapper, err := msgHandler.Receive()
+	if err != nil {
+		return nil, err
+	}
+	storageNodes := wrapper.GetStorageNodesMessage()
+	if storageNodes == nil {
+		return nil, errors.New("could not get storage nodes from controller")
+	}
+	nodes := make([]*messages.Node, 0)
+	for _, node := range storageNodes.Nodes {
+		if node.State == common.NODE_ONLINE {
+			nodes = append(nodes, &messages.Node{Uuid: node.Uuid, Hostname: node.Hostname, Port: node.Port})
 		}
-		reducers = append(reducers, storageNode)
-		i++
 	}
-	return reducers
+	return nodes, nil
 }
 
-func getRandomNode(chunk *messages.Chunk) *messages.Node {
-	keys := make([]string, len(chunk.StorageNodes))
-	i := 0
-	for k := range chunk.StorageNodes {
-		keys[i] = k
-		i++
+func getChunkHolders(chunk *messages.Chunk) []*messages.Node {
+	holders := make([]*messages.Node, 0, len(chunk.StorageNodes))
+	for _, storageNode := range chunk.StorageNodes {
+		holders = append(holders, storageNode)
 	}
-	randomUuid := keys[rand.Intn(len(keys))]
-	return chunk.StorageNodes[randomUuid]
+	return holders
 }
 
-func (cerm *CERMImpl) sendComputationJob(
-	statusUpdateConn *messages.MessageHandler,
+/**
+* Runs the map task of every chunk, each with its own retries, and returns
+* the mapper output files by partition. partitions limits what mappers
+* shuffle when re-running output a reducer lost.
+ */
+func (cerm *CERMImpl) runMappers(
+	job *job,
+	chunks []*messages.Chunk,
+	reducers []*messages.Node,
+	partitions []int32,
+) (map[int][]string, *messages.Counters, error) {
+	type result struct {
+		status *messages.ComputationStatus
+		err    error
+	}
+	results := make(chan result, len(chunks))
+	tracker := NewProgressTracker(len(chunks))
+	for _, chunk := range chunks {
+		go func(chunk *messages.Chunk) {
+			status, err := cerm.runMapTask(job, tracker, chunk, reducers, partitions)
+			results <- result{status, err}
+		}(chunk)
+	}
+	filesTable := make(map[int][]string) // partition index -> mapper output files
+	counters := &messages.Counters{}
+	for i := 0; i < len(chunks); i++ {
+		res := <-results
+		if res.err != nil {
+			return nil, nil, res.err
+		}
+		if res.status.Counters != nil {
+			counters.CombineInputRecords += res.status.Counters.CombineInputRecords
+			counters.CombineOutputRecords += res.status.Counters.CombineOutputRecords
+		}
+		for filename := range res.status.FilesTable {
+			partitionIndex, err := PartitionOf(filename)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Unexpected mapper output file")
+				continue
+			}
+			filesTable[partitionIndex] = append(filesTable[partitionIndex], filename)
+		}
+		if partitions == nil {
+			statusMsg := "Mappers completed " + strconv.Itoa(i+1) + "/" + strconv.Itoa(len(chunks))
+			job.statusUpdateConn.SendComputationStatus(messages.JobStatus_job_mappers, true, statusMsg)
+			logrus.WithFields(logrus.Fields{"JobId": job.id}).Info(statusMsg)
+		}
+	}
+	return filesTable, counters, nil
+}
+
+/** One run of a map task */
+type mapAttempt struct {
+	id      int32
+	node    *messages.Node
+	started time.Time
+	status  *messages.ComputationStatus
+	err     error
+}
+
+/**
+* Runs a map task until one of its attempts succeeds. A failed attempt is
+* retried on the chunk's other replica holders, then on any of them again.
+* An attempt running well behind the median of the phase (see
+* ProgressTracker) gets a speculative duplicate on another holder with a free
+* slot; the first to succeed wins and the other one's output is discarded.
+ */
+func (cerm *CERMImpl) runMapTask(
+	job *job,
+	tracker *ProgressTracker,
 	chunk *messages.Chunk,
-	plugin *messages.Plugin,
-	outputFilename string,
 	reducers []*messages.Node,
-	ok chan<- map[string]*messages.Node,
-) {
-	if statusUpdateConn.IsClosed {
-		ok <- nil
-		return
+	partitions []int32,
+) (*messages.ComputationStatus, error) {
+	holders := getChunkHolders(chunk)
+	if len(holders) == 0 {
+		return nil, fmt.Errorf("no storage node holds %s", chunk.ChunkName)
+	}
+	results := make(chan *mapAttempt)
+	running := make(map[string]*mapAttempt) // [uuid] attempts in flight
+	launch := func(sn *messages.Node) *mapAttempt {
+		if !job.startTask() {
+			cerm.scheduler.Release(sn)
+			return nil
+		}
+		attempt := &mapAttempt{id: atomic.AddInt32(&cerm.mapAttempts, 1), node: sn, started: time.Now()}
+		running[sn.Uuid] = attempt
+		go func() {
+			attempt.status, attempt.err = cerm.sendComputationJob(job, attempt, chunk, reducers, partitions)
+			job.finishTask()
+			results <- attempt
+		}()
+		return attempt
+	}
+	ticker := time.NewTicker(SPECULATION_CHECK_INTERVAL)
+	defer ticker.Stop()
+	failed := make(map[string]bool)
+	attempts := 0
+	var original *mapAttempt
+	var err error
+	for {
+		if len(running) == 0 {
+			if attempts >= cerm.maxTaskAttempts {
+				return nil, fmt.Errorf("mapper of %s failed %d times: %s", chunk.ChunkName, attempts, err.Error())
+			}
+			/** Waits for a free slot on one of the chunk's replica holders */
+			if original = launch(cerm.scheduler.Acquire(withoutNodes(holders, failed))); original == nil {
+				return nil, errors.New("job ended")
+			}
+		}
+		select {
+		case <-ticker.C:
+			if len(running) > 1 {
+				continue // already speculating
+			}
+			runtime := time.Since(original.started)
+			straggler, median := tracker.IsStraggler(runtime)
+			if !straggler {
+				continue
+			}
+			others := make([]*messages.Node, 0, len(holders))
+			for _, holder := range holders {
+				if running[holder.Uuid] == nil && !failed[holder.Uuid] {
+					others = append(others, holder)
+				}
+			}
+			if sn := cerm.scheduler.TryAcquire(others); sn != nil {
+				msg := fmt.Sprintf("Mapper of %s on %s running for %s, median %s: speculating on %s",
+					chunk.ChunkName, original.node.Uuid, runtime.Round(time.Second), median.Round(time.Millisecond), sn.Uuid)
+				logrus.WithFields(logrus.Fields{"JobId": job.id}).Warn(msg)
+				job.statusUpdateConn.SendComputationStatus(messages.JobStatus_job_mappers, true, msg)
+				launch(sn)
+			}
+		case attempt := <-results:
+			delete(running, attempt.node.Uuid)
+			if attempt.err == nil {
+				tracker.Finish(time.Since(attempt.started))
+				go cerm.discardLosers(job, results, len(running), chunk, reducers)
+				return attempt.status, nil
+			}
+			go cerm.discardMapOutput(job, attempt, chunk, reducers)
+			if attempt == original {
+				// a speculative copy still running is now the one checked for straggling
+				original = nil
+				for _, other := range running {
+					original = other
+				}
+			}
+			err = attempt.err
+			failed[attempt.node.Uuid] = true
+			attempts++
+			msg := fmt.Sprintf("Mapper of %s failed on %s (attempt %d/%d): %s", chunk.ChunkName, attempt.node.Uuid, attempts, cerm.maxTaskAttempts, err.Error())
+			logrus.WithFields(logrus.Fields{"JobId": job.id}).Warn(msg)
+			job.statusUpdateConn.SendComputationStatus(messages.JobStatus_job_mappers, true, msg)
+		}
+	}
+}
+
+/** Waits for the attempts still running after the winner, and throws away what they shuffled */
+func (cerm *CERMImpl) discardLosers(job *job, results chan *mapAttempt, losers int, chunk *messages.Chunk, reducers []*messages.Node) {
+	for i := 0; i < losers; i++ {
+		cerm.discardMapOutput(job, <-results, chunk, reducers)
+	}
+}
+
+/**
+* Mapper nodes drop the local output of their failed attempts themselves, but
+* whatever an attempt shuffled stays on the reducers until told otherwise.
+ */
+func (cerm *CERMImpl) discardMapOutput(job *job, attempt *mapAttempt, chunk *messages.Chunk, reducers []*messages.Node) {
+	attemptName := AttemptOutputName(chunk.ChunkName, attempt.id)
+	discarded := make(map[string]bool)
+	for _, reducer := range reducers {
+		if discarded[reducer.Uuid] {
+			continue
+		}
+		discarded[reducer.Uuid] = true
+		msgHandler, err := messages.GetMessageHandlerFor(helpers.GetAddr(reducer.Hostname, int(reducer.Port)))
+		if err != nil {
+			continue // a dead reducer takes its files with it
+		}
+		msgHandler.SendDiscardRequest(job.id, attemptName)
+		msgHandler.Receive()
+		msgHandler.Close()
 	}
-	sn := getRandomNode(chunk)
+	logrus.WithFields(logrus.Fields{"JobId": job.id, "Attempt": attemptName, "UUID": attempt.node.Uuid}).Info("Discarded map attempt output")
+}
+
+/** Nodes not in excluded; all of them when every node is excluded */
+func withoutNodes(nodes []*messages.Node, excluded map[string]bool) []*messages.Node {
+	remaining := make([]*messages.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if !excluded[node.Uuid] {
+			remaining = append(remaining, node)
+		}
+	}
+	if len(remaining) == 0 {
+		return nodes
+	}
+	return remaining
+}
+
+/** Runs a map attempt on the node it got a slot of, and frees the slot */
+func (cerm *CERMImpl) sendComputationJob(
+	job *job,
+	attempt *mapAttempt,
+	chunk *messages.Chunk,
+	reducers []*messages.Node,
+	partitions []int32,
+) (*messages.ComputationStatus, error) {
+	sn := attempt.node
+	defer cerm.scheduler.Release(sn)
 	snConn, err := messages.GetMessageHandlerFor(helpers.GetAddr(sn.GetHostname(), int(sn.GetPort())))
+	if err != nil {
+		return nil, errors.New("could not connect to mapper")
+	}
 	defer snConn.Close()
 	logrus.WithFields(logrus.Fields{
 		"StorageNodeAddr": snConn.GetRemoteAddr(),
+		"JobId":           job.id,
 		"Chunk":           chunk.ChunkName,
-		"Plugin":          plugin.Name,
+		"Plugin":          job.plugin.Name,
+		"Attempt":         attempt.id,
 	}).Info("Sending job to mapper")
-	if err != nil {
-		statusUpdateConn.SendComputationStatus(
-			messages.JobStatus_job_mappers,
-			false,
-			"Could not connect to Mapper")
-		ok <- nil
-		return
-	}
 
 	/**
 	* Init map phase
 	* Send enough information so that mappers can map the data and transfer
 	* the output to the corresponding reducers
 	 */
-	snConn.SendComputeRequest(
-		chunk.ChunkName,
-		plugin.Name,
-		outputFilename,
-		plugin.Plugin,
-		messages.ComputeType_MAP,
-		reducers,
-	)
+	if err := snConn.SendMapRequest(job.id, chunk.ChunkName, job.plugin, job.outputFilename, reducers, partitions, attempt.id); err != nil {
+		return nil, err
+	}
 	res, _ := snConn.Receive()
 	/** we need to know the names of the output files of the mappers */
 	switch res := res.Msg.(type) {
 	case *messages.Wrapper_ComputationStatusMessage:
-		if res.ComputationStatusMessage.Ok {
-			statusUpdateConn.SendComputationStatus(messages.JobStatus_job_mappers, true, res.ComputationStatusMessage.ErrorMessage)
-			filesTable := res.ComputationStatusMessage.FilesTable
-			ok <- filesTable
-		} else {
-			logrus.WithFields(logrus.Fields{
-				"ErrorMsg": res.ComputationStatusMessage.ErrorMessage,
-				"Stage":    res.ComputationStatusMessage.Status,
-			}).Error("Error in Distributed Computation")
-			ok <- nil
+		if !res.ComputationStatusMessage.Ok {
+			return nil, errors.New(res.ComputationStatusMessage.ErrorMessage)
+		}
+		return res.ComputationStatusMessage, nil
+	}
+	return nil, errors.New("lost connection to mapper")
+}
+
+/** Runs the reduce task of every partition, each with its own retries */
+func (cerm *CERMImpl) runReducers(
+	job *job,
+	chunks []*messages.Chunk,
+	reducers []*messages.Node,
+	filesTable map[int][]string,
+) error {
+	results := make(chan error, len(reducers))
+	for reducerNumber := range reducers {
+		go func(reducerNumber int) {
+			results <- cerm.runReduceTask(job, chunks, reducers, reducerNumber, filesTable[reducerNumber])
+		}(reducerNumber)
+	}
+	for i := 0; i < len(reducers); i++ {
+		if err := <-results; err != nil {
+			return err
+		}
+		job.statusUpdateConn.SendComputationStatus(
+			messages.JobStatus_job_reducers,
+			true,
+			"Reducers completed "+strconv.Itoa(i+1)+"/"+strconv.Itoa(len(reducers)))
+	}
+	return nil
+}
+
+/**
+* Reducers delete the mapper output they merged, so a failed reduce task has
+* lost its input: it moves to another node and every chunk is mapped again,
+* shuffling only this partition to the new reducer. Once an attempt got as
+* far as uploading, the output it left behind is removed before each retry.
+ */
+func (cerm *CERMImpl) runReduceTask(
+	job *job,
+	chunks []*messages.Chunk,
+	reducers []*messages.Node,
+	reducerNumber int,
+	filenames []string,
+) error {
+	reducer := reducers[reducerNumber]
+	failed := make(map[string]bool)
+	var err error
+	partialOutput := false
+	for attempt := 1; ; attempt++ {
+		if partialOutput {
+			cerm.removeReducerOutput(job, int32(reducerNumber))
+		}
+		var uploading bool
+		uploading, err = cerm.sendReduceJob(job, reducer, filenames, int32(reducerNumber))
+		if err == nil {
+			return nil
+		}
+		partialOutput = partialOutput || uploading
+		failed[reducer.Uuid] = true
+		msg := fmt.Sprintf("Reducer %d failed on %s (attempt %d/%d): %s", reducerNumber, reducer.Uuid, attempt, cerm.maxTaskAttempts, err.Error())
+		logrus.WithFields(logrus.Fields{"JobId": job.id}).Warn(msg)
+		if attempt == cerm.maxTaskAttempts {
+			break
+		}
+		job.statusUpdateConn.SendComputationStatus(messages.JobStatus_job_reducers, true, msg+". Re-running its mappers")
+
+		if reducer, err = cerm.replaceReducer(failed); err != nil {
+			break
+		}
+		rerouted := append([]*messages.Node{}, reducers...)
+		rerouted[reducerNumber] = reducer
+		var filesTable map[int][]string
+		if filesTable, _, err = cerm.runMappers(job, chunks, rerouted, []int32{int32(reducerNumber)}); err != nil {
+			break
 		}
+		filenames = filesTable[reducerNumber]
+	}
+	return fmt.Errorf("reducer %d failed: %s", reducerNumber, err.Error())
+}
+
+/** An online node that has not failed the task yet, or any online one when all have */
+func (cerm *CERMImpl) replaceReducer(failed map[string]bool) (*messages.Node, error) {
+	nodes, err := cerm.getOnlineNodes()
+	if err != nil {
+		return nil, err
 	}
+	nodes = withoutNodes(nodes, failed)
+	if len(nodes) == 0 {
+		return nil, errors.New("no storage nodes online to run reducers")
+	}
+	return nodes[rand.Intn(len(nodes))], nil
 }
 
+/** Best effort: a removal that fails shows up as the retry's own upload failing */
+func (cerm *CERMImpl) removeReducerOutput(job *job, reducerNumber int32) {
+	outputName := ReducerOutputName(job.outputFilename, reducerNumber)
+	controllerConn, err := messages.GetLeaderMessageHandler(cerm.controllerAddrs)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"JobId": job.id, "File": outputName}).Warn("Partial reducer output not removed: controller down")
+		return
+	}
+	defer controllerConn.Close()
+	controllerConn.SendRMRequest(outputName)
+	wrapper, _ := controllerConn.Receive()
+	if ack := wrapper.GetAckMessage(); ack == nil || !ack.Ok {
+		logrus.WithFields(logrus.Fields{"JobId": job.id, "File": outputName, "Ack": ack}).Warn("Partial reducer output not removed")
+		return
+	}
+	logrus.WithFields(logrus.Fields{"JobId": job.id, "File": outputName}).Info("Removed partial reducer output")
+}
+
+/** uploading tells whether the reducer got its output name from the controller before failing */
 func (cerm *CERMImpl) sendReduceJob(
+	job *job,
 	sn *messages.Node,
-	statusUpdateConn *messages.MessageHandler,
 	filenames []string,
-	plugin *messages.Plugin,
-	ok chan<- bool,
 	reducerNumber int32,
-	outputFilename string,
-) {
-	if statusUpdateConn.IsClosed {
-		return
+) (uploading bool, err error) {
+	/** Reducers run on their assigned node but still take one of its slots */
+	cerm.scheduler.Acquire([]*messages.Node{sn})
+	defer cerm.scheduler.Release(sn)
+	if !job.startTask() {
+		return false, errors.New("job ended")
 	}
-
+	defer job.finishTask()
 	snConn, err := messages.GetMessageHandlerFor(helpers.GetAddr(sn.GetHostname(), int(sn.GetPort())))
+	if err != nil {
+		return false, errors.New("could not connect to reducer")
+	}
 	defer snConn.Close()
 	logrus.WithFields(logrus.Fields{
 		"StorageNodeAddr": snConn.GetRemoteAddr(),
-		"Plugin":          plugin.Name,
+		"JobId":           job.id,
+		"Plugin":          job.plugin.Name,
 	}).Info("Sending reduce job")
-	if err != nil {
-		statusUpdateConn.SendComputationStatus(
-			messages.JobStatus_job_reducers,
-			false,
-			"Could not connect to Reducer")
-		ok <- false
-		return
-	}
 
 	/** Init reduce phase */
-	snConn.SendReduceRequest(filenames, plugin.Name, plugin.Plugin, reducerNumber, outputFilename)
-	res, _ := snConn.Receive()
+	if err := snConn.SendReduceRequest(job.id, filenames, job.plugin, reducerNumber, job.outputFilename); err != nil {
+		return false, err
+	}
 	/** Wait for status information */
-	switch res := res.Msg.(type) {
-	case *messages.Wrapper_ComputationStatusMessage:
-		if res.ComputationStatusMessage.Ok {
-			statusUpdateConn.SendComputationStatus(messages.JobStatus_job_reducers, true, "")
-			ok <- true
-		} else {
-			logrus.WithFields(logrus.Fields{
-				"ErrorMsg": res.ComputationStatusMessage.ErrorMessage,
-				"Stage":    res.ComputationStatusMessage.Status,
-			}).Error("Error in Distributed Computation")
-			ok <- false
+	for {
+		res, _ := snConn.Receive()
+		status := res.GetComputationStatusMessage()
+		if status == nil {
+			return uploading, errors.New("lost connection to reducer")
+		}
+		if status.Status == messages.JobStatus_job_uploading {
+			uploading = true
+			continue
+		}
+		if !status.Ok {
+			return uploading, errors.New(status.ErrorMessage)
 		}
+		return uploading, nil
 	}
 }
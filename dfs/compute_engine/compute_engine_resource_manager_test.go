@@ -0,0 +1,70 @@
+package compute_engine
+
+import (
+	"adfs/messages"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+/** A node whose address refuses connections, so every attempt sent to it fails */
+func deadNode(t *testing.T, uuid string) *messages.Node {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return &messages.Node{Uuid: uuid, Hostname: "127.0.0.1", Port: int32(port)}
+}
+
+/** A job whose status updates go nowhere */
+func testJob(t *testing.T) *job {
+	client, server := net.Pipe()
+	go io.Copy(io.Discard, server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return &job{id: "job_test_0001", statusUpdateConn: messages.NewMessageHandler(client)}
+}
+
+func TestRunMapTaskFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		holders      int
+		maxAttempts  int
+		wantAttempts int32
+		wantErr      string
+	}{
+		{"chunk without holders", 0, 3, 0, "no storage node holds chunk-0"},
+		{"single holder retried up to the limit", 1, 3, 3, "failed 3 times"},
+		{"limit of one attempt", 2, 1, 1, "failed 1 times"},
+		{"every holder tried", 2, 3, 3, "failed 3 times"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunk := &messages.Chunk{ChunkName: "chunk-0", StorageNodes: map[string]*messages.Node{}}
+			for i := 0; i < tt.holders; i++ {
+				uuid := "n" + strconv.Itoa(i)
+				chunk.StorageNodes[uuid] = deadNode(t, uuid)
+			}
+			cerm := &CERMImpl{scheduler: NewScheduler(1), maxTaskAttempts: tt.maxAttempts}
+			_, err := cerm.runMapTask(testJob(t), NewProgressTracker(1), chunk, nil, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+			if cerm.mapAttempts != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", cerm.mapAttempts, tt.wantAttempts)
+			}
+			for uuid, running := range cerm.scheduler.(*SchedulerImpl).running {
+				if running != 0 {
+					t.Errorf("%s still holds %d slots", uuid, running)
+				}
+			}
+		})
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"adfs/helpers"
 	m "adfs/messages"
 	"errors"
-	"hash/fnv"
-	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -20,10 +18,13 @@ type Context interface {
 	SetComputeOutputFilename(filename string)
 	GetComputeOutputFilename() string
 	SetReducerNodes(reducers []*m.Node)
+	SetPartitioner(partitioner Partitioner)
 	GetReducers() []*m.Node
 	GetStorageDir() string
 	SetNodeUuid(uuid string)
 	GetNodeUuid() string
+	SetJobId(jobId string)
+	GetJobId() string
 	ClearKeysTracker()
 }
 
@@ -32,9 +33,11 @@ const REDUCE = "REDUCE"
 
 type ContextImpl struct {
 	uuid              string
+	jobId             string
 	storageDir        string
 	outputFilePath    string
 	reducerNodes      []*m.Node
+	partitioner       Partitioner
 	mapperOutputFiles map[string]int
 	keysTracker       map[string]string
 }
@@ -55,6 +58,15 @@ func (c *ContextImpl) GetNodeUuid() string {
 	return c.uuid
 }
 
+/** Reducers keep the shuffled files of each job apart */
+func (c *ContextImpl) SetJobId(jobId string) {
+	c.jobId = jobId
+}
+
+func (c *ContextImpl) GetJobId() string {
+	return c.jobId
+}
+
 /** Both mapper and reducer will only have 1 output file */
 func (c *ContextImpl) SetComputeOutputFilename(filename string) {
 	c.outputFilePath = c.storageDir + "/" + helpers.GetFilename(filename)
@@ -64,9 +76,14 @@ func (c *ContextImpl) GetComputeOutputFilename() string {
 	return c.outputFilePath
 }
 
-/** Set reducer nodes so mapper knows where to send the data to */
+/** Set reducer nodes so mapper knows where to send the data to. Keys are hash partitioned by default */
 func (c *ContextImpl) SetReducerNodes(reducers []*m.Node) {
 	c.reducerNodes = reducers
+	c.partitioner = &hashPartitioner{len(reducers)}
+}
+
+func (c *ContextImpl) SetPartitioner(partitioner Partitioner) {
+	c.partitioner = partitioner
 }
 
 /** Returns reducer so that Compute Engine can _shuffle_ the mapper output files */
@@ -90,8 +107,9 @@ func (c *ContextImpl) ClearKeysTracker() {
 	c.keysTracker = nil
 }
 
+/** Empty values are kept: streaming mappers may print bare keys */
 func (c *ContextImpl) Write(key, value []byte) {
-	if len(key) == 0 || len(value) == 0 {
+	if len(key) == 0 {
 		return
 	}
 	/** determine where each line is going to be persisted, */
@@ -100,17 +118,9 @@ func (c *ContextImpl) Write(key, value []byte) {
 	var path string
 	var partitionIndex int
 	if extantPath, prst := c.keysTracker[stringifiedKey]; !prst {
-		numReducers := len(c.reducerNodes)
-		if numReducers == 1 {
-			partitionIndex = 0
-		} else {
-			hashinator := fnv.New32a()
-			hashinator.Write([]byte(stringifiedKey))
-			hash := int32(math.Abs(float64(hashinator.Sum32())))
-			partitionIndex = int(hash % int32(numReducers))
-		}
-		/* path for reducers will have the form of <chunkName>-part-<partitionIndex> */
-		path = c.outputFilePath + "-part-" + strconv.Itoa(partitionIndex)
+		partitionIndex = c.partitioner.Partition(stringifiedKey)
+		/* path for reducers will have the form of <chunkName>-attempt-<attempt>-part-<partitionIndex> */
+		path = c.outputFilePath + PARTITION_SUFFIX + strconv.Itoa(partitionIndex)
 		c.keysTracker[stringifiedKey] = path
 
 		/* create path to partition file */
@@ -144,8 +154,10 @@ func (c *ContextImpl) Write(key, value []byte) {
 		return
 	}
 	/* line is the composition of Key and a Value separated by a line */
-	var line []byte
-	line = append(key, []byte("\t")...)
+	// fresh slice: appending to key could write into the caller's array
+	line := make([]byte, 0, len(key)+len(value)+2)
+	line = append(line, key...)
+	line = append(line, []byte("\t")...)
 	line = append(line, value...)
 	line = append(line, []byte("\n")...)
 	_, err = file.Write(line)
@@ -158,7 +170,7 @@ func (c *ContextImpl) Write(key, value []byte) {
 	}
 }
 
-/* Reducer emits an output file which will then be stored in the DFS */
+/* Reducer emits an output file which will then be stored in the DFS, one key\tvalue line per call */
 func (c *ContextImpl) Emit(key, value []byte) {
 	path := c.outputFilePath
 	if _, err := os.Stat(path); err == nil {
@@ -176,10 +188,12 @@ func (c *ContextImpl) Emit(key, value []byte) {
 		return
 	}
 	/** line is the composition of Key and a Value separated by a line */
-	var line []byte
-	line = append(key, []byte("\t")...)
+	// fresh slice: appending to key could write into the caller's array
+	line := make([]byte, 0, len(key)+len(value)+2)
+	line = append(line, key...)
+	line = append(line, []byte("\t")...)
 	line = append(line, value...)
-	// line = append(line, []byte("\n")...)
+	line = append(line, []byte("\n")...)
 	file.Write(line)
 	file.Sync()
 	file.Close()
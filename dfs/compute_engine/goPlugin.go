@@ -0,0 +1,84 @@
+package compute_engine
+
+import (
+	"crypto/sha256"
+	"debug/elf"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"plugin"
+	"sync"
+)
+
+// a .so built with -buildmode=plugin exports a variable with this name implementing MapReduce
+const MAP_REDUCE_SYMBOL = "MapReduce"
+
+/**
+* Go can neither unload a plugin nor load the same one twice from another
+* path, and every task gets its own copy on disk, so loaded plugins are kept
+* by content: [sha256 of the .so] MapReduce
+ */
+var goPlugins = make(map[string]MapReduce)
+var goPluginsMu sync.Mutex
+
+/** Shared objects have no interpreter, unlike executables (PIE ones included) */
+func isGoPlugin(pluginPath string) bool {
+	file, err := elf.Open(pluginPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	if file.Type != elf.ET_DYN {
+		return false
+	}
+	for _, prog := range file.Progs {
+		if prog.Type == elf.PT_INTERP {
+			return false
+		}
+	}
+	return true
+}
+
+func loadGoPlugin(pluginPath string) (MapReduce, error) {
+	data, err := os.ReadFile(pluginPath)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	goPluginsMu.Lock()
+	defer goPluginsMu.Unlock()
+	if mapReduce, present := goPlugins[hash]; present {
+		return mapReduce, nil
+	}
+	// plugins must be built with the same toolchain and adfs packages as this binary
+	p, err := plugin.Open(pluginPath)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := p.Lookup(MAP_REDUCE_SYMBOL)
+	if err != nil {
+		return nil, err
+	}
+	var mapReduce MapReduce
+	switch s := symbol.(type) {
+	case MapReduce: // var MapReduce = &WordCount{} or methods on *WordCount
+		mapReduce = s
+	case *MapReduce: // var MapReduce compute_engine.MapReduce = ...
+		mapReduce = *s
+	}
+	if mapReduce == nil {
+		return nil, errors.New(MAP_REDUCE_SYMBOL + " in plugin does not implement compute_engine.MapReduce")
+	}
+	goPlugins[hash] = mapReduce
+	return mapReduce, nil
+}
+
+/** Turns a panic in plugin code into an error instead of taking the storage node down */
+func recoverPlugin(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("plugin panicked: %v", r)
+	}
+}
@@ -0,0 +1,86 @@
+package compute_engine
+
+import (
+	"adfs/helpers"
+	"adfs/messages"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+/**
+* A compute request, from the moment the resource manager accepts it. Storage
+* nodes keep every intermediate file of the job under its id, so jobs on the
+* same input or with the same plugin never share paths.
+ */
+type job struct {
+	id               string
+	statusUpdateConn *messages.MessageHandler
+	filename         string
+	plugin           *messages.Plugin
+	outputFilename   string
+	mu               sync.Mutex
+	ended            bool
+	tasks            sync.WaitGroup // attempts running on storage nodes
+}
+
+/** Ids look like job_<compute engine start time>_<sequence number>, e.g. job_20231020143205_0007 */
+func (cerm *CERMImpl) newJob(
+	statusUpdateConn *messages.MessageHandler,
+	filename string,
+	plugin *messages.Plugin,
+	outputFilename string,
+) *job {
+	return &job{
+		id:               fmt.Sprintf("job_%s_%04d", cerm.startedAt.Format("20060102150405"), atomic.AddInt32(&cerm.jobs, 1)),
+		statusUpdateConn: statusUpdateConn,
+		filename:         filename,
+		plugin:           plugin,
+		outputFilename:   outputFilename,
+	}
+}
+
+/** Registers an attempt about to run on a storage node; false once the job has ended */
+func (j *job) startTask() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.ended {
+		return false
+	}
+	j.tasks.Add(1)
+	return true
+}
+
+func (j *job) finishTask() {
+	j.tasks.Done()
+}
+
+/**
+* Once a job succeeds or fails no new attempt starts, but the ones already
+* running (speculative losers, tasks of a failed job) are waited for before
+* every storage node drops the job's files.
+ */
+func (cerm *CERMImpl) endJob(j *job) {
+	j.mu.Lock()
+	j.ended = true
+	j.mu.Unlock()
+	j.tasks.Wait()
+
+	nodes, err := cerm.getOnlineNodes()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"JobId": j.id, "ErrorMsg": err.Error()}).Error("Job files not cleaned up")
+		return
+	}
+	for _, node := range nodes {
+		msgHandler, err := messages.GetMessageHandlerFor(helpers.GetAddr(node.Hostname, int(node.Port)))
+		if err != nil {
+			continue
+		}
+		msgHandler.SendDiscardRequest(j.id, "")
+		msgHandler.Receive()
+		msgHandler.Close()
+	}
+	logrus.WithFields(logrus.Fields{"JobId": j.id}).Info("Job files cleaned up")
+}
@@ -0,0 +1,53 @@
+package compute_engine
+
+import "sync"
+
+// jobs running at once, unless the compute engine is started with --concurrent-jobs
+const DEFAULT_CONCURRENT_JOBS = 2
+
+/**
+* Starts jobs in arrival order, at most concurrency of them at once; the
+* others wait in a FIFO queue until a running job ends.
+ */
+type JobQueue interface {
+	// returns the number of jobs that will start before this one
+	Submit(run func()) int
+}
+
+type JobQueueImpl struct {
+	concurrency int
+	mu          sync.Mutex
+	running     int
+	queue       []func()
+}
+
+func NewJobQueue(concurrency int) JobQueue {
+	return &JobQueueImpl{concurrency: concurrency}
+}
+
+func (q *JobQueueImpl) Submit(run func()) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running < q.concurrency {
+		q.running++
+		go q.run(run)
+		return 0
+	}
+	q.queue = append(q.queue, run)
+	return len(q.queue)
+}
+
+/** Runs a job, then hands its slot to the oldest queued one */
+func (q *JobQueueImpl) run(run func()) {
+	for run != nil {
+		run()
+		q.mu.Lock()
+		run = nil
+		if len(q.queue) > 0 {
+			run, q.queue = q.queue[0], q.queue[1:]
+		} else {
+			q.running--
+		}
+		q.mu.Unlock()
+	}
+}
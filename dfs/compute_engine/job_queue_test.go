@@ -0,0 +1,92 @@
+package compute_engine
+
+import (
+	"adfs/messages"
+	"net"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJobQueueStartsJobsInOrderUpToTheCap(t *testing.T) {
+	const concurrency, jobs = 2, 6
+	q := NewJobQueue(concurrency)
+	started := make(chan int, jobs)
+	release := make([]chan bool, jobs)
+	var running, maxRunning int32
+	for i := 0; i < jobs; i++ {
+		release[i] = make(chan bool)
+		i := i
+		ahead := q.Submit(func() {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if now <= max || atomic.CompareAndSwapInt32(&maxRunning, max, now) {
+					break
+				}
+			}
+			started <- i
+			<-release[i]
+			atomic.AddInt32(&running, -1)
+		})
+		want := 0
+		if i >= concurrency {
+			want = i - concurrency + 1
+		}
+		if ahead != want {
+			t.Fatalf("job %d has %d jobs ahead, want %d", i, ahead, want)
+		}
+	}
+
+	first := []int{<-started, <-started}
+	sort.Ints(first)
+	if first[0] != 0 || first[1] != 1 {
+		t.Fatalf("jobs %v started first, want [0 1]", first)
+	}
+	select {
+	case i := <-started:
+		t.Fatalf("job %d started while %d were running", i, concurrency)
+	case <-time.After(50 * time.Millisecond):
+	}
+	// every job that ends hands its slot to the oldest queued one
+	for i := 0; i < jobs-concurrency; i++ {
+		close(release[i])
+		if got := <-started; got != i+concurrency {
+			t.Fatalf("job %d started after job %d ended, want job %d", got, i, i+concurrency)
+		}
+	}
+	for i := jobs - concurrency; i < jobs; i++ {
+		close(release[i])
+	}
+	if maxRunning > concurrency {
+		t.Errorf("%d jobs ran at once, cap is %d", maxRunning, concurrency)
+	}
+}
+
+func TestQueuedJobHearsJobQueued(t *testing.T) {
+	cerm := &CERMImpl{jobQueue: NewJobQueue(1), startedAt: time.Now()}
+	release := make(chan bool)
+	defer close(release)
+	cerm.jobQueue.Submit(func() { <-release }) // takes the only slot
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go cerm.handleConnection(messages.NewMessageHandler(server))
+	clientHandler := messages.NewMessageHandler(client)
+	plugin := &messages.Plugin{Name: "/streaming", Streaming: &messages.Streaming{Mapper: "cat", Reducer: "cat"}}
+	if err := clientHandler.SendComputeRequest("/in", plugin, "/out", messages.ComputeType_MAP, nil); err != nil {
+		t.Fatal(err)
+	}
+	wrapper, err := clientHandler.Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := wrapper.GetComputationStatusMessage()
+	if status.GetStatus() != messages.JobStatus_job_queued {
+		t.Fatalf("got %v, want job_queued", wrapper)
+	}
+	if status.JobId == "" || status.ErrorMessage != "1 jobs ahead" {
+		t.Errorf("got job id %q and %q, want an id and 1 jobs ahead", status.JobId, status.ErrorMessage)
+	}
+}
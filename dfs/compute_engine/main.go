@@ -1,23 +1,23 @@
 package compute_engine
 
 import (
-	"adfs/helpers"
 	s "adfs/server"
 	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
-	Port               int
-	ControllerHostname string
-	ControllerPort     int
+	Port            int
+	ControllerAddrs []string // every controller; requests go to the leader
+	TaskSlots       int      // tasks each storage node runs at once
+	MaxTaskAttempts int      // runs of a task before its job fails
+	ConcurrentJobs  int      // jobs running at once, the others wait in a queue
 }
 
 func Init(config Config) {
-	controllerAddr := helpers.GetAddr(config.ControllerHostname, config.ControllerPort)
 	server, err := s.NewServerAt(config.Port)
 	if err != nil {
 		logrus.Error("Error creating local server for Compute Engine: " + err.Error())
 	}
-	computeEngine := NewComputeEngineResourceManager(controllerAddr, server)
+	computeEngine := NewComputeEngineResourceManager(config.ControllerAddrs, server, config.TaskSlots, config.MaxTaskAttempts, config.ConcurrentJobs)
 	computeEngine.Start()
 }
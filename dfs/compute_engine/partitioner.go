@@ -0,0 +1,106 @@
+package compute_engine
+
+import (
+	"adfs/helpers"
+	"adfs/messages"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/** Mapper output files are named <chunkName>-attempt-<attempt>-part-<partitionIndex> */
+const PARTITION_SUFFIX = "-part-"
+const ATTEMPT_SUFFIX = "-attempt-"
+
+type Partitioner interface {
+	Partition(key string) int
+}
+
+/** Validates the job's partitioner against the number of reducers it will run with */
+func NewPartitioner(config *messages.Partitioner, numReducers int) (Partitioner, error) {
+	if numReducers < 1 {
+		return nil, errors.New("a job needs at least one reducer")
+	}
+	switch config.GetType() {
+	case messages.PartitionerType_partition_hash:
+		return &hashPartitioner{numReducers}, nil
+	case messages.PartitionerType_partition_key_prefix:
+		if config.PrefixLength < 1 {
+			return nil, errors.New("key prefix length must be at least 1")
+		}
+		return &prefixPartitioner{hashPartitioner{numReducers}, int(config.PrefixLength)}, nil
+	case messages.PartitionerType_partition_range:
+		boundaries := config.Boundaries
+		if len(boundaries) != numReducers-1 {
+			return nil, errors.New("range partitioning over " + strconv.Itoa(numReducers) +
+				" reducers needs " + strconv.Itoa(numReducers-1) + " boundaries, got " + strconv.Itoa(len(boundaries)))
+		}
+		for i := 1; i < len(boundaries); i++ {
+			if boundaries[i-1] >= boundaries[i] {
+				return nil, errors.New("range boundaries must be sorted and distinct")
+			}
+		}
+		return &rangePartitioner{boundaries}, nil
+	case messages.PartitionerType_partition_total_order:
+		return nil, errors.New("total order boundaries must be sampled by the compute engine first")
+	}
+	return nil, errors.New("unknown partitioner " + config.GetType().String())
+}
+
+type hashPartitioner struct {
+	numReducers int
+}
+
+func (p *hashPartitioner) Partition(key string) int {
+	if p.numReducers == 1 {
+		return 0
+	}
+	hashinator := fnv.New32a()
+	hashinator.Write([]byte(key))
+	return int(hashinator.Sum32() % uint32(p.numReducers))
+}
+
+/** Keys sharing a prefix end up in the same reducer, e.g. dates by month */
+type prefixPartitioner struct {
+	hashPartitioner
+	prefixLength int
+}
+
+func (p *prefixPartitioner) Partition(key string) int {
+	if len(key) > p.prefixLength {
+		key = key[:p.prefixLength]
+	}
+	return p.hashPartitioner.Partition(key)
+}
+
+/** Reducer i gets the keys in [boundaries[i-1], boundaries[i]), so outputs concatenate in key order */
+type rangePartitioner struct {
+	boundaries []string
+}
+
+func (p *rangePartitioner) Partition(key string) int {
+	return sort.Search(len(p.boundaries), func(i int) bool {
+		return key < p.boundaries[i]
+	})
+}
+
+/** Runs of a map task write apart, so a speculative duplicate never overwrites the original */
+func AttemptOutputName(chunkName string, attempt int32) string {
+	return helpers.GetFilename(chunkName) + ATTEMPT_SUFFIX + strconv.Itoa(int(attempt))
+}
+
+/** DFS file reducer number reducerNumber of a job writing to outputFilename uploads */
+func ReducerOutputName(outputFilename string, reducerNumber int32) string {
+	return outputFilename + "-" + strconv.Itoa(int(reducerNumber))
+}
+
+/** Partition index of a mapper output file, see PARTITION_SUFFIX */
+func PartitionOf(filename string) (int, error) {
+	i := strings.LastIndex(filename, PARTITION_SUFFIX)
+	if i < 0 {
+		return 0, errors.New("not a mapper output file: " + filename)
+	}
+	return strconv.Atoi(filename[i+len(PARTITION_SUFFIX):])
+}
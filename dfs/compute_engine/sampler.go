@@ -0,0 +1,132 @@
+package compute_engine
+
+import (
+	"adfs/helpers"
+	"adfs/messages"
+	"errors"
+	"math/rand"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// keys sampled over the whole input, split evenly between its chunks
+const TOTAL_ORDER_SAMPLE_SIZE = 10000
+const MIN_SAMPLES_PER_CHUNK = 10
+
+/**
+* Turns a total order job into a range partitioned one: samples keys from
+* every chunk and splits them in equally sized ranges, one per reducer, so the
+* reducer outputs <output>-0..<output>-N concatenate into sorted data. Keys
+* are sampled from the input lines, so the ranges are only balanced for
+* mappers keeping keys close to their input (sorts, filters...).
+ */
+func (cerm *CERMImpl) resolveTotalOrder(chunks []*messages.Chunk, plugin *messages.Plugin) error {
+	numReducers := int(plugin.Reducers)
+	if numReducers == 0 {
+		nodes, err := cerm.getOnlineNodes()
+		if err != nil {
+			return err
+		}
+		numReducers = len(nodes)
+	}
+	var boundaries []string
+	if numReducers > 1 && len(chunks) > 0 {
+		sampleSize := TOTAL_ORDER_SAMPLE_SIZE / len(chunks)
+		if sampleSize < MIN_SAMPLES_PER_CHUNK {
+			sampleSize = MIN_SAMPLES_PER_CHUNK
+		}
+		keys, err := sampleChunks(chunks, int32(sampleSize))
+		if err != nil {
+			return err
+		}
+		boundaries = getBoundaries(keys, numReducers)
+	}
+	logrus.WithFields(logrus.Fields{"Boundaries": boundaries}).Info("Sampled total order boundaries")
+	plugin.Partitioner = &messages.Partitioner{
+		Type:       messages.PartitionerType_partition_range,
+		Boundaries: boundaries,
+	}
+	// equal samples leave fewer distinct boundaries than asked for
+	plugin.Reducers = int32(len(boundaries) + 1)
+	return nil
+}
+
+func sampleChunks(chunks []*messages.Chunk, sampleSize int32) ([]string, error) {
+	type result struct {
+		keys []string
+		err  error
+	}
+	results := make(chan result)
+	for _, chunk := range chunks {
+		go func(chunk *messages.Chunk) {
+			keys, err := sampleChunk(chunk, sampleSize)
+			results <- result{keys, err}
+		}(chunk)
+	}
+	keys := make([]string, 0)
+	var err error
+	for range chunks {
+		res := <-results
+		if res.err != nil {
+			err = res.err
+		}
+		keys = append(keys, res.keys...)
+	}
+	return keys, err
+}
+
+/** Asks the chunk's holders, in random order, until one answers */
+func sampleChunk(chunk *messages.Chunk, sampleSize int32) ([]string, error) {
+	nodes := make([]*messages.Node, 0, len(chunk.StorageNodes))
+	for _, node := range chunk.StorageNodes {
+		nodes = append(nodes, node)
+	}
+	rand.Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+	err := errors.New("no storage node holds " + chunk.ChunkName)
+	for _, node := range nodes {
+		var keys []string
+		keys, err = requestSamples(node, chunk.ChunkName, sampleSize)
+		if err == nil {
+			return keys, nil
+		}
+		logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName, "UUID": node.Uuid, "ErrorMsg": err.Error()}).Warn("Could not sample chunk")
+	}
+	return nil, err
+}
+
+func requestSamples(node *messages.Node, chunkName string, sampleSize int32) ([]string, error) {
+	msgHandler, err := messages.GetMessageHandlerFor(helpers.GetAddr(node.Hostname, int(node.Port)))
+	if err != nil {
+		return nil, err
+	}
+	defer msgHandler.Close()
+	if err := msgHandler.SendSampleRequest(chunkName, sampleSize); err != nil {
+		return nil, err
+	}
+	wrapper, err := msgHandler.Receive()
+	if err != nil {
+		return nil, err
+	}
+	switch msg := wrapper.Msg.(type) {
+	case *messages.Wrapper_SamplesMessage:
+		return msg.SamplesMessage.Keys, nil
+	case *messages.Wrapper_AckMessage:
+		return nil, errors.New(msg.AckMessage.ErrorMessage)
+	}
+	return nil, errors.New("unexpected answer to sample request")
+}
+
+/** Splits the sorted sample in numReducers equal parts; the key starting each part but the first is a boundary */
+func getBoundaries(keys []string, numReducers int) []string {
+	sort.Strings(keys)
+	boundaries := make([]string, 0, numReducers-1)
+	for i := 1; i < numReducers && len(keys) > 0; i++ {
+		boundary := keys[i*len(keys)/numReducers]
+		// a boundary equal to the smallest key would leave the first reducer nothing
+		if boundary > keys[0] && (len(boundaries) == 0 || boundaries[len(boundaries)-1] < boundary) {
+			boundaries = append(boundaries, boundary)
+		}
+	}
+	return boundaries
+}
@@ -0,0 +1,114 @@
+package compute_engine
+
+import (
+	"adfs/messages"
+	"math/rand"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tasks a storage node runs at once, unless the compute engine is started with --task-slots
+const DEFAULT_TASK_SLOTS = 2
+
+/**
+* Hands out per storage node task slots, shared by every job. A task runs on
+* the least loaded of its candidate nodes (the replica holders of a map
+* task's chunk); when all of them are busy it waits in a FIFO queue until one
+* releases a slot.
+ */
+type Scheduler interface {
+	Acquire(candidates []*messages.Node) *messages.Node
+	TryAcquire(candidates []*messages.Node) *messages.Node
+	Release(node *messages.Node)
+}
+
+type SchedulerImpl struct {
+	slots   int
+	mu      sync.Mutex
+	running map[string]int // [uuid] tasks running
+	queue   []*pendingTask
+}
+
+type pendingTask struct {
+	candidates []*messages.Node
+	granted    chan *messages.Node
+}
+
+func NewScheduler(slots int) Scheduler {
+	return &SchedulerImpl{
+		slots:   slots,
+		running: make(map[string]int),
+	}
+}
+
+/** Blocks until one of the candidates has a free slot, and takes it; nil right away without candidates */
+func (s *SchedulerImpl) Acquire(candidates []*messages.Node) *messages.Node {
+	if len(candidates) == 0 {
+		return nil // no release could ever grant it
+	}
+	s.mu.Lock()
+	if node := s.take(candidates); node != nil {
+		s.mu.Unlock()
+		return node
+	}
+	task := &pendingTask{candidates, make(chan *messages.Node, 1)}
+	s.queue = append(s.queue, task)
+	logrus.WithFields(logrus.Fields{"Queued": len(s.queue)}).Info("All candidate nodes busy, task queued")
+	s.mu.Unlock()
+	return <-task.granted
+}
+
+/** Takes a free slot of one of the candidates without waiting; nil when all are busy or tasks are queued */
+func (s *SchedulerImpl) TryAcquire(candidates []*messages.Node) *messages.Node {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) > 0 {
+		return nil // queued tasks come before optional ones
+	}
+	return s.take(candidates)
+}
+
+/**
+* Frees a slot of node and gives it to the oldest queued task that can run
+* there. Queued tasks only wait on busy nodes, so one release starts at most
+* one of them.
+ */
+func (s *SchedulerImpl) Release(node *messages.Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[node.Uuid] > 0 {
+		s.running[node.Uuid]--
+	}
+	for i, task := range s.queue {
+		if granted := s.take(task.candidates); granted != nil {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			task.granted <- granted
+			return
+		}
+	}
+}
+
+/** Least loaded candidate with a free slot, ties broken at random; nil if all are full */
+func (s *SchedulerImpl) take(candidates []*messages.Node) *messages.Node {
+	var best *messages.Node
+	ties := 0
+	for _, node := range candidates {
+		running := s.running[node.Uuid]
+		if running >= s.slots {
+			continue
+		}
+		if best == nil || running < s.running[best.Uuid] {
+			best, ties = node, 1
+		} else if running == s.running[best.Uuid] {
+			ties++
+			if rand.Intn(ties) == 0 {
+				best = node
+			}
+		}
+	}
+	if best != nil {
+		s.running[best.Uuid]++
+	}
+	return best
+}
@@ -0,0 +1,93 @@
+package compute_engine
+
+import (
+	"adfs/messages"
+	"testing"
+	"time"
+)
+
+func testNodes(uuids ...string) []*messages.Node {
+	nodes := make([]*messages.Node, len(uuids))
+	for i, uuid := range uuids {
+		nodes[i] = &messages.Node{Uuid: uuid}
+	}
+	return nodes
+}
+
+func TestSchedulerSlotAllocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		slots      int
+		running    map[string]int
+		candidates []string
+		want       string // "" when no slot is free
+	}{
+		{"free node", 2, map[string]int{}, []string{"a"}, "a"},
+		{"least loaded", 2, map[string]int{"a": 1}, []string{"a", "b"}, "b"},
+		{"full node skipped", 2, map[string]int{"a": 2, "b": 1}, []string{"a", "b"}, "b"},
+		{"every candidate full", 1, map[string]int{"a": 1, "b": 1}, []string{"a", "b"}, ""},
+		{"busy non candidate ignored", 1, map[string]int{"c": 0, "a": 1}, []string{"a"}, ""},
+		{"no candidates", 2, map[string]int{}, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(tt.slots).(*SchedulerImpl)
+			for uuid, running := range tt.running {
+				s.running[uuid] = running
+			}
+			got := s.TryAcquire(testNodes(tt.candidates...))
+			if got == nil {
+				if tt.want != "" {
+					t.Fatalf("got no slot, want one on %s", tt.want)
+				}
+				return
+			}
+			if got.Uuid != tt.want {
+				t.Fatalf("got a slot on %s, want %q", got.Uuid, tt.want)
+			}
+			if s.running[got.Uuid] != tt.running[got.Uuid]+1 {
+				t.Errorf("%s runs %d tasks, want %d", got.Uuid, s.running[got.Uuid], tt.running[got.Uuid]+1)
+			}
+		})
+	}
+}
+
+func TestSchedulerReleaseGrantsQueuedTasksInOrder(t *testing.T) {
+	s := NewScheduler(1)
+	a := testNodes("a")
+	first := s.Acquire(a)
+	granted := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		go func(i int) {
+			s.Acquire(a)
+			granted <- i
+		}(i)
+		// each waiter queues before the next one starts
+		for len(s.(*SchedulerImpl).queuedTasks()) < i {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if s.TryAcquire(a) != nil {
+		t.Fatal("TryAcquire took a slot ahead of queued tasks")
+	}
+	s.Release(first)
+	if got := <-granted; got != 1 {
+		t.Fatalf("task %d started first, want 1", got)
+	}
+	s.Release(first)
+	if got := <-granted; got != 2 {
+		t.Fatalf("task %d started second, want 2", got)
+	}
+}
+
+func TestSchedulerAcquireWithoutCandidates(t *testing.T) {
+	if got := NewScheduler(1).Acquire(nil); got != nil {
+		t.Errorf("got a slot on %s without candidates", got.Uuid)
+	}
+}
+
+func (s *SchedulerImpl) queuedTasks() []*pendingTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*pendingTask{}, s.queue...)
+}
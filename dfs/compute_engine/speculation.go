@@ -0,0 +1,48 @@
+package compute_engine
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// a map task runs a speculative duplicate once it runs this many times longer than the median finished task
+const SPECULATIVE_SLOWDOWN = 2
+
+// and at least this long, so that short tasks are never duplicated
+const SPECULATION_MIN_RUNTIME = 10 * time.Second
+const SPECULATION_CHECK_INTERVAL = time.Second
+
+/**
+* Running times of the finished map tasks of a phase, used to spot the ones
+* running well behind (e.g. on a slow disk). Tasks are only judged once half
+* of them have finished, so the median means something.
+ */
+type ProgressTracker struct {
+	mu       sync.Mutex
+	tasks    int
+	finished []time.Duration
+}
+
+func NewProgressTracker(tasks int) *ProgressTracker {
+	return &ProgressTracker{tasks: tasks}
+}
+
+func (t *ProgressTracker) Finish(runtime time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finished = append(t.finished, runtime)
+}
+
+/** Whether a task running for runtime so far is a straggler, and the median it was compared with */
+func (t *ProgressTracker) IsStraggler(runtime time.Duration) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.finished) == 0 || 2*len(t.finished) < t.tasks {
+		return false, 0
+	}
+	sorted := append([]time.Duration{}, t.finished...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+	return runtime >= SPECULATION_MIN_RUNTIME && runtime > SPECULATIVE_SLOWDOWN*median, median
+}
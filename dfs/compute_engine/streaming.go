@@ -0,0 +1,91 @@
+package compute_engine
+
+import (
+	extsort "adfs/external_sort"
+	"adfs/helpers"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const STREAMING_SHELL = "/bin/sh"
+
+/**
+* Streaming mappers get the chunk lines on stdin and print key\tvalue lines.
+* The key runs up to the first tab; a line without one is all key.
+ */
+func (ce *ComputeEngineImpl) RunStreamingMapper(command string, input io.Reader) error {
+	cmd, stderr := streamingCommand(command)
+	cmd.Stdin = input
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	logrus.WithFields(logrus.Fields{"Command": command}).Info("Running streaming mapper")
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanner := extsort.NewScanner(stdout)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "\t")
+		ce.context.Write([]byte(key), []byte(value))
+	}
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		cmd.Process.Kill()
+	}
+	ce.context.ClearKeysTracker()
+	if err := cmd.Wait(); err != nil && scanErr == nil {
+		return withStderr(err, stderr)
+	}
+	if scanErr != nil {
+		return scanErr
+	}
+
+	for filename := range ce.context.GetMapperOutputFiles() {
+		logrus.WithFields(logrus.Fields{"Filename": filename}).Info("External Sort")
+		if err := extsort.NewExtSort().Sort(filename); err != nil {
+			logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("External Sort error")
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+* Streaming reducers get every key\tvalue pair of their partition on stdin,
+* sorted by key, and whatever they print is the job output.
+ */
+func (ce *ComputeEngineImpl) RunStreamingReducer(command, dataPath string) error {
+	file, err := os.Open(dataPath)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"File": dataPath}).Error("Error opening file in reducer")
+		return err
+	}
+	defer file.Close()
+	helpers.CreatePaths(ce.context.GetStorageDir())
+	output, err := os.OpenFile(ce.context.GetComputeOutputFilename(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	cmd, stderr := streamingCommand(command)
+	cmd.Stdin = file
+	cmd.Stdout = output
+	logrus.WithFields(logrus.Fields{"Command": command}).Info("Running streaming reducer")
+	if err := cmd.Run(); err != nil {
+		return withStderr(err, stderr)
+	}
+	return nil
+}
+
+func streamingCommand(command string) (*exec.Cmd, *bytes.Buffer) {
+	cmd := exec.Command(STREAMING_SHELL, "-c", command)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	return cmd, stderr
+}
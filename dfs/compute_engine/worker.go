@@ -0,0 +1,122 @@
+package compute_engine
+
+import (
+	"adfs/mapreduce"
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// plugins built for the one-exec-per-record mode exit or stay silent instead
+const WORKER_HANDSHAKE_TIMEOUT_S = 5
+
+var errNotWorker = errors.New("plugin does not speak the worker protocol")
+
+/** A plugin process started once for a whole task, exchanging records over stdin/stdout */
+type pluginWorker struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr *bytes.Buffer
+}
+
+/** Starts the plugin in worker mode. Returns errNotWorker if it doesn't answer the handshake */
+func startWorker(pluginPath, job string) (*pluginWorker, error) {
+	cmd := exec.Command(pluginPath, mapreduce.WORKER, job)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	worker := &pluginWorker{cmd, stdin, bufio.NewReader(stdout), stderr}
+
+	handshake := make(chan bool, 1)
+	go func() {
+		buf := make([]byte, len(mapreduce.HANDSHAKE))
+		_, err := io.ReadFull(worker.stdout, buf)
+		handshake <- err == nil && string(buf) == mapreduce.HANDSHAKE
+	}()
+	select {
+	case ok := <-handshake:
+		if ok {
+			return worker, nil
+		}
+	case <-time.After(WORKER_HANDSHAKE_TIMEOUT_S * time.Second):
+	}
+	worker.kill()
+	return nil, errNotWorker
+}
+
+/**
+* Streams every input record to the plugin while handing its output records
+* to output. Input is written from its own goroutine so a plugin blocked on a
+* full stdout never deadlocks against us blocked on a full stdin.
+ */
+func (w *pluginWorker) run(
+	input func(send func(key, value []byte) error) error,
+	output func(key, value []byte),
+) error {
+	inputErr := make(chan error, 1)
+	go func() {
+		writer := bufio.NewWriter(w.stdin)
+		err := input(func(key, value []byte) error {
+			return mapreduce.WriteRecord(writer, key, value)
+		})
+		if err == nil {
+			err = writer.Flush()
+		}
+		w.stdin.Close()
+		inputErr <- err
+	}()
+	var readErr error
+	for {
+		key, value, err := mapreduce.ReadRecord(w.stdout)
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+		output(key, value)
+	}
+	if readErr != nil {
+		w.kill()
+		<-inputErr
+		return readErr
+	}
+	err := <-inputErr
+	if waitErr := w.cmd.Wait(); waitErr != nil {
+		return withStderr(waitErr, w.stderr)
+	}
+	return err
+}
+
+func (w *pluginWorker) kill() {
+	if err := w.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Error killing plugin worker")
+	}
+	w.cmd.Wait()
+}
+
+/** Adds whatever the plugin printed to stderr */
+func withStderr(err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return errors.New(err.Error() + ": " + msg)
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package compute_engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/** Records what the mapper wrote; everything else is left to the embedded nil Context */
+type recordingContext struct {
+	Context
+	written []string
+}
+
+func (c *recordingContext) Write(key, value []byte) {
+	c.written = append(c.written, string(key)+"="+string(value))
+}
+
+func (c *recordingContext) GetMapperOutputFiles() map[string]int {
+	return map[string]int{}
+}
+
+func (c *recordingContext) ClearKeysTracker() {}
+
+/** A plugin for the one-exec-per-record mode: started as a worker it exits without a handshake */
+func writeArgvPlugin(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "plugin")
+	script := "#!/bin/sh\n" +
+		"[ \"$1\" = WORKER ] && exit 0\n" +
+		"for word in $3; do printf '%s\\t1\\n' \"$word\"; done\n"
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestStartWorkerWithoutHandshake(t *testing.T) {
+	worker, err := startWorker(writeArgvPlugin(t), MAP)
+	if err != errNotWorker {
+		t.Errorf("got %v, want errNotWorker", err)
+	}
+	if worker != nil {
+		t.Error("got a worker for a plugin that never answered the handshake")
+	}
+}
+
+func TestStartWorkerMissingPlugin(t *testing.T) {
+	_, err := startWorker(filepath.Join(t.TempDir(), "missing"), MAP)
+	if err == nil || err == errNotWorker {
+		t.Errorf("got %v, want the error starting the plugin", err)
+	}
+}
+
+func TestRunMapperFallsBackToOneExecPerLine(t *testing.T) {
+	context := &recordingContext{}
+	ce := NewComputeEngine(context)
+	if err := ce.RunMapper(writeArgvPlugin(t), strings.NewReader("a b\nc\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(context.written, " "), "a=1 b=1 c=1"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,301 @@
+package controller
+
+import (
+	"adfs/common"
+	"adfs/helpers"
+	m "adfs/messages"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const BALANCER_DELAY_S = 30
+
+// nodes within this fraction of the cluster mean utilization are considered balanced
+const BALANCER_THRESHOLD = 0.1
+const DEFAULT_BALANCER_BANDWIDTH int64 = 64 << 20 // bytes moved per round
+
+// a move not confirmed by the target's heartbeats after this many rounds is dropped
+const MAX_MOVE_ROUNDS = 3
+
+/**
+* Evens out disk utilization (used over capacity, as reported in heartbeats)
+* across storage nodes. Every round it plans chunk moves from over- to
+* under-utilized nodes, bounded by a bandwidth budget, and asks
+* the source node to copy the chunk to the target. Once the target reports
+* the copy, ownership is moved in the FileIndex and the source copy deleted.
+ */
+type Balancer interface {
+	Start()
+	Stop()
+}
+
+type BalancerImpl struct {
+	zookeeper Zookeeper
+	fileIndex FileIndex
+	safeMode  SafeMode
+	bandwidth int64
+	scheduler *time.Ticker
+	quit      chan bool
+	inFlight  map[string]*ChunkMove // [chunkName] move waiting for the target's copy
+}
+
+type ChunkMove struct {
+	filename  string
+	chunkName string
+	size      int64
+	source    *m.Node
+	target    *m.Node
+	rounds    int
+}
+
+/** bandwidth is the number of bytes moved per round; 0 disables the balancer */
+func NewBalancer(zookeeper Zookeeper, fileIndex FileIndex, safeMode SafeMode, bandwidth int64) Balancer {
+	return &BalancerImpl{
+		zookeeper: zookeeper,
+		fileIndex: fileIndex,
+		safeMode:  safeMode,
+		bandwidth: bandwidth,
+		quit:      make(chan bool),
+		inFlight:  make(map[string]*ChunkMove),
+	}
+}
+
+func (b *BalancerImpl) Start() {
+	if b.bandwidth <= 0 {
+		logrus.Info("Balancer disabled")
+		return
+	}
+	if b.scheduler != nil {
+		return // already running
+	}
+	b.scheduler = time.NewTicker(BALANCER_DELAY_S * time.Second)
+	go b.worker()
+}
+
+func (b *BalancerImpl) Stop() {
+	if b.scheduler == nil {
+		return
+	}
+	b.scheduler.Stop()
+	b.quit <- true
+	b.scheduler = nil
+}
+
+func (b *BalancerImpl) worker() {
+	for {
+		select {
+		case <-b.quit:
+			return
+		case <-b.scheduler.C:
+			if b.safeMode.IsOn() {
+				continue
+			}
+			b.completeMoves()
+			b.balance()
+		}
+	}
+}
+
+/** Finishes the moves whose copy already shows up in the FileIndex */
+func (b *BalancerImpl) completeMoves() {
+	for chunkName, move := range b.inFlight {
+		chunk := b.getChunk(move.filename, chunkName)
+		if chunk == nil {
+			delete(b.inFlight, chunkName) // file removed meanwhile
+			continue
+		}
+		if _, copied := chunk.StorageNodes[move.target.Uuid]; !copied {
+			move.rounds++
+			if move.rounds > MAX_MOVE_ROUNDS {
+				logrus.WithFields(logrus.Fields{"Chunk": chunkName, "Target": move.target.Uuid}).Warn("Chunk move timed out")
+				delete(b.inFlight, chunkName)
+			}
+			continue
+		}
+		// index first, so nobody is sent to the source once its copy is gone
+		b.fileIndex.MoveReplica(move.filename, chunkName, move.source.Uuid, move.target)
+		removeChunk(move.source, chunkName)
+		delete(b.inFlight, chunkName)
+		logrus.WithFields(logrus.Fields{
+			"Chunk":  chunkName,
+			"Source": move.source.Uuid,
+			"Target": move.target.Uuid,
+		}).Info("Chunk moved")
+	}
+}
+
+func (b *BalancerImpl) getChunk(filename, chunkName string) *m.Chunk {
+	file, err := b.fileIndex.Get(filename)
+	if err != nil {
+		return nil
+	}
+	for _, chunk := range file.Chunks {
+		if chunk.ChunkName == chunkName {
+			return chunk
+		}
+	}
+	return nil
+}
+
+/** Bytes used and capacity of a node, from its last heartbeat */
+type NodeUsage struct {
+	used     int64
+	capacity int64
+}
+
+func (u *NodeUsage) utilization() float64 {
+	return float64(u.used) / float64(u.capacity)
+}
+
+func (b *BalancerImpl) balance() {
+	nodes := make(map[string]*m.Node)
+	usage := make(map[string]*NodeUsage)
+	for _, zn := range b.zookeeper.GetPlacementNodes() {
+		if zn.Stats == nil || zn.Stats.Capacity <= 0 {
+			continue // no disk usage reported yet
+		}
+		nodes[zn.Uuid] = &m.Node{Uuid: zn.Uuid, Hostname: zn.Hostname, Port: int32(zn.Port)}
+		usage[zn.Uuid] = &NodeUsage{used: zn.Stats.Used, capacity: zn.Stats.Capacity}
+	}
+	if len(nodes) < 2 {
+		return
+	}
+	b.addInFlight(usage)
+	var used, capacity int64 = 0, 0
+	for _, u := range usage {
+		used += u.used
+		capacity += u.capacity
+	}
+	mean := float64(used) / float64(capacity)
+	limit := mean + mean*BALANCER_THRESHOLD
+
+	sources := make([]string, 0)
+	for uuid, u := range usage {
+		if u.utilization() > limit {
+			sources = append(sources, uuid)
+		}
+	}
+	if len(sources) == 0 {
+		return
+	}
+	sort.Slice(sources, func(i, j int) bool { return usage[sources[i]].utilization() > usage[sources[j]].utilization() })
+	nodeChunks := b.getNodeChunks(usage)
+
+	budget := b.bandwidth
+	for _, source := range sources {
+		for _, chunk := range nodeChunks[source] {
+			if usage[source].utilization() <= mean || budget < chunk.Size {
+				break
+			}
+			if _, moving := b.inFlight[chunk.ChunkName]; moving {
+				continue
+			}
+			target := b.getTarget(chunk, usage, nodes, mean)
+			if target == nil {
+				continue
+			}
+			if err := b.move(chunk, nodes[source], target); err != nil {
+				logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName, "Error": err.Error()}).Error("Could not move chunk")
+				continue
+			}
+			usage[source].used -= chunk.Size
+			usage[target.Uuid].used += chunk.Size
+			budget -= chunk.Size
+		}
+	}
+	if budget < b.bandwidth {
+		logrus.WithFields(logrus.Fields{"Bytes": b.bandwidth - budget, "Mean": mean}).Info("Balancer round planned")
+	}
+}
+
+/** Heartbeats don't show moves still under way yet: count them as done */
+func (b *BalancerImpl) addInFlight(usage map[string]*NodeUsage) {
+	for _, move := range b.inFlight {
+		if u, present := usage[move.source.Uuid]; present {
+			u.used -= move.size
+		}
+		if u, present := usage[move.target.Uuid]; present {
+			u.used += move.size
+		}
+	}
+}
+
+/** Chunks each node holds, from a snapshot of the FileIndex */
+func (b *BalancerImpl) getNodeChunks(usage map[string]*NodeUsage) map[string][]*m.Chunk {
+	nodeChunks := make(map[string][]*m.Chunk)
+	for _, file := range b.fileIndex.Ls() {
+		for _, chunk := range file.chunks {
+			for uuid := range chunk.StorageNodes {
+				if _, present := usage[uuid]; present {
+					nodeChunks[uuid] = append(nodeChunks[uuid], chunk)
+				}
+			}
+		}
+	}
+	return nodeChunks
+}
+
+/**
+* Least utilized node that can take the chunk without going over the mean. It
+* must not hold the chunk, nor (erasure coded files) another chunk of its stripe.
+ */
+func (b *BalancerImpl) getTarget(chunk *m.Chunk, usage map[string]*NodeUsage, nodes map[string]*m.Node, mean float64) *m.Node {
+	excluded := make(map[string]bool)
+	for uuid := range chunk.StorageNodes {
+		excluded[uuid] = true
+	}
+	if chunk.DataShards > 0 {
+		for _, stripeChunk := range b.fileIndex.GetStripe(chunk.FileName, chunk.Stripe) {
+			for uuid := range stripeChunk.StorageNodes {
+				excluded[uuid] = true
+			}
+		}
+	}
+	var target *m.Node
+	for uuid, node := range nodes {
+		u := usage[uuid]
+		if excluded[uuid] || float64(u.used+chunk.Size)/float64(u.capacity) > mean {
+			continue
+		}
+		if target == nil || u.utilization() < usage[target.Uuid].utilization() {
+			target = node
+		}
+	}
+	return target
+}
+
+/** Only a move handed to the source node counts as in flight */
+func (b *BalancerImpl) move(chunk *m.Chunk, source, target *m.Node) error {
+	msgHandler, err := m.GetMessageHandlerFor(helpers.GetAddr(source.Hostname, int(source.Port)))
+	if err != nil {
+		return err
+	}
+	defer msgHandler.Close()
+	replication := chunk.Replication
+	if replication <= 0 {
+		replication = common.DEFAULT_REPLICATION
+	}
+	if err := msgHandler.SendReplicateRequest(chunk.ChunkName, replication, []*m.Node{target}); err != nil {
+		return err
+	}
+	b.inFlight[chunk.ChunkName] = &ChunkMove{
+		filename:  chunk.FileName,
+		chunkName: chunk.ChunkName,
+		size:      chunk.Size,
+		source:    source,
+		target:    target,
+	}
+	return nil
+}
+
+func removeChunk(sn *m.Node, chunkName string) {
+	msgHandler, err := m.GetMessageHandlerFor(helpers.GetAddr(sn.Hostname, int(sn.Port)))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"Chunk": chunkName, "Error": err.Error()}).Error("Could not reach chunk owner")
+		return
+	}
+	msgHandler.SendChunkRemoveRequest(chunkName)
+	msgHandler.Close()
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"adfs/common"
+	ec "adfs/erasure_coding"
 	"adfs/helpers"
 	m "adfs/messages"
 	s "adfs/server"
@@ -21,12 +22,18 @@ type ControllerImpl struct {
 	server            s.Server
 	zookeeper         Zookeeper
 	fileIndex         FileIndex
+	balancer          Balancer
+	election          Election
+	safeMode          SafeMode
 	computeEngineAddr string
 }
 
 type ControllerConfig struct {
 	Zookeeper
 	FileIndex
+	Balancer
+	Election
+	SafeMode
 	s.Server
 	computeEngineAddr string
 }
@@ -35,6 +42,9 @@ func NewController(config ControllerConfig) Controller {
 	return &ControllerImpl{
 		zookeeper: config.Zookeeper,
 		fileIndex: config.FileIndex,
+		balancer:  config.Balancer,
+		election:  config.Election,
+		safeMode:  config.SafeMode,
 		server:    config.Server,
 	}
 }
@@ -45,6 +55,10 @@ func (c *ControllerImpl) Start() {
 	logrus.Info("Zookeeper running")
 	c.fileIndex.Start()
 	logrus.Info("File Index running")
+	c.safeMode.Start()
+	c.election.AddListenerOnApply(c.applyEdit)
+	c.election.AddListenerOnLeaderChange(c.onLeaderChange)
+	c.election.Start()
 	logrus.WithFields(logrus.Fields{
 		"PORT": c.server.GetPort(),
 	}).Info("Controller listening")
@@ -52,17 +66,29 @@ func (c *ControllerImpl) Start() {
 }
 
 func (c *ControllerImpl) Stop() {
+	c.election.Stop()
+	c.safeMode.Stop()
 	c.zookeeper.Stop()
 	c.fileIndex.Stop()
+	c.balancer.Stop()
 	c.server.Stop()
 }
 
 func (c *ControllerImpl) handleConnection(messageHandler *m.MessageHandler) {
 	wrapper, _ := messageHandler.Receive()
+	if c.handleElectionMessage(messageHandler, wrapper) {
+		c.handleCloseConnection(messageHandler)
+		return
+	}
 	switch msg := wrapper.Msg.(type) {
 	case *m.Wrapper_RegistrationMessage:
-		c.handleRegistration(msg.RegistrationMessage.Node)
+		c.handleRegistration(msg.RegistrationMessage.Node, msg.RegistrationMessage.Chunks)
 	case *m.Wrapper_HeartbeatMessage:
+		if _, known := c.zookeeper.GetNode(msg.HeartbeatMessage.StorageNode.Uuid); !known {
+			// e.g. the controller restarted; the node must re-register with its block report
+			messageHandler.SendFailAck(common.UNKNOWN_NODE_ERROR_MSG)
+			break
+		}
 		c.handleHeartbeat(msg.HeartbeatMessage)
 		c.sendOnlineStorageNodes(messageHandler)
 	case *m.Wrapper_ActionRequestMessage:
@@ -77,27 +103,76 @@ func (c *ControllerImpl) handleConnection(messageHandler *m.MessageHandler) {
 	c.handleCloseConnection(messageHandler)
 }
 
-func (c *ControllerImpl) handleRegistration(node *m.Node) {
+func (c *ControllerImpl) handleRegistration(node *m.Node, chunks []*m.Chunk) {
 	if node.Uuid == common.COMPUTE_ENGINE {
-		c.computeEngineAddr = helpers.GetAddr(node.Hostname, int(node.Port))
+		// the compute engine registers on every check; only moves are news.
+		// Applying the edit sets computeEngineAddr, so a failed one is retried next time
+		addr := helpers.GetAddr(node.Hostname, int(node.Port))
+		if addr == c.computeEngineAddr {
+			return
+		}
 		logrus.WithFields(logrus.Fields{
-			"computeEngineAddr": strings.TrimSpace(c.computeEngineAddr),
+			"computeEngineAddr": strings.TrimSpace(addr),
 		}).Info("Compute Engine Registration")
+		if err := c.election.Append(&m.EditLogEntry{ComputeEngineAddr: addr}); err != nil {
+			logrus.WithFields(logrus.Fields{"error": err.Error()}).Warn("Compute Engine Registration not replicated")
+		}
 		return
 	}
+	rejoining := c.zookeeper.KnowsNode(node.Uuid)
 	c.zookeeper.RegisterNode(node)
+	c.dropRefused(node, c.fileIndex.SyncNode(node, chunks))
+	if rejoining && !c.safeMode.IsOn() {
+		logrus.WithFields(logrus.Fields{
+			"UUID":   node.Uuid,
+			"Chunks": len(chunks),
+		}).Info("Storage Node rejoined")
+		go c.reconcileRejoinedChunks(chunks)
+	}
+}
+
+/**
+* While a node was away its chunks may have been re-replicated elsewhere.
+* Now that its copies are back, bring those files back to their replication.
+ */
+func (c *ControllerImpl) reconcileRejoinedChunks(chunks []*m.Chunk) {
+	filenames := make(map[string]bool)
+	for _, chunk := range chunks {
+		filenames[chunk.FileName] = true
+	}
+	for filename := range filenames {
+		file, err := c.fileIndex.Get(filename)
+		if err != nil || ec.IsErasureCoded(file.StoragePolicy) {
+			continue
+		}
+		c.reconcileReplicas(file)
+	}
 }
 
 func (c *ControllerImpl) handleHeartbeat(heartbeat *m.Heartbeat) {
 	chunks := heartbeat.Chunks
 	uuid := heartbeat.StorageNode.Uuid
 	c.zookeeper.Heartbeat(uuid, heartbeat.Stats)
-	c.fileIndex.PutAll(heartbeat.StorageNode, chunks)
+	// heartbeats carry every chunk the node holds, so replicas lost on a live node show up too
+	c.dropRefused(heartbeat.StorageNode, c.fileIndex.SyncNode(heartbeat.StorageNode, chunks))
+}
+
+/** Chunks the index refused for going over a quota are deleted; standby controllers leave that to the leader */
+func (c *ControllerImpl) dropRefused(sn *m.Node, refused []*m.Chunk) {
+	if len(refused) == 0 || !c.election.IsLeader() {
+		return
+	}
+	go func() {
+		for _, chunk := range refused {
+			c.removeReplica(sn, chunk)
+		}
+	}()
 }
 
 func (c *ControllerImpl) sendOnlineStorageNodes(messageHandler *m.MessageHandler) {
 	nodes := make([]*m.Node, 0)
-	for _, n := range c.zookeeper.GetNodes() {
+	// storage nodes replicate new chunks among these, so draining nodes are left out
+	for _, n := range c.zookeeper.GetPlacementNodes() {
 		nodes = append(nodes, &m.Node{
 			Uuid:     n.Uuid,
 			Hostname: n.Hostname,
@@ -125,6 +200,10 @@ func (c *ControllerImpl) handleActionRequest(
 		fields["hasPlugin"] = len(actionRequest.Plugin.Plugin) > 0
 	}
 	logrus.WithFields(fields).Info("Received Action Request")
+	if c.safeMode.IsOn() && isNamespaceChange(actionRequest.Type) {
+		messageHandler.SendFailAck(common.SAFE_MODE_ERROR_MSG)
+		return
+	}
 	switch *actionRequest.Type.Enum() {
 	case m.ActionType_LS:
 		c.handleLS(messageHandler)
@@ -138,6 +217,44 @@ func (c *ControllerImpl) handleActionRequest(
 		c.handleCompute(messageHandler, actionRequest)
 	case m.ActionType_CLUSTER_STATS:
 		c.handleClusterStats(messageHandler)
+	case m.ActionType_SETREP:
+		c.handleSetRep(messageHandler, actionRequest)
+	case m.ActionType_SETPOLICY:
+		c.handleSetPolicy(messageHandler, actionRequest)
+	case m.ActionType_DECOMMISSION:
+		c.handleDecommission(messageHandler, actionRequest)
+	case m.ActionType_SAFEMODE:
+		c.handleSafeMode(messageHandler, actionRequest)
+	case m.ActionType_FSCK:
+		c.handleFsck(messageHandler, actionRequest)
+	case m.ActionType_SETQUOTA:
+		c.handleSetQuota(messageHandler, actionRequest)
+	}
+}
+
+/** Requests refused while in safe mode */
+func isNamespaceChange(actionType m.ActionType) bool {
+	switch actionType {
+	case m.ActionType_PUT, m.ActionType_RM, m.ActionType_SETREP, m.ActionType_SETPOLICY,
+		m.ActionType_SETQUOTA, m.ActionType_DECOMMISSION, m.ActionType_COMPUTE:
+		return true
+	}
+	return false
+}
+
+func (c *ControllerImpl) handleSafeMode(
+	messageHandler *m.MessageHandler,
+	actionRequest *m.ActionRequest,
+) {
+	switch actionRequest.SafeMode {
+	case common.SAFE_MODE_ENTER:
+		c.safeMode.Enter(true)
+		messageHandler.SendSuccessAck()
+	case common.SAFE_MODE_LEAVE:
+		c.safeMode.Leave()
+		messageHandler.SendSuccessAck()
+	default:
+		messageHandler.SendFailAck("Safe mode action must be " + common.SAFE_MODE_ENTER + " or " + common.SAFE_MODE_LEAVE)
 	}
 }
 
@@ -155,7 +272,7 @@ func (c *ControllerImpl) handleLS(messageHandler *m.MessageHandler) {
 			Chunks:  chunks,
 		})
 	}
-	messageHandler.SendFilesMetadata(fileIndex)
+	messageHandler.SendFilesMetadata(fileIndex, c.fileIndex.GetQuotas())
 }
 
 func (c *ControllerImpl) handleGET(
@@ -176,15 +293,30 @@ func (c *ControllerImpl) handlePUT(
 	actionRequest *m.ActionRequest,
 ) {
 	filename := actionRequest.FileName
-	nodes := c.zookeeper.GetNodes()
+	replication := actionRequest.Replication
+	policy := actionRequest.StoragePolicy
+	if policy == "" {
+		policy = c.fileIndex.GetPolicy(filename)
+	}
+	nodes := c.zookeeper.GetPlacementNodes()
 	if len(nodes) == 0 {
-		errorMsg := "Currently there are not Storage Nodes online"
-		messageHandler.SendFailAck(errorMsg)
-	} else if c.fileIndex.FileExists(filename) {
-		errorMsg := "FileName already exists. Please choose a different name."
+		errorMsg := "Currently there are not Storage Nodes online with free space"
 		messageHandler.SendFailAck(errorMsg)
+	} else if err := validateReplication(replication); err != nil {
+		messageHandler.SendFailAck(err.Error())
+	} else if err := ec.ValidatePolicy(policy); err != nil {
+		messageHandler.SendFailAck(err.Error())
+	} else if err := validatePlacement(policy, len(nodes)); err != nil {
+		messageHandler.SendFailAck(err.Error())
+	} else if err := c.fileIndex.Reserve(filename, replication, uploadBytes(actionRequest.FileSize, replication, policy)); err != nil {
+		messageHandler.SendFailAck(err.Error())
 	} else {
-		c.fileIndex.ReserveSlot(filename)
+		// followers reserve the upload when applying the edit
+		if err := c.logEdit(actionRequest); err != nil {
+			c.fileIndex.Rm(filename)
+			messageHandler.SendFailAck(err.Error())
+			return
+		}
 		// TODO (TLDR): send only a small number of available Storage Nodes using better algo
 		// rn it sends all nodes as available for uploading files.
 		// Meaning client will upload a chunk to each of the nodes in
@@ -198,7 +330,7 @@ func (c *ControllerImpl) handlePUT(
 				Stats:    node.Stats,
 			})
 		}
-		messageHandler.SendNodes(n)
+		messageHandler.SendUploadNodes(n, policy)
 	}
 }
 
@@ -208,11 +340,19 @@ func (c *ControllerImpl) handleRM(
 ) {
 	filename := actionRequest.FileName
 	file, err := c.fileIndex.Get(filename)
-	if err != nil {
+	if err != nil && !c.fileIndex.IsPending(filename) {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	if err := c.logEdit(actionRequest); err != nil {
 		messageHandler.SendFailAck(err.Error())
 		return
 	}
-	c.fileIndex.Rm(filename)
+	if file == nil {
+		// an upload whose chunks are not reported yet only loses its reservation
+		messageHandler.SendSuccessAck()
+		return
+	}
 	go func() {
 		for _, chunk := range file.Chunks {
 			for _, sn := range chunk.StorageNodes {
@@ -227,6 +367,65 @@ func (c *ControllerImpl) handleRM(
 	messageHandler.SendSuccessAck()
 }
 
+func (c *ControllerImpl) handleSetRep(
+	messageHandler *m.MessageHandler,
+	actionRequest *m.ActionRequest,
+) {
+	filename := actionRequest.FileName
+	replication := actionRequest.Replication
+	if replication == 0 {
+		messageHandler.SendFailAck("Replication factor must be at least 1")
+		return
+	}
+	if err := validateReplication(replication); err != nil {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	file, err := c.fileIndex.Get(filename)
+	if err != nil {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	if ec.IsErasureCoded(file.StoragePolicy) {
+		messageHandler.SendFailAck(filename + " is erasure coded (" + file.StoragePolicy + "); it has no replicas")
+		return
+	}
+	// applying the edit sets the new factor
+	if err := c.logEdit(actionRequest); err != nil {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	file, err = c.fileIndex.Get(filename)
+	if err != nil {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	go c.reconcileReplicas(file)
+	messageHandler.SendSuccessAck()
+}
+
+/** Policy applies to files uploaded to dirname from now on; existing files are kept as they are */
+func (c *ControllerImpl) handleSetPolicy(
+	messageHandler *m.MessageHandler,
+	actionRequest *m.ActionRequest,
+) {
+	dirname := strings.TrimSuffix(actionRequest.FileName, "/")
+	policy := actionRequest.StoragePolicy
+	if policy == "" {
+		policy = ec.REPLICATED_POLICY
+	}
+	if err := ec.ValidatePolicy(policy); err != nil {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	if err := c.logEdit(&m.ActionRequest{Type: m.ActionType_SETPOLICY, FileName: dirname, StoragePolicy: policy}); err != nil {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	logrus.WithFields(logrus.Fields{"Dirname": dirname, "Policy": policy}).Info("Directory storage policy updated")
+	messageHandler.SendSuccessAck()
+}
+
 func (c *ControllerImpl) handleCompute(
 	clientConn *m.MessageHandler,
 	actionRequest *m.ActionRequest,
@@ -241,11 +440,15 @@ func (c *ControllerImpl) handleCompute(
 	targetFilename := actionRequest.FileName
 	plugin := actionRequest.Plugin
 	outputFilename := actionRequest.OutputFilename
+	// reducer outputs are checked again, with their size, when uploaded
+	if err := c.fileIndex.CheckQuota(outputFilename, 0); err != nil {
+		clientConn.SendFailAck(err.Error())
+		return
+	}
 	computeEngineConn.SendComputeRequest(
 		targetFilename,
-		plugin.Name,
+		plugin,
 		outputFilename,
-		plugin.Plugin,
 		m.ComputeType_MAP,
 		nil,
 	)
@@ -275,14 +478,16 @@ func (c *ControllerImpl) handleClusterStats(messageHandler *m.MessageHandler) {
 	znodes := c.zookeeper.GetNodes()
 	for _, zn := range znodes {
 		storageNodes = append(storageNodes, &m.Node{
-			Uuid:     zn.Uuid,
-			Hostname: zn.Hostname,
-			Port:     int32(zn.Port),
-			Stats:    zn.Stats,
+			Uuid:          zn.Uuid,
+			Hostname:      zn.Hostname,
+			Port:          int32(zn.Port),
+			Stats:         zn.Stats,
+			State:         zn.State,
+			ChunksToDrain: zn.ChunksToDrain,
 		})
 	}
 
-	messageHandler.SendNodes(storageNodes)
+	messageHandler.SendClusterStats(storageNodes, c.safeMode.GetStatus())
 }
 
 func (c *ControllerImpl) handleCloseConnection(messageHandler *m.MessageHandler) {
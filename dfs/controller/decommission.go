@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"adfs/common"
+	ec "adfs/erasure_coding"
+	"adfs/helpers"
+	m "adfs/messages"
+	"adfs/storageNode"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gives heartbeats time to report the copies requested on the previous round
+const DRAIN_CHECK_DELAY_S = storageNode.HEARTBEAT_DELAY_S * 2
+
+/**
+* Marks the node as draining and starts copying its chunks elsewhere.
+* Progress shows up in CLUSTER_STATS until the node is decommissioned.
+ */
+func (c *ControllerImpl) handleDecommission(
+	messageHandler *m.MessageHandler,
+	actionRequest *m.ActionRequest,
+) {
+	zn := c.findNode(actionRequest.FileName)
+	if zn == nil {
+		messageHandler.SendFailAck("Storage Node " + actionRequest.FileName + " is not online")
+		return
+	}
+	if zn.State != common.NODE_ONLINE {
+		messageHandler.SendSuccessAck() // already draining
+		return
+	}
+	c.zookeeper.UpdateDrainState(zn.Uuid, common.NODE_DRAINING, 0)
+	go c.drainNode(zn.Uuid)
+	messageHandler.SendSuccessAck()
+}
+
+/** Looks a node up by uuid or hostname:port */
+func (c *ControllerImpl) findNode(node string) *ZNode {
+	if zn, present := c.zookeeper.GetNode(node); present {
+		return zn
+	}
+	for _, zn := range c.zookeeper.GetNodes() {
+		if helpers.GetAddr(zn.Hostname, zn.Port) == node {
+			return zn
+		}
+	}
+	return nil
+}
+
+func (c *ControllerImpl) drainNode(uuid string) {
+	ticker := time.NewTicker(DRAIN_CHECK_DELAY_S * time.Second)
+	defer ticker.Stop()
+	for {
+		zn, present := c.zookeeper.GetNode(uuid)
+		if !present {
+			logrus.WithFields(logrus.Fields{"UUID": uuid}).Error("Storage Node went down while draining")
+			return
+		}
+		if c.safeMode.IsOn() {
+			<-ticker.C
+			continue
+		}
+		chunksToDrain := c.drainRound(zn)
+		if chunksToDrain == 0 {
+			c.zookeeper.UpdateDrainState(uuid, common.NODE_DECOMMISSIONED, 0)
+			logrus.WithFields(logrus.Fields{"UUID": uuid}).Info("Storage Node drained; safe to shut down")
+			return
+		}
+		c.zookeeper.UpdateDrainState(uuid, common.NODE_DRAINING, chunksToDrain)
+		<-ticker.C
+	}
+}
+
+/**
+* Requests copies of every chunk of the node that doesn't have enough of them
+* on nodes that stay in service. Returns how many chunks are still short.
+ */
+func (c *ControllerImpl) drainRound(zn *ZNode) int64 {
+	source := &m.Node{Uuid: zn.Uuid, Hostname: zn.Hostname, Port: int32(zn.Port)}
+	staying := make(map[string]bool)
+	for _, node := range c.zookeeper.GetPlacementNodes() {
+		staying[node.Uuid] = true
+	}
+	var chunksToDrain int64 = 0
+	for _, file := range c.fileIndex.Ls() {
+		replication := file.replication
+		if ec.IsErasureCoded(file.policy) {
+			replication = 1 // parity provides the redundancy
+		}
+		for _, chunk := range file.chunks {
+			if _, present := chunk.StorageNodes[zn.Uuid]; !present {
+				continue
+			}
+			copies := 0
+			for uuid := range chunk.StorageNodes {
+				if staying[uuid] {
+					copies++
+				}
+			}
+			if copies >= int(replication) {
+				continue
+			}
+			chunksToDrain++
+			targets := c.getReplicaTargets(chunk, int(replication)-copies)
+			c.replicateChunk(source, chunk.ChunkName, replication, targets)
+		}
+	}
+	return chunksToDrain
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	m "adfs/messages"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// next to the edit log: "<term>\n<voted for>\n"
+const TERM_FILE_SUFFIX = ".term"
+
+/**
+* Ordered namespace changes shared by all controllers. Entries are indexed
+* from 1; index 0 stands for the empty log. Persisted as length-prefixed
+* protos when a file is given, along with the election term and vote of this
+* controller.
+ */
+type EditLog interface {
+	Append(entries ...*m.EditLogEntry) error
+	TruncateAfter(index int64) error
+	Get(index int64) *m.EditLogEntry
+	From(index int64) []*m.EditLogEntry
+	LastIndex() int64
+	LastTerm() int64
+	SaveVote(term int64, votedFor string) error
+	LoadVote() (term int64, votedFor string)
+}
+
+type EditLogImpl struct {
+	filename string
+	entries  []*m.EditLogEntry
+	term     int64
+	votedFor string
+	mutex    sync.RWMutex
+}
+
+/** Empty filename keeps the log in memory only */
+func NewEditLog(filename string) (EditLog, error) {
+	editLog := &EditLogImpl{
+		filename: filename,
+		entries:  make([]*m.EditLogEntry, 0),
+	}
+	if filename == "" {
+		return editLog, nil
+	}
+	entries, err := readEntries(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	editLog.entries = entries
+	if editLog.term, editLog.votedFor, err = readVote(filename + TERM_FILE_SUFFIX); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return editLog, nil
+}
+
+func (l *EditLogImpl) Append(entries ...*m.EditLogEntry) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.entries = append(l.entries, entries...)
+	if l.filename == "" {
+		return nil
+	}
+	file, err := os.OpenFile(l.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return writeEntries(file, entries)
+}
+
+/** Drops every entry after index; used when a follower's log diverges from the leader's */
+func (l *EditLogImpl) TruncateAfter(index int64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if index >= int64(len(l.entries)) {
+		return nil
+	}
+	l.entries = l.entries[:index]
+	if l.filename == "" {
+		return nil
+	}
+	file, err := os.Create(l.filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return writeEntries(file, l.entries)
+}
+
+func (l *EditLogImpl) Get(index int64) *m.EditLogEntry {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if index < 1 || index > int64(len(l.entries)) {
+		return nil
+	}
+	return l.entries[index-1]
+}
+
+func (l *EditLogImpl) From(index int64) []*m.EditLogEntry {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if index < 1 {
+		index = 1
+	}
+	if index > int64(len(l.entries)) {
+		return []*m.EditLogEntry{}
+	}
+	entries := make([]*m.EditLogEntry, int64(len(l.entries))-index+1)
+	copy(entries, l.entries[index-1:])
+	return entries
+}
+
+func (l *EditLogImpl) LastIndex() int64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return int64(len(l.entries))
+}
+
+func (l *EditLogImpl) LastTerm() int64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	if len(l.entries) == 0 {
+		return 0
+	}
+	return l.entries[len(l.entries)-1].Term
+}
+
+/**
+* A controller must not forget a vote it gave: returns only once term and
+* vote are synced to disk, replacing the previous ones in one rename.
+ */
+func (l *EditLogImpl) SaveVote(term int64, votedFor string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.filename != "" {
+		if err := writeVote(l.filename+TERM_FILE_SUFFIX, term, votedFor); err != nil {
+			return err
+		}
+	}
+	l.term, l.votedFor = term, votedFor
+	return nil
+}
+
+func (l *EditLogImpl) LoadVote() (term int64, votedFor string) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.term, l.votedFor
+}
+
+func writeVote(filename string, term int64, votedFor string) error {
+	tmp := filename + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(strconv.FormatInt(term, 10) + "\n" + votedFor + "\n")
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		return err
+	}
+	// the rename itself only lasts once the directory is synced
+	dir, err := os.Open(filepath.Dir(filename))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func readVote(filename string) (int64, string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, "", err
+	}
+	lines := strings.SplitN(string(data), "\n", 3)
+	if len(lines) < 2 {
+		return 0, "", errors.New("malformed term file " + filename)
+	}
+	term, err := strconv.ParseInt(lines[0], 10, 64)
+	if err != nil {
+		return 0, "", errors.New("malformed term file " + filename + ": " + err.Error())
+	}
+	return term, lines[1], nil
+}
+
+func writeEntries(writer io.Writer, entries []*m.EditLogEntry) error {
+	for _, entry := range entries {
+		serialized, err := proto.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		prefix := make([]byte, 8)
+		binary.LittleEndian.PutUint64(prefix, uint64(len(serialized)))
+		if _, err := writer.Write(append(prefix, serialized...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readEntries(filename string) ([]*m.EditLogEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return make([]*m.EditLogEntry, 0), err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	entries := make([]*m.EditLogEntry, 0)
+	prefix := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, prefix); err != nil {
+			if err == io.EOF {
+				return entries, nil
+			}
+			return entries, err
+		}
+		payload := make([]byte, binary.LittleEndian.Uint64(prefix))
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return entries, err
+		}
+		entry := &m.EditLogEntry{}
+		if err := proto.Unmarshal(payload, entry); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
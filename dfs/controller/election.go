@@ -0,0 +1,521 @@
+package controller
+
+import (
+	"adfs/common"
+	m "adfs/messages"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const FOLLOWER = "follower"
+const CANDIDATE = "candidate"
+const LEADER = "leader"
+
+const LEADER_HEARTBEAT_MS = 500
+
+// followers wait a random time within this range before running for leader
+const ELECTION_TIMEOUT_MIN_MS = 2000
+const ELECTION_TIMEOUT_MAX_MS = 4000
+
+// how long Append waits for a majority to store an entry
+const APPEND_TIMEOUT_MS = 5000
+
+/**
+* Raft-like leader election among controllers. The leader appends namespace
+* changes to the edit log and pushes them to the followers along with its
+* heartbeats. An entry is committed once a majority of the controllers store
+* it; every controller applies committed entries only, in log order.
+*
+* Both votes and commits need a majority of the controllers, so a cluster of
+* 2n+1 of them survives n failures: failing over takes at least 3. Even
+* counts survive no more failures than one controller less, and are refused.
+*
+* Term and vote are on disk before any peer hears of them, so a restarted
+* controller never votes twice in a term.
+ */
+type Election interface {
+	Start()
+	Stop()
+	IsLeader() bool
+	GetLeader() string
+	Append(entry *m.EditLogEntry) error
+	HandleVoteRequest(voteRequest *m.VoteRequest) *m.VoteResponse
+	HandleAppendEntries(appendEntries *m.AppendEntries) *m.AppendEntriesResponse
+	AddListenerOnApply(onApply func(entry *m.EditLogEntry))
+	AddListenerOnLeaderChange(onLeaderChange func(isLeader bool))
+}
+
+type ElectionImpl struct {
+	self                    string   // hostname:port of this controller
+	peers                   []string // hostname:port of the other controllers
+	editLog                 EditLog
+	term                    int64
+	votedFor                string
+	state                   string
+	leader                  string
+	lastContact             time.Time
+	electionTimeout         time.Duration
+	nextIndex               map[string]int64 // [peer] next entry to send; leader only
+	matchIndex              map[string]int64 // [peer] last entry known to be stored there; leader only
+	commitIndex             int64            // last entry stored by a majority
+	lastApplied             int64            // last entry handed to the apply listeners
+	applied                 *sync.Cond       // broadcast on applies and leadership changes
+	scheduler               *time.Ticker
+	quit                    chan bool
+	mutex                   sync.Mutex
+	onApplyListeners        []func(entry *m.EditLogEntry)
+	onLeaderChangeListeners []func(isLeader bool)
+}
+
+/** One controller, or an odd number of them */
+func validateControllers(controllers []string) error {
+	if len(controllers) > 1 && len(controllers)%2 == 0 {
+		return errors.New(strconv.Itoa(len(controllers)) + " controllers given: use an odd number of them, " +
+			"3 survive the failure of one")
+	}
+	return nil
+}
+
+func NewElection(self string, peers []string, editLog EditLog) Election {
+	rand.Seed(time.Now().UnixNano()) // controllers must not share election timeouts
+	e := &ElectionImpl{
+		self:                    self,
+		peers:                   peers,
+		editLog:                 editLog,
+		state:                   FOLLOWER,
+		nextIndex:               make(map[string]int64),
+		matchIndex:              make(map[string]int64),
+		quit:                    make(chan bool),
+		onApplyListeners:        make([]func(entry *m.EditLogEntry), 0),
+		onLeaderChangeListeners: make([]func(isLeader bool), 0),
+	}
+	e.applied = sync.NewCond(&e.mutex)
+	return e
+}
+
+/**
+* Alone, a controller committed every entry of its log and replays them all
+* before leading. With peers it can't tell which of its entries were committed,
+* so it waits for a leader (possibly itself) to commit them.
+ */
+func (e *ElectionImpl) Start() {
+	e.term, e.votedFor = e.editLog.LoadVote()
+	if lastTerm := e.editLog.LastTerm(); lastTerm > e.term {
+		e.term, e.votedFor = lastTerm, ""
+	}
+	if len(e.peers) == 0 {
+		e.mutex.Lock()
+		e.commit(e.editLog.LastIndex())
+		e.becomeLeader()
+		e.mutex.Unlock()
+		return
+	}
+	e.lastContact = time.Now()
+	e.electionTimeout = randomElectionTimeout()
+	e.scheduler = time.NewTicker(LEADER_HEARTBEAT_MS * time.Millisecond)
+	go e.worker()
+}
+
+func (e *ElectionImpl) Stop() {
+	if e.scheduler == nil {
+		return
+	}
+	e.scheduler.Stop()
+	e.quit <- true
+}
+
+func (e *ElectionImpl) worker() {
+	for {
+		select {
+		case <-e.quit:
+			return
+		case <-e.scheduler.C:
+			e.mutex.Lock()
+			state := e.state
+			timedOut := time.Since(e.lastContact) > e.electionTimeout
+			e.mutex.Unlock()
+			if state == LEADER {
+				e.replicate()
+			} else if timedOut {
+				e.runForLeader()
+			}
+		}
+	}
+}
+
+func (e *ElectionImpl) IsLeader() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.state == LEADER
+}
+
+func (e *ElectionImpl) GetLeader() string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.leader
+}
+
+/**
+* Leader only: stamps the entry, pushes it to the followers right away and
+* returns once it is committed and applied here. An error means the entry may
+* or may not end up committed (e.g. leadership lost before a majority answered).
+ */
+func (e *ElectionImpl) Append(entry *m.EditLogEntry) error {
+	e.mutex.Lock()
+	if e.state != LEADER {
+		e.mutex.Unlock()
+		return errors.New(common.NOT_LEADER_ERROR_MSG + e.leader)
+	}
+	term := e.term
+	e.appendEntry(entry)
+	e.advanceCommit()
+	e.mutex.Unlock()
+	go e.replicate()
+	return e.waitApplied(entry.Index, term)
+}
+
+/** Must hold the mutex */
+func (e *ElectionImpl) appendEntry(entry *m.EditLogEntry) {
+	entry.Index = e.editLog.LastIndex() + 1
+	entry.Term = e.term
+	if err := e.editLog.Append(entry); err != nil {
+		logrus.WithFields(logrus.Fields{"Error": err.Error()}).Error("Could not persist edit log entry")
+	}
+}
+
+func (e *ElectionImpl) waitApplied(index, term int64) error {
+	timedOut := false
+	timer := time.AfterFunc(APPEND_TIMEOUT_MS*time.Millisecond, func() {
+		e.mutex.Lock()
+		timedOut = true
+		e.applied.Broadcast()
+		e.mutex.Unlock()
+	})
+	defer timer.Stop()
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for e.lastApplied < index && e.state == LEADER && e.term == term && !timedOut {
+		e.applied.Wait()
+	}
+	// a later leader may have replaced the entry with one of its own
+	if entry := e.editLog.Get(index); e.lastApplied >= index && entry != nil && entry.Term == term {
+		return nil
+	}
+	return errors.New("change not stored by a majority of the controllers")
+}
+
+/** Leader only, must hold the mutex: commits the last entry of this term a majority stores */
+func (e *ElectionImpl) advanceCommit() {
+	majority := (len(e.peers)+1)/2 + 1
+	for index := e.editLog.LastIndex(); index > e.commitIndex; index-- {
+		// entries of older terms only commit along with one of ours (Raft §5.4.2)
+		if entry := e.editLog.Get(index); entry == nil || entry.Term != e.term {
+			return
+		}
+		stored := 1 // our own copy
+		for _, peer := range e.peers {
+			if e.matchIndex[peer] >= index {
+				stored++
+			}
+		}
+		if stored >= majority {
+			e.commit(index)
+			return
+		}
+	}
+}
+
+/** Must hold the mutex: applies every entry up to index, in order */
+func (e *ElectionImpl) commit(index int64) {
+	if index > e.editLog.LastIndex() {
+		index = e.editLog.LastIndex()
+	}
+	if index > e.commitIndex {
+		e.commitIndex = index
+	}
+	for e.lastApplied < e.commitIndex {
+		e.lastApplied++
+		if entry := e.editLog.Get(e.lastApplied); entry != nil {
+			e.apply(entry)
+		}
+	}
+	e.applied.Broadcast()
+}
+
+/** Must hold the mutex */
+func (e *ElectionImpl) persistVote() error {
+	if err := e.editLog.SaveVote(e.term, e.votedFor); err != nil {
+		logrus.WithFields(logrus.Fields{"Term": e.term, "Error": err.Error()}).Error("Could not persist election term")
+		return err
+	}
+	return nil
+}
+
+func (e *ElectionImpl) runForLeader() {
+	e.mutex.Lock()
+	if err := e.editLog.SaveVote(e.term+1, e.self); err != nil {
+		logrus.WithFields(logrus.Fields{"Term": e.term + 1, "Error": err.Error()}).Error("Could not persist election term, not running for leader")
+		e.lastContact = time.Now()
+		e.mutex.Unlock()
+		return
+	}
+	e.term++
+	e.state = CANDIDATE
+	e.votedFor = e.self
+	e.leader = ""
+	e.lastContact = time.Now()
+	e.electionTimeout = randomElectionTimeout()
+	term := e.term
+	voteRequest := &m.VoteRequest{
+		Term:      term,
+		Candidate: e.self,
+		LastIndex: e.editLog.LastIndex(),
+		LastTerm:  e.editLog.LastTerm(),
+	}
+	e.mutex.Unlock()
+	logrus.WithFields(logrus.Fields{"Term": term}).Info("Running for controller leader")
+
+	votes := 1 // own vote
+	for _, peer := range e.peers {
+		msgHandler, err := m.GetMessageHandlerFor(peer)
+		if err != nil {
+			continue // unreachable peers never count as votes, see Election
+		}
+		msgHandler.SendVoteRequest(voteRequest)
+		wrapper, _ := msgHandler.Receive()
+		msgHandler.Close()
+		voteResponse, ok := wrapper.Msg.(*m.Wrapper_VoteResponseMessage)
+		if !ok {
+			continue
+		}
+		if e.stepDownIfBehind(voteResponse.VoteResponseMessage.Term) {
+			return
+		}
+		if voteResponse.VoteResponseMessage.Granted {
+			votes++
+		}
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.state == CANDIDATE && e.term == term && votes > (len(e.peers)+1)/2 {
+		e.becomeLeader()
+	}
+}
+
+/**
+* Must hold the mutex. With peers, the leader opens its term with an empty
+* entry: committing it commits whatever earlier leaders left in the log.
+ */
+func (e *ElectionImpl) becomeLeader() {
+	e.state = LEADER
+	e.leader = e.self
+	for _, peer := range e.peers {
+		e.nextIndex[peer] = e.editLog.LastIndex() + 1
+		e.matchIndex[peer] = 0
+	}
+	if len(e.peers) > 0 {
+		e.appendEntry(&m.EditLogEntry{})
+	}
+	logrus.WithFields(logrus.Fields{"Term": e.term, "Addr": e.self}).Info("Elected controller leader")
+	e.notifyLeaderChange(true)
+}
+
+/** Must hold the mutex */
+func (e *ElectionImpl) becomeFollower(term int64, leader string) {
+	wasLeader := e.state == LEADER
+	if term > e.term {
+		e.term = term
+		e.votedFor = ""
+		e.persistVote() // a failure only loses the term; votes are persisted before they are given
+	}
+	e.state = FOLLOWER
+	e.leader = leader
+	e.applied.Broadcast() // pending Appends of ours can't commit anymore
+	if wasLeader {
+		logrus.WithFields(logrus.Fields{"Term": e.term}).Warn("Stepped down as controller leader")
+		e.notifyLeaderChange(false)
+	}
+}
+
+/** Returns true if a peer is on a newer term, turning this controller into a follower */
+func (e *ElectionImpl) stepDownIfBehind(term int64) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if term > e.term {
+		e.becomeFollower(term, "")
+		e.lastContact = time.Now()
+		return true
+	}
+	return false
+}
+
+func (e *ElectionImpl) notifyLeaderChange(isLeader bool) {
+	for _, f := range e.onLeaderChangeListeners {
+		go f(isLeader)
+	}
+}
+
+/** Leader heartbeat: sends every follower the entries it is missing (possibly none) */
+func (e *ElectionImpl) replicate() {
+	var wg sync.WaitGroup
+	for _, peer := range e.peers {
+		wg.Add(1)
+		go func(peer string) {
+			defer wg.Done()
+			e.replicateTo(peer)
+		}(peer)
+	}
+	wg.Wait()
+}
+
+func (e *ElectionImpl) replicateTo(peer string) {
+	e.mutex.Lock()
+	if e.state != LEADER {
+		e.mutex.Unlock()
+		return
+	}
+	nextIndex := e.nextIndex[peer]
+	if nextIndex < 1 {
+		nextIndex = 1
+	}
+	appendEntries := &m.AppendEntries{
+		Term:         e.term,
+		Leader:       e.self,
+		PrevIndex:    nextIndex - 1,
+		Entries:      e.editLog.From(nextIndex),
+		LeaderCommit: e.commitIndex,
+	}
+	if prev := e.editLog.Get(nextIndex - 1); prev != nil {
+		appendEntries.PrevTerm = prev.Term
+	}
+	e.mutex.Unlock()
+
+	msgHandler, err := m.GetMessageHandlerFor(peer)
+	if err != nil {
+		return // follower down; it catches up once back
+	}
+	defer msgHandler.Close()
+	msgHandler.SendAppendEntries(appendEntries)
+	wrapper, _ := msgHandler.Receive()
+	msg, ok := wrapper.Msg.(*m.Wrapper_AppendEntriesResponseMessage)
+	if !ok {
+		return
+	}
+	response := msg.AppendEntriesResponseMessage
+	if e.stepDownIfBehind(response.Term) {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.state != LEADER || e.term != appendEntries.Term {
+		return
+	}
+	if response.Ok {
+		// the follower may hold stale entries past the ones sent; they don't count
+		match := appendEntries.PrevIndex + int64(len(appendEntries.Entries))
+		if match > e.matchIndex[peer] {
+			e.matchIndex[peer] = match
+		}
+		e.nextIndex[peer] = e.matchIndex[peer] + 1
+		e.advanceCommit()
+	} else if nextIndex > 1 {
+		// logs diverge: walk back, but never past what the follower has
+		e.nextIndex[peer] = nextIndex - 1
+		if response.LastIndex+1 < e.nextIndex[peer] {
+			e.nextIndex[peer] = response.LastIndex + 1
+		}
+	}
+}
+
+func (e *ElectionImpl) HandleVoteRequest(voteRequest *m.VoteRequest) *m.VoteResponse {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if voteRequest.Term < e.term {
+		return &m.VoteResponse{Term: e.term, Granted: false}
+	}
+	if voteRequest.Term > e.term {
+		e.becomeFollower(voteRequest.Term, "")
+	}
+	lastTerm := e.editLog.LastTerm()
+	upToDate := voteRequest.LastTerm > lastTerm ||
+		(voteRequest.LastTerm == lastTerm && voteRequest.LastIndex >= e.editLog.LastIndex())
+	granted := upToDate && (e.votedFor == "" || e.votedFor == voteRequest.Candidate)
+	if granted && e.votedFor == "" {
+		e.votedFor = voteRequest.Candidate
+		if e.persistVote() != nil {
+			e.votedFor = ""
+			granted = false
+		}
+	}
+	if granted {
+		e.lastContact = time.Now()
+	}
+	return &m.VoteResponse{Term: e.term, Granted: granted}
+}
+
+func (e *ElectionImpl) HandleAppendEntries(appendEntries *m.AppendEntries) *m.AppendEntriesResponse {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if appendEntries.Term < e.term {
+		return &m.AppendEntriesResponse{Term: e.term, Ok: false, LastIndex: e.editLog.LastIndex()}
+	}
+	if e.leader != appendEntries.Leader {
+		logrus.WithFields(logrus.Fields{"Leader": appendEntries.Leader, "Term": appendEntries.Term}).Info("Following controller leader")
+	}
+	e.becomeFollower(appendEntries.Term, appendEntries.Leader)
+	e.lastContact = time.Now()
+
+	if appendEntries.PrevIndex > e.editLog.LastIndex() {
+		return &m.AppendEntriesResponse{Term: e.term, Ok: false, LastIndex: e.editLog.LastIndex()}
+	}
+	if prev := e.editLog.Get(appendEntries.PrevIndex); prev != nil && prev.Term != appendEntries.PrevTerm {
+		e.editLog.TruncateAfter(appendEntries.PrevIndex - 1)
+		return &m.AppendEntriesResponse{Term: e.term, Ok: false, LastIndex: e.editLog.LastIndex()}
+	}
+	for _, entry := range appendEntries.Entries {
+		if existing := e.editLog.Get(entry.Index); existing != nil {
+			if existing.Term == entry.Term {
+				continue // already have it
+			}
+			// never committed (a majority would have elected a leader holding it); safe to drop
+			e.editLog.TruncateAfter(entry.Index - 1)
+		}
+		if err := e.editLog.Append(entry); err != nil {
+			logrus.WithFields(logrus.Fields{"Error": err.Error()}).Error("Could not persist edit log entry")
+		}
+	}
+	// what we hold past the entries sent may not be the leader's
+	lastNew := appendEntries.PrevIndex + int64(len(appendEntries.Entries))
+	if appendEntries.LeaderCommit < lastNew {
+		lastNew = appendEntries.LeaderCommit
+	}
+	if lastNew > e.commitIndex {
+		e.commit(lastNew)
+	}
+	return &m.AppendEntriesResponse{Term: e.term, Ok: true, LastIndex: e.editLog.LastIndex()}
+}
+
+func (e *ElectionImpl) apply(entry *m.EditLogEntry) {
+	for _, f := range e.onApplyListeners {
+		f(entry)
+	}
+}
+
+func (e *ElectionImpl) AddListenerOnApply(onApply func(entry *m.EditLogEntry)) {
+	e.onApplyListeners = append(e.onApplyListeners, onApply)
+}
+
+func (e *ElectionImpl) AddListenerOnLeaderChange(onLeaderChange func(isLeader bool)) {
+	e.onLeaderChangeListeners = append(e.onLeaderChangeListeners, onLeaderChange)
+}
+
+func randomElectionTimeout() time.Duration {
+	spread := ELECTION_TIMEOUT_MAX_MS - ELECTION_TIMEOUT_MIN_MS
+	return time.Duration(ELECTION_TIMEOUT_MIN_MS+rand.Intn(spread)) * time.Millisecond
+}
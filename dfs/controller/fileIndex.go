@@ -1,12 +1,16 @@
 package controller
 
 import (
+	"adfs/common"
+	ec "adfs/erasure_coding"
 	"adfs/helpers"
 	m "adfs/messages"
 	"errors"
 	"strconv"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
 )
 
 type FileIndex interface {
@@ -16,39 +20,172 @@ type FileIndex interface {
 	Get(filename string) (*m.File, error)
 	Put(fileIndex *m.Chunk)
 	PutAll(storageNode *m.Node, chunks []*m.Chunk)
+	SyncNode(storageNode *m.Node, chunks []*m.Chunk) []*m.Chunk
 	Rm(filename string) error
-	ReserveSlot(filename string)
-	FileExists(filename string) bool
+	ReserveSlot(filename string, replication int32, bytes int64)
+	Reserve(filename string, replication int32, bytes int64) error
+	IsPending(filename string) bool
 	NodeDown(nodeUuid string)
+	SetReplication(filename string, replication int32) error
+	RmReplica(filename, chunkName, nodeUuid string)
+	MoveReplica(filename, chunkName, fromUuid string, to *m.Node)
+	SetPolicy(dirname, policy string)
+	GetPolicy(filename string) string
+	GetStripe(filename string, stripe int32) []*m.Chunk
+	ChunkReport() (reported, expected int64)
+	SetQuota(dirname string, maxFiles, maxBytes int64)
+	GetQuotas() []*m.Quota
+	CheckQuota(filename string, bytes int64) error
+	Fsck(path string) *FsckScan
 }
 
 type FileIndexImpl struct {
-	index            map[string]*FileMetadata // [dirname] filemetadata  /folder1/test.img
-	pendingUploads   map[string]bool
+	index            map[string]*FileMetadata  // [dirname] filemetadata  /folder1/test.img
+	pendingUploads   map[string]*PendingUpload // [dirname] upload reserved by a PUT
 	updateIndexChan  chan *StorageNodeUpdate
+	syncNodeCh       chan *StorageNodeUpdate
 	rmFileCh         chan string
-	pendingUploadsCh chan string
+	pendingUploadsCh chan *PendingUpload
+	setRepCh         chan *ReplicationUpdate
+	rmReplicaCh      chan *ReplicaRemoval
+	moveReplicaCh    chan *ReplicaMove
 	nodeDownCh       chan string
+	dirPolicies      map[string]string // [dirname] storage policy for new files
+	dirPoliciesCh    chan *PolicyUpdate
+	quotas           map[string]*DirQuota // [dirname] limits; "" is the root
+	quotasCh         chan *DirQuota
+	chunkReportCh    chan chan *ChunkCount
+	quotaChecksCh    chan *QuotaCheck
+	quotaReportCh    chan chan []*m.Quota
+	pendingChecksCh  chan *PendingCheck
+	lsCh             chan chan []*FileMetadata
+	getCh            chan *FileRequest
+	stripesCh        chan *StripeRequest
+	policiesCh       chan *PolicyRequest
+	fsckCh           chan *FsckRequest
 }
 
 type FileMetadata struct {
+	filename    string
+	replication int32
+	policy      string
+	chunks      map[string]*m.Chunk           // [chunkName] chunkInformation
+	stripes     map[int32][]string            // [stripe] chunkNames; erasure coded files only
+	corrupt     map[string]map[string]*m.Node // [chunkName][uuid] replicas failing verification
+	reserved    int64                         // bytes announced at PUT; counts against quotas until uploaded
+}
+
+type PolicyUpdate struct {
+	dirname string
+	policy  string
+}
+
+type PendingUpload struct {
+	filename    string
+	replication int32
+	bytes       int64 // space the upload will take, replicas included
+}
+
+type DirQuota struct {
+	dirname  string
+	maxFiles int64 // 0 means no limit
+	maxBytes int64
+}
+
+/** Quota check, and the reservation of the upload if reserve is set, done in one go by the worker */
+type QuotaCheck struct {
+	upload  *PendingUpload
+	reserve bool
+	result  chan error
+}
+
+type PendingCheck struct {
+	filename string
+	result   chan bool
+}
+
+type FileRequest struct {
 	filename string
-	chunks   map[string]*m.Chunk // [chunkName] chunkInformation
+	result   chan *m.File // nil if the file isn't indexed
+}
+
+type StripeRequest struct {
+	filename string
+	stripe   int32
+	result   chan []*m.Chunk
+}
+
+type PolicyRequest struct {
+	filename string
+	result   chan string
+}
+
+type FsckRequest struct {
+	path   string
+	result chan *FsckScan
+}
+
+/** Fsck report built by the worker, with copies of the corrupt replicas a repair deletes */
+type FsckScan struct {
+	report  *m.FsckReport
+	corrupt map[string][]*m.Chunk // [filename] one chunk per corrupt replica
+}
+
+type ChunkCount struct {
+	reported int64
+	expected int64
+}
+
+type ReplicationUpdate struct {
+	filename    string
+	replication int32
+	done        chan error // closed by the worker once the factor is applied
+}
+
+type ReplicaRemoval struct {
+	filename  string
+	chunkName string
+	nodeUuid  string
+}
+
+type ReplicaMove struct {
+	filename  string
+	chunkName string
+	fromUuid  string
+	to        *m.Node
 }
 
 type StorageNodeUpdate struct {
 	storageNode *m.Node
 	chunks      []*m.Chunk
+	refused     chan []*m.Chunk // chunks left out of the index for going over a quota; nil if nobody asks
 }
 
 func NewFileIndex() FileIndex {
 	return &FileIndexImpl{
 		index:            make(map[string]*FileMetadata),
-		pendingUploads:   make(map[string]bool),
+		pendingUploads:   make(map[string]*PendingUpload),
 		updateIndexChan:  make(chan *StorageNodeUpdate),
+		syncNodeCh:       make(chan *StorageNodeUpdate),
 		rmFileCh:         make(chan string),
-		pendingUploadsCh: make(chan string),
+		pendingUploadsCh: make(chan *PendingUpload),
+		setRepCh:         make(chan *ReplicationUpdate),
+		rmReplicaCh:      make(chan *ReplicaRemoval),
+		moveReplicaCh:    make(chan *ReplicaMove),
 		nodeDownCh:       make(chan string),
+		dirPolicies:      make(map[string]string),
+		dirPoliciesCh:    make(chan *PolicyUpdate),
+		quotas:           make(map[string]*DirQuota),
+		quotasCh:         make(chan *DirQuota),
+		chunkReportCh:    make(chan chan *ChunkCount),
+		quotaChecksCh:    make(chan *QuotaCheck),
+		quotaReportCh:    make(chan chan []*m.Quota),
+		pendingChecksCh:  make(chan *PendingCheck),
+		lsCh:             make(chan chan []*FileMetadata),
+		getCh:            make(chan *FileRequest),
+		stripesCh:        make(chan *StripeRequest),
+		policiesCh:       make(chan *PolicyRequest),
+		fsckCh:           make(chan *FsckRequest),
 	}
 }
 
@@ -64,8 +201,11 @@ func (f *FileIndexImpl) worker() {
 		select {
 		case storageNodeUpdate := <-f.updateIndexChan:
 			f.handleStorageNodeUpdate(storageNodeUpdate)
+		case storageNodeUpdate := <-f.syncNodeCh:
+			storageNodeUpdate.refused <- f.handleSyncNode(storageNodeUpdate)
 		case filename := <-f.rmFileCh:
 			delete(f.index, filename)
+			delete(f.pendingUploads, filename)
 			fields := logrus.Fields{}
 			i := 0
 			for _, f := range f.index {
@@ -73,25 +213,90 @@ func (f *FileIndexImpl) worker() {
 				i++
 			}
 			logrus.WithFields(fields).Info("Current files: ")
-		case filename := <-f.pendingUploadsCh:
-			f.pendingUploads[filename] = true
+		case pendingUpload := <-f.pendingUploadsCh:
+			f.pendingUploads[pendingUpload.filename] = pendingUpload
+		case update := <-f.setRepCh:
+			if file, present := f.index[update.filename]; present {
+				file.replication = update.replication
+			} else if pending, present := f.pendingUploads[update.filename]; present {
+				pending.replication = update.replication // keeps the bytes reserved
+			} else {
+				update.done <- errors.New(update.filename + " doesn't exist")
+			}
+			close(update.done)
+		case removal := <-f.rmReplicaCh:
+			f.handleRmReplica(removal)
+		case move := <-f.moveReplicaCh:
+			f.handleMoveReplica(move)
+		case update := <-f.dirPoliciesCh:
+			f.dirPolicies[update.dirname] = update.policy
+		case quota := <-f.quotasCh:
+			if quota.maxFiles <= 0 && quota.maxBytes <= 0 {
+				delete(f.quotas, quota.dirname)
+			} else {
+				f.quotas[quota.dirname] = quota
+			}
 		case nodeUuid := <-f.nodeDownCh:
 			f.handleNodeDown(nodeUuid)
+		case reply := <-f.chunkReportCh:
+			reply <- f.countChunks()
+		case check := <-f.quotaChecksCh:
+			check.result <- f.handleQuotaCheck(check)
+		case reply := <-f.quotaReportCh:
+			reply <- f.getQuotas()
+		case check := <-f.pendingChecksCh:
+			_, pending := f.pendingUploads[check.filename]
+			check.result <- pending
+		case reply := <-f.lsCh:
+			reply <- f.ls()
+		case request := <-f.getCh:
+			request.result <- f.get(request.filename)
+		case request := <-f.stripesCh:
+			request.result <- f.getStripe(request.filename, request.stripe)
+		case request := <-f.policiesCh:
+			request.result <- f.getPolicy(request.filename)
+		case request := <-f.fsckCh:
+			request.result <- f.fsck(request.path)
 		}
 	}
 }
 
 // this function is nasty
-func (f *FileIndexImpl) handleStorageNodeUpdate(storageNodeUpdate *StorageNodeUpdate) {
+func (f *FileIndexImpl) handleStorageNodeUpdate(storageNodeUpdate *StorageNodeUpdate) []*m.Chunk {
+	refused := make([]*m.Chunk, 0)
 	for _, newChunk := range storageNodeUpdate.chunks {
 		sn := storageNodeUpdate.storageNode
+		if newChunk.Corrupt {
+			f.markCorrupt(sn, newChunk)
+			continue
+		}
+		f.clearCorrupt(newChunk.FileName, newChunk.ChunkName, sn.Uuid)
 		newStorageNodes := map[string]*m.Node{
 			sn.Uuid: sn}
 
 		newFilename := newChunk.FileName
-		if _, prst := f.pendingUploads[newFilename]; prst {
-			delete(f.pendingUploads, newFilename)
+		replication := newChunk.Replication
+		var reserved int64 = 0
+		pending, isPending := f.pendingUploads[newFilename]
+		if isPending {
+			if pending.replication > 0 {
+				replication = pending.replication
+			}
+			reserved = pending.bytes
+		}
+		if replication <= 0 {
+			replication = common.DEFAULT_REPLICATION
 		}
+		if err := f.checkArrival(newChunk, replication, reserved); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"Chunk": newChunk.ChunkName,
+				"UUID":  sn.Uuid,
+				"Error": err.Error(),
+			}).Warn("Chunk refused")
+			refused = append(refused, newChunk)
+			continue
+		}
+		delete(f.pendingUploads, newFilename)
 
 		file, present := f.index[newFilename]
 		newChunk.StorageNodes = newStorageNodes
@@ -99,9 +304,14 @@ func (f *FileIndexImpl) handleStorageNodeUpdate(storageNodeUpdate *StorageNodeUp
 		if !present {
 			chunks := map[string]*m.Chunk{newChunk.ChunkName: newChunk}
 			fileMetadata := &FileMetadata{
-				filename: newChunk.FileName,
-				chunks:   chunks,
+				filename:    newChunk.FileName,
+				replication: replication,
+				policy:      ec.PolicyOf(newChunk.DataShards, newChunk.ParityShards),
+				chunks:      chunks,
+				stripes:     make(map[int32][]string),
+				reserved:    reserved,
 			}
+			fileMetadata.addToStripe(newChunk)
 			f.index[newFilename] = fileMetadata
 		} else { // case: file exists in index
 			chunks := file.chunks
@@ -118,33 +328,206 @@ func (f *FileIndexImpl) handleStorageNodeUpdate(storageNodeUpdate *StorageNodeUp
 			} else {
 				// case: chunk doesn't exist in file of file index
 				chunks[newChunk.ChunkName] = newChunk
+				file.addToStripe(newChunk)
 			}
 		}
 	}
+	return refused
+}
+
+/**
+* PUTs are checked against the size the client announced, so chunks are
+* charged again as they show up: a chunk taking its upload past that size
+* must fit in the quotas too. Files without a reservation (indexed from the
+* nodes' reports after a restart) are taken as they are.
+ */
+func (f *FileIndexImpl) checkArrival(chunk *m.Chunk, replication int32, reserved int64) error {
+	var stored int64 = 0
+	if file, present := f.index[chunk.FileName]; present {
+		if _, known := file.chunks[chunk.ChunkName]; known {
+			return nil // another replica, already charged
+		}
+		stored, reserved, replication = file.chunkBytes(), file.reserved, file.replication
+	}
+	if reserved == 0 {
+		return nil
+	}
+	charged := chunk.Size * int64(replication)
+	if chunk.DataShards > 0 {
+		charged = chunk.Size
+	}
+	before, after := stored, stored+charged
+	if reserved > before {
+		before = reserved
+	}
+	if after <= before {
+		return nil
+	}
+	return f.checkQuota(chunk.FileName, 0, after-before)
 }
 
+/** The node's copy can't be trusted: it stops being an owner until it reports a healthy copy */
+func (f *FileIndexImpl) markCorrupt(sn *m.Node, chunk *m.Chunk) {
+	file, present := f.index[chunk.FileName]
+	if !present {
+		// unreadable replicas don't tell which file they belong to
+		file = f.findFileOf(chunk.ChunkName)
+	}
+	if file == nil {
+		logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName, "UUID": sn.Uuid}).Warn("Corrupt replica of unknown chunk")
+		return
+	}
+	if c, present := file.chunks[chunk.ChunkName]; present {
+		delete(c.StorageNodes, sn.Uuid)
+	}
+	if file.corrupt == nil {
+		file.corrupt = make(map[string]map[string]*m.Node)
+	}
+	if file.corrupt[chunk.ChunkName] == nil {
+		file.corrupt[chunk.ChunkName] = make(map[string]*m.Node)
+	}
+	if _, known := file.corrupt[chunk.ChunkName][sn.Uuid]; !known {
+		logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName, "UUID": sn.Uuid}).Error("Corrupt replica reported")
+	}
+	file.corrupt[chunk.ChunkName][sn.Uuid] = sn
+}
+
+func (f *FileIndexImpl) clearCorrupt(filename, chunkName, nodeUuid string) {
+	file, present := f.index[filename]
+	if !present || file.corrupt == nil {
+		return
+	}
+	delete(file.corrupt[chunkName], nodeUuid)
+	if len(file.corrupt[chunkName]) == 0 {
+		delete(file.corrupt, chunkName)
+	}
+}
+
+func (f *FileIndexImpl) findFileOf(chunkName string) *FileMetadata {
+	for _, file := range f.index {
+		if _, present := file.chunks[chunkName]; present {
+			return file
+		}
+	}
+	return nil
+}
+
+func (fm *FileMetadata) addToStripe(chunk *m.Chunk) {
+	if chunk.DataShards == 0 {
+		return
+	}
+	fm.stripes[chunk.Stripe] = append(fm.stripes[chunk.Stripe], chunk.ChunkName)
+}
+
+/** Snapshot of every indexed file, copied by the worker */
 func (f *FileIndexImpl) Ls() []*FileMetadata {
+	reply := make(chan []*FileMetadata)
+	f.lsCh <- reply
+	return <-reply
+}
+
+func (f *FileIndexImpl) ls() []*FileMetadata {
 	metadata := []*FileMetadata{}
 	for _, v := range f.index {
-		metadata = append(metadata, v)
+		metadata = append(metadata, v.copy())
 	}
 	return metadata
 }
 
+func (fm *FileMetadata) copy() *FileMetadata {
+	c := *fm
+	c.chunks = make(map[string]*m.Chunk, len(fm.chunks))
+	for chunkName, chunk := range fm.chunks {
+		c.chunks[chunkName] = copyChunk(chunk)
+	}
+	c.stripes = make(map[int32][]string, len(fm.stripes))
+	for stripe, chunkNames := range fm.stripes {
+		c.stripes[stripe] = append([]string{}, chunkNames...)
+	}
+	c.corrupt = make(map[string]map[string]*m.Node, len(fm.corrupt))
+	for chunkName, nodes := range fm.corrupt {
+		c.corrupt[chunkName] = make(map[string]*m.Node, len(nodes))
+		for uuid, node := range nodes {
+			c.corrupt[chunkName][uuid] = node
+		}
+	}
+	return &c
+}
+
+/** File with its chunks, copied by the worker */
 func (f *FileIndexImpl) Get(filename string) (*m.File, error) {
-	_, exists := f.index[filename]
+	request := &FileRequest{filename, make(chan *m.File)}
+	f.getCh <- request
+	if file := <-request.result; file != nil {
+		return file, nil
+	}
+	return nil, errors.New(filename + " doesn't exist")
+}
+
+func (f *FileIndexImpl) get(filename string) *m.File {
+	file, exists := f.index[filename]
 	if !exists {
-		return nil, errors.New(filename + " doesn't exist")
+		return nil
 	}
 	chunks := []*m.Chunk{}
-	for _, c := range f.index[filename].chunks {
-		chunks = append(chunks, c)
+	for _, c := range file.chunks {
+		chunks = append(chunks, copyChunk(c))
 	}
 	return &m.File{
-		Name:    helpers.GetFilename(filename),
-		Dirname: filename,
-		Chunks:  chunks,
-	}, nil
+		Name:          helpers.GetFilename(filename),
+		Dirname:       filename,
+		Chunks:        chunks,
+		Replication:   file.replication,
+		StoragePolicy: file.policy,
+	}
+}
+
+/** Chunks (data and parity) forming the given stripe of an erasure coded file, copied by the worker */
+func (f *FileIndexImpl) GetStripe(filename string, stripe int32) []*m.Chunk {
+	request := &StripeRequest{filename, stripe, make(chan []*m.Chunk)}
+	f.stripesCh <- request
+	return <-request.result
+}
+
+func (f *FileIndexImpl) getStripe(filename string, stripe int32) []*m.Chunk {
+	file, exists := f.index[filename]
+	if !exists {
+		return nil
+	}
+	chunks := []*m.Chunk{}
+	for _, chunkName := range file.stripes[stripe] {
+		chunks = append(chunks, copyChunk(file.chunks[chunkName]))
+	}
+	return chunks
+}
+
+/** Index chunks keep changing in the worker; callers outside it get their own copy */
+func copyChunk(chunk *m.Chunk) *m.Chunk {
+	return proto.Clone(chunk).(*m.Chunk)
+}
+
+/** New files inherit the policy of their closest ancestor directory */
+func (f *FileIndexImpl) SetPolicy(dirname, policy string) {
+	f.dirPoliciesCh <- &PolicyUpdate{dirname, policy}
+}
+
+func (f *FileIndexImpl) GetPolicy(filename string) string {
+	request := &PolicyRequest{filename, make(chan string)}
+	f.policiesCh <- request
+	return <-request.result
+}
+
+func (f *FileIndexImpl) getPolicy(filename string) string {
+	dirname := helpers.GetPathFrom(filename)
+	for {
+		if policy, present := f.dirPolicies[dirname]; present {
+			return policy
+		}
+		if dirname == "" {
+			return ec.REPLICATED_POLICY
+		}
+		dirname = helpers.GetPathFrom(dirname)
+	}
 }
 
 func (f *FileIndexImpl) Put(chunk *m.Chunk) {
@@ -160,19 +543,120 @@ func (f *FileIndexImpl) PutAll(node *m.Node, chunks []*m.Chunk) {
 	f.updateIndexChan <- storageNodeUpdate
 }
 
+/**
+* Like PutAll, but chunks is the complete list held by the node: the node is
+* dropped as owner of anything it no longer reports, and its address is
+* refreshed where it is still an owner.
+ */
+func (f *FileIndexImpl) SyncNode(node *m.Node, chunks []*m.Chunk) []*m.Chunk {
+	update := &StorageNodeUpdate{
+		storageNode: node,
+		chunks:      chunks,
+		refused:     make(chan []*m.Chunk),
+	}
+	f.syncNodeCh <- update
+	return <-update.refused
+}
+
+func (f *FileIndexImpl) handleSyncNode(storageNodeUpdate *StorageNodeUpdate) []*m.Chunk {
+	sn := storageNodeUpdate.storageNode
+	reported := make(map[string]bool)
+	for _, chunk := range storageNodeUpdate.chunks {
+		reported[chunk.ChunkName] = true
+	}
+	for _, file := range f.index {
+		for chunkName, chunk := range file.chunks {
+			if _, present := chunk.StorageNodes[sn.Uuid]; !present {
+				continue
+			}
+			if reported[chunkName] {
+				chunk.StorageNodes[sn.Uuid] = sn
+			} else {
+				delete(chunk.StorageNodes, sn.Uuid)
+			}
+		}
+		for chunkName := range file.corrupt {
+			if !reported[chunkName] {
+				f.clearCorrupt(file.filename, chunkName, sn.Uuid)
+			}
+		}
+	}
+	return f.handleStorageNodeUpdate(storageNodeUpdate)
+}
+
 func (f *FileIndexImpl) Rm(filename string) error {
 	f.rmFileCh <- filename
 	return nil
 }
 
-func (f *FileIndexImpl) FileExists(filename string) bool {
+func (f *FileIndexImpl) fileExists(filename string) bool {
 	_, presentIndex := f.index[filename]
 	_, presentPending := f.pendingUploads[filename]
 	return presentIndex || presentPending
 }
 
-func (f *FileIndexImpl) ReserveSlot(filename string) {
-	f.pendingUploadsCh <- filename
+/**
+* Reserves filename for an upload unless it is taken or bytes (replicas
+* included) would exceed a quota. Two PUTs can't both pass the checks.
+ */
+func (f *FileIndexImpl) Reserve(filename string, replication int32, bytes int64) error {
+	check := &QuotaCheck{&PendingUpload{filename, replication, bytes}, true, make(chan error)}
+	f.quotaChecksCh <- check
+	return <-check.result
+}
+
+/** Whether filename is reserved by a PUT none of whose chunks got reported yet */
+func (f *FileIndexImpl) IsPending(filename string) bool {
+	check := &PendingCheck{filename, make(chan bool)}
+	f.pendingChecksCh <- check
+	return <-check.result
+}
+
+/** Unchecked: replays reservations the leader already checked */
+func (f *FileIndexImpl) ReserveSlot(filename string, replication int32, bytes int64) {
+	f.pendingUploadsCh <- &PendingUpload{filename, replication, bytes}
+}
+
+/**
+* Returns once the worker has applied the new factor, so a following Get sees
+* it. Uploads none of whose chunks got reported yet take it on their reservation.
+ */
+func (f *FileIndexImpl) SetReplication(filename string, replication int32) error {
+	update := &ReplicationUpdate{filename, replication, make(chan error, 1)}
+	f.setRepCh <- update
+	return <-update.done
+}
+
+/** Forgets nodeUuid as owner of the chunk; used once surplus replicas are trimmed */
+func (f *FileIndexImpl) RmReplica(filename, chunkName, nodeUuid string) {
+	f.rmReplicaCh <- &ReplicaRemoval{filename, chunkName, nodeUuid}
+}
+
+func (f *FileIndexImpl) handleRmReplica(removal *ReplicaRemoval) {
+	file, present := f.index[removal.filename]
+	if !present {
+		return
+	}
+	if chunk, present := file.chunks[removal.chunkName]; present {
+		delete(chunk.StorageNodes, removal.nodeUuid)
+	}
+	f.clearCorrupt(removal.filename, removal.chunkName, removal.nodeUuid)
+}
+
+/** Swaps one owner of the chunk for another in a single index update */
+func (f *FileIndexImpl) MoveReplica(filename, chunkName, fromUuid string, to *m.Node) {
+	f.moveReplicaCh <- &ReplicaMove{filename, chunkName, fromUuid, to}
+}
+
+func (f *FileIndexImpl) handleMoveReplica(move *ReplicaMove) {
+	file, present := f.index[move.filename]
+	if !present {
+		return
+	}
+	if chunk, present := file.chunks[move.chunkName]; present {
+		chunk.StorageNodes[move.to.Uuid] = move.to
+		delete(chunk.StorageNodes, move.fromUuid)
+	}
 }
 
 func (f *FileIndexImpl) NodeDown(nodeUuid string) {
@@ -186,7 +670,197 @@ func (f *FileIndexImpl) handleNodeDown(nodeUuid string) {
 				delete(chunk.StorageNodes, nodeUuid)
 			}
 		}
+		for chunkName := range file.corrupt {
+			f.clearCorrupt(file.filename, chunkName, nodeUuid)
+		}
+	}
+}
+
+/**
+* Chunks with at least one live replica vs chunks the indexed files should
+* have. Files are only known once some node reports one of their chunks.
+ */
+/** Asked by the safe mode ticker; counted by the worker, the index can't change meanwhile */
+func (f *FileIndexImpl) ChunkReport() (reported, expected int64) {
+	reply := make(chan *ChunkCount)
+	f.chunkReportCh <- reply
+	count := <-reply
+	return count.reported, count.expected
+}
+
+func (f *FileIndexImpl) countChunks() *ChunkCount {
+	var reported, expected int64
+	for _, file := range f.index {
+		expected += file.expectedChunks()
+		for _, chunk := range file.chunks {
+			if len(chunk.StorageNodes) > 0 {
+				reported++
+			}
+		}
+	}
+	return &ChunkCount{reported, expected}
+}
+
+/** Parity chunks included */
+func (fm *FileMetadata) expectedChunks() int64 {
+	expected := fm.expectedDataChunks()
+	for _, chunk := range fm.chunks {
+		if chunk.DataShards > 0 {
+			expected += fm.expectedStripes() * int64(chunk.ParityShards)
+		}
+		break
+	}
+	if n := int64(len(fm.chunks)); n > expected {
+		expected = n
+	}
+	return expected
+}
+
+/**
+* Every chunk carries the count the client recorded at upload. Chunks stored
+* before it was recorded only tell how far serials go.
+ */
+func (fm *FileMetadata) expectedDataChunks() int64 {
+	var expected int64 = 0
+	for _, chunk := range fm.chunks {
+		if chunk.TotalChunks > 0 {
+			return int64(chunk.TotalChunks)
+		}
+		if int64(chunk.Serial) >= expected {
+			expected = int64(chunk.Serial) + 1
+		}
+	}
+	return expected
+}
+
+/** 0 for replicated files */
+func (fm *FileMetadata) expectedStripes() int64 {
+	for _, chunk := range fm.chunks {
+		if chunk.DataShards == 0 {
+			return 0
+		}
+		return (fm.expectedDataChunks() + int64(chunk.DataShards) - 1) / int64(chunk.DataShards)
+	}
+	return 0
+}
+
+/** Limits apply to everything under dirname. 0 removes a limit; "/" is the root */
+func (f *FileIndexImpl) SetQuota(dirname string, maxFiles, maxBytes int64) {
+	f.quotasCh <- &DirQuota{strings.TrimSuffix(dirname, "/"), maxFiles, maxBytes}
+}
+
+/** Limits and usage as the worker sees them */
+func (f *FileIndexImpl) GetQuotas() []*m.Quota {
+	reply := make(chan []*m.Quota)
+	f.quotaReportCh <- reply
+	return <-reply
+}
+
+func (f *FileIndexImpl) getQuotas() []*m.Quota {
+	quotas := make([]*m.Quota, 0)
+	for dirname, quota := range f.quotas {
+		files, bytes := f.usage(dirname)
+		if dirname == "" {
+			dirname = "/"
+		}
+		quotas = append(quotas, &m.Quota{
+			Dirname:  dirname,
+			MaxFiles: quota.maxFiles,
+			MaxBytes: quota.maxBytes,
+			Files:    files,
+			Bytes:    bytes,
+		})
+	}
+	return quotas
+}
+
+/** Fails if storing bytes more as filename would exceed the quota of any directory above it */
+func (f *FileIndexImpl) CheckQuota(filename string, bytes int64) error {
+	check := &QuotaCheck{&PendingUpload{filename: filename, bytes: bytes}, false, make(chan error)}
+	f.quotaChecksCh <- check
+	return <-check.result
+}
+
+func (f *FileIndexImpl) handleQuotaCheck(check *QuotaCheck) error {
+	upload := check.upload
+	if check.reserve && f.fileExists(upload.filename) {
+		return errors.New("FileName already exists. Please choose a different name.")
+	}
+	if err := f.checkQuota(upload.filename, 1, upload.bytes); err != nil {
+		return err
+	}
+	if check.reserve {
+		f.pendingUploads[upload.filename] = upload
+	}
+	return nil
+}
+
+/** files and bytes are what the change adds under every directory above filename */
+func (f *FileIndexImpl) checkQuota(filename string, files, bytes int64) error {
+	dirname := helpers.GetPathFrom(filename)
+	for {
+		if quota, present := f.quotas[dirname]; present {
+			present, used := f.usage(dirname)
+			shown := dirname
+			if shown == "" {
+				shown = "/"
+			}
+			if quota.maxFiles > 0 && present+files > quota.maxFiles {
+				return errors.New("Quota exceeded on " + shown + ": at most " +
+					strconv.FormatInt(quota.maxFiles, 10) + " files allowed")
+			}
+			if quota.maxBytes > 0 && used+bytes > quota.maxBytes {
+				return errors.New("Quota exceeded on " + shown + ": " + strconv.FormatInt(used, 10) + " of " +
+					strconv.FormatInt(quota.maxBytes, 10) + " bytes used, " + strconv.FormatInt(bytes, 10) + " more requested")
+			}
+		}
+		if dirname == "" {
+			return nil
+		}
+		dirname = helpers.GetPathFrom(dirname)
+	}
+}
+
+/** Files under dirname and the bytes they take, uploads in progress included */
+func (f *FileIndexImpl) usage(dirname string) (files, bytes int64) {
+	for filename, file := range f.index {
+		if isUnder(filename, dirname) {
+			files++
+			bytes += file.storedBytes()
+		}
+	}
+	for filename, pending := range f.pendingUploads {
+		if isUnder(filename, dirname) {
+			files++
+			bytes += pending.bytes
+		}
+	}
+	return files, bytes
+}
+
+/** Counts the reservation while the chunks reported so far take less */
+func (fm *FileMetadata) storedBytes() int64 {
+	if bytes := fm.chunkBytes(); bytes > fm.reserved {
+		return bytes
+	}
+	return fm.reserved // upload still in progress
+}
+
+/** Every replica counts; erasure coded files count their parity chunks */
+func (fm *FileMetadata) chunkBytes() int64 {
+	replication := int64(fm.replication)
+	if replication <= 0 {
+		replication = int64(common.DEFAULT_REPLICATION)
+	}
+	var bytes int64 = 0
+	for _, chunk := range fm.chunks {
+		if chunk.DataShards > 0 {
+			bytes += chunk.Size
+		} else {
+			bytes += chunk.Size * replication
+		}
 	}
+	return bytes
 }
 
 func (f *FileIndexImpl) PrintIndex() {
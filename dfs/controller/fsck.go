@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"adfs/common"
+	ec "adfs/erasure_coding"
+	m "adfs/messages"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+func (c *ControllerImpl) handleFsck(
+	messageHandler *m.MessageHandler,
+	actionRequest *m.ActionRequest,
+) {
+	if actionRequest.Repair && c.safeMode.IsOn() {
+		messageHandler.SendFailAck(common.SAFE_MODE_ERROR_MSG)
+		return
+	}
+	path := actionRequest.FileName
+	scan := c.fileIndex.Fsck(path)
+	report := scan.report
+	if actionRequest.Repair {
+		for _, fsckFile := range report.Files {
+			report.Repairs = append(report.Repairs, c.repairFile(fsckFile, scan.corrupt[fsckFile.FileName])...)
+		}
+	}
+	logrus.WithFields(logrus.Fields{
+		"Path":      path,
+		"Files":     report.FilesChecked,
+		"Unhealthy": len(report.Files),
+		"Repair":    actionRequest.Repair,
+	}).Info("FSCK")
+	messageHandler.SendFsckReport(report)
+}
+
+/** "" and "/" cover the whole namespace; otherwise the file itself or anything in the directory */
+func isUnder(filename, path string) bool {
+	dir := strings.TrimSuffix(path, "/")
+	return dir == "" || filename == dir || strings.HasPrefix(filename, dir+"/")
+}
+
+/** Checks every file under path against the index as the worker sees it */
+func (f *FileIndexImpl) Fsck(path string) *FsckScan {
+	request := &FsckRequest{path, make(chan *FsckScan)}
+	f.fsckCh <- request
+	return <-request.result
+}
+
+/** Runs on the worker: the report only holds copies, so callers may keep it */
+func (f *FileIndexImpl) fsck(path string) *FsckScan {
+	scan := &FsckScan{
+		report:  &m.FsckReport{},
+		corrupt: make(map[string][]*m.Chunk),
+	}
+	for _, file := range f.index {
+		if !isUnder(file.filename, path) {
+			continue
+		}
+		scan.report.FilesChecked++
+		scan.report.ChunksChecked += int64(len(file.chunks))
+		fsckFile := checkFile(file)
+		if isHealthy(fsckFile) {
+			continue
+		}
+		scan.report.Files = append(scan.report.Files, fsckFile)
+		for chunkName, nodes := range file.corrupt {
+			for _, sn := range nodes {
+				scan.corrupt[file.filename] = append(scan.corrupt[file.filename], &m.Chunk{
+					FileName:     file.filename,
+					ChunkName:    chunkName,
+					StorageNodes: map[string]*m.Node{sn.Uuid: proto.Clone(sn).(*m.Node)},
+				})
+			}
+		}
+	}
+	sort.Slice(scan.report.Files, func(i, j int) bool {
+		return scan.report.Files[i].FileName < scan.report.Files[j].FileName
+	})
+	return scan
+}
+
+func checkFile(file *FileMetadata) *m.FsckFile {
+	replication := file.replication
+	if replication <= 0 {
+		replication = common.DEFAULT_REPLICATION
+	}
+	if ec.IsErasureCoded(file.policy) {
+		replication = 1 // parity provides the redundancy
+	}
+	fsckFile := &m.FsckFile{
+		FileName:      file.filename,
+		Replication:   replication,
+		StoragePolicy: file.policy,
+	}
+	serials := make(map[int32]bool)
+	for chunkName, chunk := range file.chunks {
+		if chunk.Serial >= 0 {
+			serials[chunk.Serial] = true
+		}
+		owners := int32(len(chunk.StorageNodes))
+		if owners == 0 {
+			fsckFile.MissingChunks = append(fsckFile.MissingChunks, chunkName)
+		} else if owners < replication {
+			fsckFile.UnderReplicated = append(fsckFile.UnderReplicated, chunkName)
+		} else if owners > replication {
+			fsckFile.OverReplicated = append(fsckFile.OverReplicated, chunkName)
+		}
+	}
+	for serial := int32(0); int64(serial) < file.expectedDataChunks(); serial++ {
+		if !serials[serial] {
+			fsckFile.MissingSerials = append(fsckFile.MissingSerials, serial)
+		}
+	}
+	// parity chunks have no serial; look them up by name
+	for _, chunk := range file.chunks {
+		for stripe := int64(0); stripe < file.expectedStripes(); stripe++ {
+			for p := 0; p < int(chunk.ParityShards); p++ {
+				parityName := file.filename + "-s" + strconv.FormatInt(stripe, 10) + "-p" + strconv.Itoa(p)
+				if _, present := file.chunks[parityName]; !present {
+					fsckFile.MissingChunks = append(fsckFile.MissingChunks, parityName)
+				}
+			}
+		}
+		break
+	}
+	for chunkName, nodes := range file.corrupt {
+		for uuid := range nodes {
+			fsckFile.CorruptReplicas = append(fsckFile.CorruptReplicas, chunkName+"@"+uuid)
+		}
+	}
+	sort.Strings(fsckFile.MissingChunks)
+	sort.Strings(fsckFile.UnderReplicated)
+	sort.Strings(fsckFile.OverReplicated)
+	sort.Strings(fsckFile.CorruptReplicas)
+	return fsckFile
+}
+
+func isHealthy(fsckFile *m.FsckFile) bool {
+	return len(fsckFile.MissingChunks) == 0 &&
+		len(fsckFile.UnderReplicated) == 0 &&
+		len(fsckFile.OverReplicated) == 0 &&
+		len(fsckFile.CorruptReplicas) == 0 &&
+		len(fsckFile.MissingSerials) == 0
+}
+
+/**
+* Deletes corrupt replicas and brings replicated files back to their
+* replication factor. Lost chunks can't be repaired here: they need a healthy
+* copy (or, for erasure coded files, readers rebuild them from parity).
+ */
+func (c *ControllerImpl) repairFile(fsckFile *m.FsckFile, corrupt []*m.Chunk) []string {
+	repairs := make([]string, 0)
+	filename := fsckFile.FileName
+	for _, chunk := range corrupt {
+		for _, sn := range chunk.StorageNodes {
+			c.removeReplica(sn, chunk)
+			repairs = append(repairs, "Deleted corrupt replica "+chunk.ChunkName+" on "+sn.Uuid)
+		}
+	}
+	lost := len(fsckFile.MissingChunks) + len(fsckFile.MissingSerials)
+	if ec.IsErasureCoded(fsckFile.StoragePolicy) {
+		if lost > 0 {
+			repairs = append(repairs, filename+": "+strconv.Itoa(lost)+
+				" chunks missing; readers rebuild them from parity while enough of each stripe is left")
+		}
+		return repairs
+	}
+	if len(fsckFile.UnderReplicated)+len(fsckFile.OverReplicated)+len(fsckFile.CorruptReplicas) > 0 {
+		if f, err := c.fileIndex.Get(filename); err == nil {
+			c.reconcileReplicas(f)
+			repairs = append(repairs, filename+": re-replicating to "+
+				strconv.Itoa(int(fsckFile.Replication))+" copies per chunk")
+		}
+	}
+	if lost > 0 {
+		repairs = append(repairs, filename+": "+strconv.Itoa(lost)+" chunks lost, no copy left to repair from")
+	}
+	return repairs
+}
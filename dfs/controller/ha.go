@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"adfs/common"
+	m "adfs/messages"
+
+	"github.com/sirupsen/logrus"
+)
+
+/**
+* Election traffic is served by every controller; anything else only by the
+* leader. Returns true if the message has been dealt with here.
+ */
+func (c *ControllerImpl) handleElectionMessage(messageHandler *m.MessageHandler, wrapper *m.Wrapper) bool {
+	switch msg := wrapper.Msg.(type) {
+	case *m.Wrapper_VoteRequestMessage:
+		messageHandler.SendVoteResponse(c.election.HandleVoteRequest(msg.VoteRequestMessage))
+		return true
+	case *m.Wrapper_AppendEntriesMessage:
+		messageHandler.SendAppendEntriesResponse(c.election.HandleAppendEntries(msg.AppendEntriesMessage))
+		return true
+	case *m.Wrapper_ActionRequestMessage:
+		if msg.ActionRequestMessage.Type == m.ActionType_WHO_IS_LEADER {
+			messageHandler.SendLeaderAck(c.election.IsLeader(), c.election.GetLeader())
+			return true
+		}
+	case nil:
+		return false
+	}
+	if !c.election.IsLeader() {
+		messageHandler.SendFailAck(common.NOT_LEADER_ERROR_MSG + c.election.GetLeader())
+		return true
+	}
+	return false
+}
+
+/**
+* Records a namespace change so standby controllers can replay it. The change
+* is applied (through applyEdit) once a majority of the controllers store it;
+* an error means it must not be acknowledged.
+ */
+func (c *ControllerImpl) logEdit(actionRequest *m.ActionRequest) error {
+	return c.election.Append(&m.EditLogEntry{
+		Action: &m.ActionRequest{
+			Type:          actionRequest.Type,
+			FileName:      actionRequest.FileName,
+			Replication:   actionRequest.Replication,
+			StoragePolicy: actionRequest.StoragePolicy,
+			FileSize:      actionRequest.FileSize,
+			QuotaFiles:    actionRequest.QuotaFiles,
+			QuotaBytes:    actionRequest.QuotaBytes,
+		},
+	})
+}
+
+/** Every controller, the leader included, applies namespace changes once committed */
+func (c *ControllerImpl) applyEdit(entry *m.EditLogEntry) {
+	if entry.ComputeEngineAddr != "" {
+		c.computeEngineAddr = entry.ComputeEngineAddr
+		return
+	}
+	action := entry.Action
+	if action == nil {
+		return
+	}
+	switch action.Type {
+	case m.ActionType_PUT:
+		policy := action.StoragePolicy
+		if policy == "" {
+			policy = c.fileIndex.GetPolicy(action.FileName)
+		}
+		c.fileIndex.ReserveSlot(action.FileName, action.Replication, uploadBytes(action.FileSize, action.Replication, policy))
+	case m.ActionType_RM:
+		c.fileIndex.Rm(action.FileName)
+	case m.ActionType_SETREP:
+		if err := c.fileIndex.SetReplication(action.FileName, action.Replication); err != nil {
+			logrus.WithFields(logrus.Fields{"Filename": action.FileName, "Error": err.Error()}).Warn("Replication factor not applied")
+		}
+	case m.ActionType_SETPOLICY:
+		c.fileIndex.SetPolicy(action.FileName, action.StoragePolicy)
+	case m.ActionType_SETQUOTA:
+		c.fileIndex.SetQuota(action.FileName, action.QuotaFiles, action.QuotaBytes)
+	}
+	logrus.WithFields(logrus.Fields{
+		"Index":    entry.Index,
+		"Type":     action.Type,
+		"Filename": action.FileName,
+	}).Info("Applied edit log entry")
+}
+
+/** Background work that changes the cluster only runs on the leader */
+func (c *ControllerImpl) onLeaderChange(isLeader bool) {
+	if isLeader {
+		// only the old leader had the block reports; wait for ours
+		c.safeMode.Enter(false)
+		c.balancer.Start()
+	} else {
+		c.balancer.Stop()
+	}
+}
@@ -1,24 +1,64 @@
 package controller
 
 import (
+	"adfs/helpers"
 	s "adfs/server"
+	"net"
+	"strconv"
 )
 
 type Config struct {
-	Port int
+	Port              int
+	BalancerBandwidth int64    // bytes moved per balancer round; 0 disables it
+	SafeModeThreshold float64  // fraction of chunks reported before leaving safe mode; 0 disables it
+	Controllers       []string // hostname:port of every controller (this one included) for HA
+	EditLog           string   // file persisting the edit log; empty keeps it in memory
 }
 
 func Init(config Config) {
+	if err := validateControllers(config.Controllers); err != nil {
+		panic(err)
+	}
 	server, err := s.NewServerAt(config.Port)
 	if err != nil {
 		panic(err)
 	}
 	fileIndex := NewFileIndex()
 	zookeeper := NewZookeeper()
+	safeMode := NewSafeMode(fileIndex, config.SafeModeThreshold)
+	balancer := NewBalancer(zookeeper, fileIndex, safeMode, config.BalancerBandwidth)
+	editLog, err := NewEditLog(config.EditLog)
+	if err != nil {
+		panic(err)
+	}
+	self := helpers.GetAddr(server.GetHostname(), server.GetPort())
+	peers := make([]string, 0)
+	for _, addr := range config.Controllers {
+		if !isSelf(addr, server) {
+			peers = append(peers, addr)
+		}
+	}
+	election := NewElection(self, peers, editLog)
 	controller := NewController(ControllerConfig{
 		Server:    server,
 		Zookeeper: zookeeper,
 		FileIndex: fileIndex,
+		Balancer:  balancer,
+		Election:  election,
+		SafeMode:  safeMode,
 	})
 	controller.Start()
 }
+
+/** addr names this controller if it has our port and a local host */
+func isSelf(addr string, server s.Server) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || port != strconv.Itoa(server.GetPort()) {
+		return false
+	}
+	switch host {
+	case "", "localhost", "127.0.0.1", "::1", server.GetHostname():
+		return true
+	}
+	return false
+}
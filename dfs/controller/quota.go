@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"adfs/common"
+	ec "adfs/erasure_coding"
+	m "adfs/messages"
+)
+
+func (c *ControllerImpl) handleSetQuota(
+	messageHandler *m.MessageHandler,
+	actionRequest *m.ActionRequest,
+) {
+	dirname := actionRequest.FileName
+	if dirname == "" || dirname[0] != '/' {
+		messageHandler.SendFailAck("Directory must be an absolute path")
+		return
+	}
+	if actionRequest.QuotaFiles < 0 || actionRequest.QuotaBytes < 0 {
+		messageHandler.SendFailAck("Quotas can't be negative")
+		return
+	}
+	if err := c.logEdit(actionRequest); err != nil {
+		messageHandler.SendFailAck(err.Error())
+		return
+	}
+	messageHandler.SendSuccessAck()
+}
+
+/** Space a file of size bytes takes once stored under the given replication and policy */
+func uploadBytes(size int64, replication int32, policy string) int64 {
+	if dataShards, parityShards, err := ec.ParsePolicy(policy); err == nil {
+		return size * int64(dataShards+parityShards) / int64(dataShards)
+	}
+	if replication <= 0 {
+		replication = common.DEFAULT_REPLICATION
+	}
+	return size * int64(replication)
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"adfs/common"
+	ec "adfs/erasure_coding"
+	"adfs/helpers"
+	m "adfs/messages"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+/** 0 means "use the default", anything else must be within bounds */
+func validateReplication(replication int32) error {
+	if replication < 0 || replication > common.MAX_REPLICATION {
+		return errors.New("Replication factor must be between 1 and " +
+			strconv.Itoa(int(common.MAX_REPLICATION)))
+	}
+	return nil
+}
+
+/** Each shard of a stripe goes to a different node, so a node failure costs a stripe one shard at most */
+func validatePlacement(policy string, nodes int) error {
+	dataShards, parityShards, err := ec.ParsePolicy(policy)
+	if err != nil || nodes >= dataShards+parityShards {
+		return nil
+	}
+	return errors.New(policy + " needs " + strconv.Itoa(dataShards+parityShards) +
+		" Storage Nodes with free space, only " + strconv.Itoa(nodes) + " available")
+}
+
+/**
+* Brings every chunk of file to file.Replication copies: missing copies are
+* pushed from a current owner to online nodes that don't hold the chunk yet,
+* surplus copies are deleted from the owners whose uuids sort last.
+ */
+func (c *ControllerImpl) reconcileReplicas(file *m.File) {
+	replication := int(file.Replication)
+	if replication <= 0 {
+		replication = int(common.DEFAULT_REPLICATION)
+	}
+	for _, chunk := range file.Chunks {
+		owners := make([]*m.Node, 0)
+		for _, sn := range chunk.StorageNodes {
+			owners = append(owners, sn)
+		}
+		// map order is random; trimming must not depend on it
+		sort.Slice(owners, func(i, j int) bool { return owners[i].Uuid < owners[j].Uuid })
+		if len(owners) == 0 {
+			logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName}).Error("No replicas left to copy from")
+			continue
+		}
+		if len(owners) < replication {
+			targets := c.getReplicaTargets(chunk, replication-len(owners))
+			c.replicateChunk(owners[0], chunk.ChunkName, int32(replication), targets)
+		} else if len(owners) > replication {
+			for _, sn := range owners[replication:] {
+				c.removeReplica(sn, chunk)
+			}
+		}
+	}
+}
+
+/**
+* Online nodes that can take a new copy. They must not hold the chunk, nor
+* (erasure coded files) another chunk of its stripe.
+ */
+func (c *ControllerImpl) getReplicaTargets(chunk *m.Chunk, n int) []*m.Node {
+	excluded := make(map[string]bool)
+	for uuid := range chunk.StorageNodes {
+		excluded[uuid] = true
+	}
+	if chunk.DataShards > 0 {
+		for _, stripeChunk := range c.fileIndex.GetStripe(chunk.FileName, chunk.Stripe) {
+			for uuid := range stripeChunk.StorageNodes {
+				excluded[uuid] = true
+			}
+		}
+	}
+	targets := make([]*m.Node, 0)
+	for _, zn := range c.zookeeper.GetPlacementNodes() {
+		if len(targets) == n {
+			break
+		}
+		if excluded[zn.Uuid] {
+			continue
+		}
+		targets = append(targets, &m.Node{
+			Uuid:     zn.Uuid,
+			Hostname: zn.Hostname,
+			Port:     int32(zn.Port),
+		})
+	}
+	return targets
+}
+
+func (c *ControllerImpl) replicateChunk(
+	source *m.Node,
+	chunkName string,
+	replication int32,
+	targets []*m.Node,
+) {
+	if len(targets) == 0 {
+		logrus.WithFields(logrus.Fields{"Chunk": chunkName}).Warn("Not enough Storage Nodes to replicate chunk")
+		return
+	}
+	msgHandler, err := m.GetMessageHandlerFor(helpers.GetAddr(source.Hostname, int(source.Port)))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"Chunk": chunkName, "Error": err.Error()}).Error("Could not reach replica owner")
+		return
+	}
+	defer msgHandler.Close()
+	msgHandler.SendReplicateRequest(chunkName, replication, targets)
+	logrus.WithFields(logrus.Fields{"Chunk": chunkName, "Copies": len(targets)}).Info("Re-replicating chunk")
+}
+
+func (c *ControllerImpl) removeReplica(sn *m.Node, chunk *m.Chunk) {
+	msgHandler, err := m.GetMessageHandlerFor(helpers.GetAddr(sn.Hostname, int(sn.Port)))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName, "Error": err.Error()}).Error("Could not reach replica owner")
+		return
+	}
+	msgHandler.SendChunkRemoveRequest(chunk.ChunkName)
+	msgHandler.Close()
+	c.fileIndex.RmReplica(chunk.FileName, chunk.ChunkName, sn.Uuid)
+	logrus.WithFields(logrus.Fields{"Chunk": chunk.ChunkName, "UUID": sn.Uuid}).Info("Removed replica")
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	m "adfs/messages"
+	"adfs/storageNode"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const SAFE_MODE_CHECK_DELAY_S = 1
+
+// give every live storage node a couple of heartbeats to register before leaving
+const SAFE_MODE_MIN_S = storageNode.HEARTBEAT_DELAY_S * 2
+const DEFAULT_SAFE_MODE_THRESHOLD = 0.999
+
+const SAFE_MODE_AUTO = "auto"
+const SAFE_MODE_MANUAL = "manual"
+const SAFE_MODE_OFF = "off"
+
+/**
+* While on, the namespace is read-only and nothing is re-replicated: the
+* FileIndex is still being rebuilt from block reports, so chunks that look
+* missing may just not have been reported yet. Entered automatically when the
+* controller becomes leader and left once threshold of the expected chunks
+* have been reported. Admins can enter it manually; then only they can leave.
+ */
+type SafeMode interface {
+	Start()
+	Stop()
+	IsOn() bool
+	Enter(manual bool)
+	Leave()
+	GetStatus() *m.SafeMode
+}
+
+type SafeModeImpl struct {
+	on        bool
+	manual    bool
+	since     time.Time
+	mutex     sync.Mutex // on, manual and since are read from connection goroutines
+	threshold float64
+	fileIndex FileIndex
+	statusCh  chan string
+	scheduler *time.Ticker
+	quit      chan bool
+}
+
+/** threshold is the fraction of chunks to be reported; 0 never enters safe mode automatically */
+func NewSafeMode(fileIndex FileIndex, threshold float64) SafeMode {
+	return &SafeModeImpl{
+		threshold: threshold,
+		fileIndex: fileIndex,
+		statusCh:  make(chan string),
+		quit:      make(chan bool),
+	}
+}
+
+func (sm *SafeModeImpl) Start() {
+	sm.scheduler = time.NewTicker(SAFE_MODE_CHECK_DELAY_S * time.Second)
+	go sm.worker()
+}
+
+func (sm *SafeModeImpl) Stop() {
+	sm.scheduler.Stop()
+	sm.quit <- true
+}
+
+func (sm *SafeModeImpl) worker() {
+	for {
+		select {
+		case <-sm.quit:
+			return
+		case status := <-sm.statusCh:
+			sm.handleStatus(status)
+		case <-sm.scheduler.C:
+			sm.checkReported()
+		}
+	}
+}
+
+func (sm *SafeModeImpl) handleStatus(status string) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	switch status {
+	case SAFE_MODE_AUTO:
+		if sm.threshold <= 0 || sm.manual {
+			return
+		}
+		sm.on = true
+		sm.since = time.Now()
+	case SAFE_MODE_MANUAL:
+		sm.on = true
+		sm.manual = true
+		sm.since = time.Now()
+	case SAFE_MODE_OFF:
+		sm.on = false
+		sm.manual = false
+	}
+	logrus.WithFields(logrus.Fields{
+		"On":     sm.on,
+		"Manual": sm.manual,
+	}).Info("Safe mode updated")
+}
+
+func (sm *SafeModeImpl) checkReported() {
+	sm.mutex.Lock()
+	waiting := sm.on && !sm.manual && time.Since(sm.since).Seconds() >= SAFE_MODE_MIN_S
+	sm.mutex.Unlock()
+	if !waiting {
+		return
+	}
+	reported, expected := sm.fileIndex.ChunkReport()
+	if fractionOf(reported, expected) < sm.threshold {
+		return
+	}
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	sm.on = false
+	logrus.WithFields(logrus.Fields{
+		"Reported": reported,
+		"Expected": expected,
+	}).Info("Leaving safe mode")
+}
+
+func fractionOf(reported, expected int64) float64 {
+	if expected == 0 {
+		return 1
+	}
+	return float64(reported) / float64(expected)
+}
+
+func (sm *SafeModeImpl) IsOn() bool {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	return sm.on
+}
+
+func (sm *SafeModeImpl) Enter(manual bool) {
+	if manual {
+		sm.statusCh <- SAFE_MODE_MANUAL
+	} else {
+		sm.statusCh <- SAFE_MODE_AUTO
+	}
+}
+
+func (sm *SafeModeImpl) Leave() {
+	sm.statusCh <- SAFE_MODE_OFF
+}
+
+func (sm *SafeModeImpl) GetStatus() *m.SafeMode {
+	reported, expected := sm.fileIndex.ChunkReport()
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+	return &m.SafeMode{
+		On:             sm.on,
+		Manual:         sm.manual,
+		ReportedChunks: reported,
+		ExpectedChunks: expected,
+		Threshold:      sm.threshold,
+	}
+}
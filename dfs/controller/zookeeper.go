@@ -1,9 +1,11 @@
 package controller
 
 import (
+	"adfs/common"
 	m "adfs/messages"
 	"adfs/storageNode"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -11,23 +13,33 @@ import (
 
 const ONLINE = "online"
 const OFFLINE = "offline"
+const DRAIN = "drain"
 const SHUTDOWN = "stopWorker"
 const FAILURE_DETECTOR_DELAY_S = 5
 
+// a node that stays away longer is forgotten and treated as new if it comes back
+const DEPARTED_NODE_TTL = 24 * time.Hour
+
 type Zookeeper interface {
 	Start()
 	Stop()
 	GetNodes() []*ZNode
+	GetPlacementNodes() []*ZNode
+	GetNode(uuid string) (*ZNode, bool)
 	Heartbeat(uuid string, stats *m.Stats)
 	FailureDetector()
 	StopFailureDetector()
 	RegisterNode(node *m.Node)
+	KnowsNode(uuid string) bool
 	NodeDown(uuid string)
+	UpdateDrainState(uuid, state string, chunksToDrain int64)
 	AddListenerOnNodeDown(onNodeDown func(nodeUuid string))
 }
 
 type ZookeeperImpl struct {
 	heartbeats          map[string]*ZNode
+	departed            map[string]time.Time // [uuid] when the node went down; lets rejoining nodes be recognized
+	mutex               sync.RWMutex         // written by the worker, read from connection goroutines
 	nodeStatusCh        chan *ZNode
 	zookeeperStatusCh   chan string
 	heartbeatsDone      chan bool
@@ -36,17 +48,20 @@ type ZookeeperImpl struct {
 }
 
 type ZNode struct {
-	Uuid     string
-	Time     time.Time
-	Status   string
-	Hostname string
-	Port     int
-	Stats    *m.Stats
+	Uuid          string
+	Time          time.Time
+	Status        string
+	Hostname      string
+	Port          int
+	Stats         *m.Stats
+	State         string // common.NODE_ONLINE/NODE_DRAINING/NODE_DECOMMISSIONED
+	ChunksToDrain int64
 }
 
 func NewZookeeper() Zookeeper {
 	return &ZookeeperImpl{
 		heartbeats:          make(map[string]*ZNode),
+		departed:            make(map[string]time.Time),
 		nodeStatusCh:        make(chan *ZNode),
 		zookeeperStatusCh:   make(chan string),
 		onNodeDownListeners: make([]func(nodeUuid string), 0),
@@ -69,6 +84,9 @@ func (z *ZookeeperImpl) init() {
 	if z.heartbeats == nil {
 		z.heartbeats = make(map[string]*ZNode)
 	}
+	if z.departed == nil {
+		z.departed = make(map[string]time.Time)
+	}
 	if z.onNodeDownListeners == nil {
 		z.onNodeDownListeners = make([]func(nodeUuid string), 0)
 	}
@@ -103,17 +121,30 @@ func (z *ZookeeperImpl) stopWorker() {
 
 func (z *ZookeeperImpl) checkHeartbeats() {
 	logrus.Info("Checking for failed storage nodes...")
+	down := make([]string, 0)
+	z.mutex.Lock()
 	for uuid, node := range z.heartbeats {
 		diff := time.Now().Sub(node.Time)
 		if diff.Seconds() > storageNode.HEARTBEAT_DELAY_S*2 {
 			delete(z.heartbeats, uuid)
-			for _, f := range z.onNodeDownListeners {
-				f(uuid)
-			}
-			logrus.WithFields(logrus.Fields{
-				"UUID": uuid,
-			}).Error("ZNode is DOWN!")
+			z.departed[uuid] = time.Now()
+			down = append(down, uuid)
+		}
+	}
+	for uuid, departedAt := range z.departed {
+		if time.Since(departedAt) > DEPARTED_NODE_TTL {
+			delete(z.departed, uuid)
+		}
+	}
+	z.mutex.Unlock()
+	// listeners may read the zookeeper back
+	for _, uuid := range down {
+		for _, f := range z.onNodeDownListeners {
+			f(uuid)
 		}
+		logrus.WithFields(logrus.Fields{
+			"UUID": uuid,
+		}).Error("ZNode is DOWN!")
 	}
 }
 
@@ -126,11 +157,21 @@ func (z *ZookeeperImpl) closeZookeeper(status string) bool {
 }
 
 func (z *ZookeeperImpl) handleNodeStatus(node *ZNode) {
+	z.mutex.Lock()
+	defer z.mutex.Unlock()
 	uuid := node.Uuid
 	status := node.Status
 	if status == ONLINE {
 		n, present := z.heartbeats[uuid]
-		if present {
+		if present && node.Hostname != "" {
+			// re-registering before the failure detector noticed the restart
+			n.Hostname = node.Hostname
+			n.Port = node.Port
+			n.Time = node.Time
+			logrus.WithFields(logrus.Fields{
+				"UUID": strings.TrimSpace(uuid),
+			}).Info("Re-registered Online ZNode")
+		} else if present {
 			// heartbeat!
 			n.Time = node.Time
 			logrus.WithFields(logrus.Fields{
@@ -141,14 +182,29 @@ func (z *ZookeeperImpl) handleNodeStatus(node *ZNode) {
 			}
 		} else {
 			// registering!
+			delete(z.departed, uuid)
+			if node.State == "" {
+				node.State = common.NODE_ONLINE
+			}
 			z.heartbeats[uuid] = node
 			logrus.WithFields(logrus.Fields{
 				"UUID": strings.TrimSpace(uuid),
 			}).Info("Registered Online ZNode")
 		}
+	} else if status == DRAIN {
+		if n, present := z.heartbeats[uuid]; present {
+			n.State = node.State
+			n.ChunksToDrain = node.ChunksToDrain
+			logrus.WithFields(logrus.Fields{
+				"UUID":          uuid,
+				"State":         node.State,
+				"ChunksToDrain": node.ChunksToDrain,
+			}).Info("ZNode decommissioning")
+		}
 	} else if status == OFFLINE {
 		// StorageIO ZNode is down
 		delete(z.heartbeats, uuid)
+		z.departed[uuid] = time.Now()
 		logrus.WithFields(logrus.Fields{
 			"UUID": uuid,
 		}).Info("StorageIO ZNode down")
@@ -161,14 +217,59 @@ func (z *ZookeeperImpl) updateNodeStatus(nodeStatus *ZNode) {
 	z.nodeStatusCh <- nodeStatus
 }
 
+/** Nodes handed out are copies; the worker keeps updating its own */
 func (z *ZookeeperImpl) GetNodes() []*ZNode {
+	z.mutex.RLock()
+	defer z.mutex.RUnlock()
 	nodes := make([]*ZNode, 0)
 	for _, node := range z.heartbeats {
-		nodes = append(nodes, node)
+		nodes = append(nodes, node.copy())
 	}
 	return nodes
 }
 
+/** Nodes that can take new chunks: online, not being decommissioned and not full */
+func (z *ZookeeperImpl) GetPlacementNodes() []*ZNode {
+	z.mutex.RLock()
+	defer z.mutex.RUnlock()
+	nodes := make([]*ZNode, 0)
+	for _, node := range z.heartbeats {
+		if node.State == common.NODE_ONLINE && !node.IsFull() {
+			nodes = append(nodes, node.copy())
+		}
+	}
+	return nodes
+}
+
+func (zn *ZNode) copy() *ZNode {
+	copied := *zn
+	return &copied
+}
+
+/** Full means less than a chunk left above the node's reserve. Unknown capacity never is */
+func (zn *ZNode) IsFull() bool {
+	return zn.Stats != nil && zn.Stats.Capacity > 0 && zn.Stats.Available < common.CHUNK_SIZE
+}
+
+func (z *ZookeeperImpl) GetNode(uuid string) (*ZNode, bool) {
+	z.mutex.RLock()
+	defer z.mutex.RUnlock()
+	node, present := z.heartbeats[uuid]
+	if !present {
+		return nil, false
+	}
+	return node.copy(), true
+}
+
+/** True if the node is online or went down less than DEPARTED_NODE_TTL ago (e.g. restarting) */
+func (z *ZookeeperImpl) KnowsNode(uuid string) bool {
+	z.mutex.RLock()
+	defer z.mutex.RUnlock()
+	_, online := z.heartbeats[uuid]
+	_, departed := z.departed[uuid]
+	return online || departed
+}
+
 func (z *ZookeeperImpl) NodeDown(uuid string) {
 	nodeStatus := &ZNode{
 		Uuid:   uuid,
@@ -177,6 +278,16 @@ func (z *ZookeeperImpl) NodeDown(uuid string) {
 	z.updateNodeStatus(nodeStatus)
 }
 
+func (z *ZookeeperImpl) UpdateDrainState(uuid, state string, chunksToDrain int64) {
+	nodeStatus := &ZNode{
+		Uuid:          uuid,
+		Status:        DRAIN,
+		State:         state,
+		ChunksToDrain: chunksToDrain,
+	}
+	z.updateNodeStatus(nodeStatus)
+}
+
 func (z *ZookeeperImpl) RegisterNode(node *m.Node) {
 	nodeStatus := &ZNode{
 		Uuid:     node.Uuid,
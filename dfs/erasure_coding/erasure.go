@@ -0,0 +1,143 @@
+package erasure_coding
+
+import (
+	m "adfs/messages"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/reedsolomon"
+)
+
+const REPLICATED_POLICY = "replicated"
+const EC_POLICY_PREFIX = "RS-"
+
+// default erasure coding policy: 6 data chunks + 3 parity chunks per stripe
+const DEFAULT_EC_POLICY = "RS-6-3"
+
+/**
+* Reed-Solomon coder for stripes of chunks. Data shards are stored unpadded,
+* so callers pad them to the stripe shard size before reconstructing.
+ */
+type Coder interface {
+	Encode(data [][]byte) (parity [][]byte, shardSize int, err error)
+	Reconstruct(shards [][]byte) error
+	DataShards() int
+	ParityShards() int
+}
+
+type CoderImpl struct {
+	dataShards   int
+	parityShards int
+	encoder      reedsolomon.Encoder
+}
+
+func NewCoder(dataShards, parityShards int) (Coder, error) {
+	encoder, err := reedsolomon.New(dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	return &CoderImpl{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		encoder:      encoder,
+	}, nil
+}
+
+/** Builds the coder described by an "RS-<data>-<parity>" policy */
+func NewCoderFor(policy string) (Coder, error) {
+	dataShards, parityShards, err := ParsePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	return NewCoder(dataShards, parityShards)
+}
+
+func (c *CoderImpl) DataShards() int {
+	return c.dataShards
+}
+
+func (c *CoderImpl) ParityShards() int {
+	return c.parityShards
+}
+
+/**
+* Computes parity shards for up to DataShards() data shards. Missing data
+* shards (last stripe of a file) are treated as zero-filled.
+ */
+func (c *CoderImpl) Encode(data [][]byte) ([][]byte, int, error) {
+	if len(data) == 0 || len(data) > c.dataShards {
+		return nil, 0, errors.New("stripe must have between 1 and " + strconv.Itoa(c.dataShards) + " data chunks")
+	}
+	shardSize := 0
+	for _, d := range data {
+		if len(d) > shardSize {
+			shardSize = len(d)
+		}
+	}
+	shards := make([][]byte, c.dataShards+c.parityShards)
+	for i := range shards {
+		shards[i] = make([]byte, shardSize)
+		if i < len(data) {
+			copy(shards[i], data[i])
+		}
+	}
+	if err := c.encoder.Encode(shards); err != nil {
+		return nil, 0, err
+	}
+	return shards[c.dataShards:], shardSize, nil
+}
+
+/** Fills in the nil shards; at least DataShards() shards must be present */
+func (c *CoderImpl) Reconstruct(shards [][]byte) error {
+	if len(shards) != c.dataShards+c.parityShards {
+		return errors.New("unexpected number of shards in stripe")
+	}
+	return c.encoder.ReconstructData(shards)
+}
+
+/** Empty policy means "not specified"; both it and "replicated" aren't erasure coded */
+func IsErasureCoded(policy string) bool {
+	return strings.HasPrefix(policy, EC_POLICY_PREFIX)
+}
+
+func ValidatePolicy(policy string) error {
+	if policy == "" || policy == REPLICATED_POLICY {
+		return nil
+	}
+	_, _, err := ParsePolicy(policy)
+	return err
+}
+
+func ParsePolicy(policy string) (int, int, error) {
+	invalid := errors.New("invalid storage policy " + policy + ". Expected " + REPLICATED_POLICY + " or RS-<data>-<parity>")
+	if !IsErasureCoded(policy) {
+		return 0, 0, invalid
+	}
+	parts := strings.Split(strings.TrimPrefix(policy, EC_POLICY_PREFIX), "-")
+	if len(parts) != 2 {
+		return 0, 0, invalid
+	}
+	dataShards, err := strconv.Atoi(parts[0])
+	if err != nil || dataShards <= 0 {
+		return 0, 0, invalid
+	}
+	parityShards, err := strconv.Atoi(parts[1])
+	if err != nil || parityShards <= 0 {
+		return 0, 0, invalid
+	}
+	return dataShards, parityShards, nil
+}
+
+/** Policy a file was stored with, derived from any of its chunks */
+func PolicyOf(dataShards, parityShards int32) string {
+	if dataShards == 0 {
+		return REPLICATED_POLICY
+	}
+	return EC_POLICY_PREFIX + strconv.Itoa(int(dataShards)) + "-" + strconv.Itoa(int(parityShards))
+}
+
+/** Parity chunks hold no file data: skip them when merging or computing */
+func IsParity(chunk *m.Chunk) bool {
+	return chunk.DataShards > 0 && chunk.StripeIndex >= chunk.DataShards
+}
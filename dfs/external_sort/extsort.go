@@ -1,12 +1,10 @@
 package extsort
 
 import (
-	"bufio"
 	"io"
 	"os"
 	"sort"
 	"strconv"
-	"strings"
 )
 
 // max number of lines kept in memory
@@ -41,7 +39,7 @@ func (es *ExtSortImpl) Sort(filePath string) error {
 	lines := []Pair{}
 	i := 0
 	tempChunkPaths := []string{}
-	scanner := bufio.NewScanner(file)
+	scanner := NewScanner(file)
 	for {
 		hasNextLine := scanner.Scan()
 		if i == NUM_LINES_IN_MEMORY || !hasNextLine {
@@ -59,69 +57,35 @@ func (es *ExtSortImpl) Sort(filePath string) error {
 				l := line.key + "\t" + line.value + "\n"
 				tempChunk.Write([]byte(l))
 			}
+			tempChunk.Close()
 			lines = []Pair{}
 			i = 0
-		} else {
-			line := scanner.Text()
-			if line != "" {
-				lines = append(lines, getPair(line))
-			}
-			i++
 		}
 
 		if !hasNextLine {
 			break
 		}
+		// the line that triggered a spill belongs to the next chunk
+		line := scanner.Text()
+		if line != "" {
+			lines = append(lines, getPair(line))
+		}
+		i++
 	}
 	file.Close()
-
-	/** merge sorted chunks */
-	/* Maps a mapper output file scanner to the next key in the file */
-	mergeMap := make(map[*bufio.Scanner]string)
-	files := []*os.File{}
-	/* Create a scanner for each mapper output file and read the first line */
-	for _, filename := range tempChunkPaths {
-		file, err := os.Open(filename)
-		files = append(files, file)
-		if err != nil {
-			return err
-		}
-		scanner := bufio.NewScanner(file)
-		scanner.Scan()
-		mergeMap[scanner] = scanner.Text()
+	if err := scanner.Err(); err != nil {
+		return err
 	}
 
-	// What should we name the intermediary file where the merge results will go? Where should we store this file?
+	/** merge sorted chunks */
 	mergedFile, err := os.Create(filePath)
 	if err != nil {
 		return err
 	}
-	/* Merge the Mapper output files into a single file */
-	for len(mergeMap) != 0 {
-		var smallestScanner *bufio.Scanner
-		var smallestText string
-		for scanner, text := range mergeMap {
-			if smallestText == "" || strings.Compare(text, smallestText) <= 0 {
-				smallestText = text
-				smallestScanner = scanner
-			}
-		}
-		if smallestText != "" {
-			mergedFile.WriteString(smallestText + "\n")
-		}
-		if smallestScanner.Scan() {
-			/* There are more lines in this file, update mergeMap */
-			mergeMap[smallestScanner] = smallestScanner.Text()
-		} else {
-			/* There are no more lines in this file, remove it from mergeMap */
-			delete(mergeMap, smallestScanner)
-		}
-	}
+	err = MergeFiles(tempChunkPaths, mergedFile)
 	mergedFile.Close()
-
-	/** close files */
-	for _, file := range files {
-		file.Close()
+	if err != nil {
+		return err
 	}
 
 	/** remove temp files */
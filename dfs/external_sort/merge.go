@@ -0,0 +1,115 @@
+package extsort
+
+import (
+	"bufio"
+	"container/heap"
+	"io"
+	"os"
+	"strings"
+)
+
+const SCANNER_BUFFER_SIZE = 64 * 1024
+
+// reducer input lines carry a key with all its values, so they can get long
+const MAX_LINE_SIZE = 64 * 1024 * 1024
+
+/** Scanner that accepts lines up to MAX_LINE_SIZE instead of bufio's 64KB */
+func NewScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, SCANNER_BUFFER_SIZE), MAX_LINE_SIZE)
+	return scanner
+}
+
+/** Opens the sorted files and merges them into out */
+func MergeFiles(filePaths []string, out io.Writer) error {
+	readers := make([]io.Reader, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+	return Merge(readers, out)
+}
+
+/**
+* k-way merge of inputs whose lines are already sorted by key (the text
+* before the first tab). A heap holds the next line of every input, so each
+* output line costs O(log k) rather than a scan over all k inputs. Empty
+* lines aren't records and are dropped, but empty keys are kept; lines with
+* the same key keep input order.
+ */
+func Merge(readers []io.Reader, out io.Writer) error {
+	writer := bufio.NewWriterSize(out, SCANNER_BUFFER_SIZE)
+	sources := make(mergeHeap, 0, len(readers))
+	for i, reader := range readers {
+		source := &mergeSource{scanner: NewScanner(reader), index: i}
+		if source.next() {
+			sources = append(sources, source)
+		} else if err := source.scanner.Err(); err != nil {
+			return err
+		}
+	}
+	heap.Init(&sources)
+	for sources.Len() > 0 {
+		smallest := sources[0]
+		if _, err := writer.WriteString(smallest.line + "\n"); err != nil {
+			return err
+		}
+		if smallest.next() {
+			heap.Fix(&sources, 0)
+			continue
+		}
+		if err := smallest.scanner.Err(); err != nil {
+			return err
+		}
+		heap.Pop(&sources)
+	}
+	return writer.Flush()
+}
+
+type mergeSource struct {
+	scanner *bufio.Scanner
+	line    string // next line to be merged
+	key     string
+	index   int
+}
+
+/** Moves to the next non empty line; false once the input is exhausted */
+func (ms *mergeSource) next() bool {
+	for ms.scanner.Scan() {
+		if ms.line = ms.scanner.Text(); ms.line != "" {
+			ms.key = ms.line
+			if i := strings.IndexByte(ms.line, '\t'); i >= 0 {
+				ms.key = ms.line[:i]
+			}
+			return true
+		}
+	}
+	return false
+}
+
+type mergeHeap []*mergeSource
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if h[i].key != h[j].key {
+		return h[i].key < h[j].key
+	}
+	return h[i].index < h[j].index
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x any) { *h = append(*h, x.(*mergeSource)) }
+
+func (h *mergeHeap) Pop() any {
+	old := *h
+	n := len(old)
+	source := old[n-1]
+	*h = old[:n-1]
+	return source
+}
@@ -0,0 +1,99 @@
+package extsort
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func merge(t *testing.T, inputs ...string) string {
+	t.Helper()
+	readers := make([]io.Reader, len(inputs))
+	for i, input := range inputs {
+		readers[i] = strings.NewReader(input)
+	}
+	var out bytes.Buffer
+	if err := Merge(readers, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestMergeSortsByKey(t *testing.T) {
+	got := merge(t, "a\t1\nc\t1\n", "b\t1\nd\t1\n", "", "ab\t1\n")
+	want := "a\t1\nab\t1\nb\t1\nc\t1\nd\t1\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMergeKeepsEmptyKeys(t *testing.T) {
+	got := merge(t, "\tv1\na\t1\n", "\tv2\nb\t2\n")
+	want := "\tv1\n\tv2\na\t1\nb\t2\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMergeDropsEmptyLines(t *testing.T) {
+	got := merge(t, "\na\t1\n\n\nb\t1\n\n", "\n")
+	want := "a\t1\nb\t1\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMergeTiesKeepInputOrder(t *testing.T) {
+	// spills are sorted by key only, so values of a key come in any order
+	got := merge(t, "k\t3\nk\t1\nz\t0\n", "k\t2\n", "a\t0\nk\t0\n")
+	want := "a\t0\nk\t3\nk\t1\nk\t2\nk\t0\nz\t0\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMergeFilesFailsOnMissingInput(t *testing.T) {
+	dir := t.TempDir()
+	present := filepath.Join(dir, "present")
+	os.WriteFile(present, []byte("a\t1\n"), 0644)
+	var out bytes.Buffer
+	if err := MergeFiles([]string{present, filepath.Join(dir, "missing")}, &out); err == nil {
+		t.Error("merging a missing file did not fail")
+	}
+}
+
+/** inputs sorted files of lines key<TAB>value each, keys spread over all of them */
+func writeSortedInputs(b *testing.B, inputs, lines int) []string {
+	b.Helper()
+	dir := b.TempDir()
+	filePaths := make([]string, inputs)
+	for i := range filePaths {
+		var content strings.Builder
+		for l := 0; l < lines; l++ {
+			fmt.Fprintf(&content, "key%08d\t%d\n", l*inputs+i, i)
+		}
+		filePaths[i] = filepath.Join(dir, fmt.Sprintf("input-%d", i))
+		if err := os.WriteFile(filePaths[i], []byte(content.String()), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return filePaths
+}
+
+func BenchmarkMergeFiles(b *testing.B) {
+	for _, inputs := range []int{100, 500} {
+		b.Run(fmt.Sprintf("inputs=%d", inputs), func(b *testing.B) {
+			filePaths := writeSortedInputs(b, inputs, 1000)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := MergeFiles(filePaths, io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -4,10 +4,16 @@ go 1.19
 
 require (
 	github.com/common-nighthawk/go-figure v0.0.0-20210622060536-734e95fb86be
+	github.com/golang/snappy v0.0.4
+	github.com/klauspost/compress v1.15.15
+	github.com/klauspost/reedsolomon v1.11.8
 	github.com/manifoldco/promptui v0.9.0
 	github.com/sirupsen/logrus v1.9.0
 	golang.org/x/sys v0.0.0-20220811171246-fbc7d0a398ab
 	google.golang.org/protobuf v1.28.1
 )
 
-require github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
+require (
+	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
+	github.com/klauspost/cpuid/v2 v2.1.1 // indirect
+)
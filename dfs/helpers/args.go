@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
 const VERBOSE_FLAG = "--verbose"
@@ -20,9 +21,16 @@ const COMPUTE_ENGINE_APP = "compute-engine"
 
 // dir where data is going to be persisted
 const STORAGE_NODE_DIR = "--storage-dir"
+const STORAGE_BACKEND_FLAG = "--storage-backend"
 const PLUGINS_DIR = "--plugins-dir"
 const COMPUTE_STORAGE_DIR = "--compute-storage-dir"
 
+// keyfile with the cluster master key; enables encryption at rest
+const MASTER_KEY_FILE_FLAG = "--master-key-file"
+
+// MB kept free on every storage dir; chunks are refused below it
+const RESERVED_SPACE_FLAG = "--reserved-space"
+
 // port flag for specified app:
 // * controller
 // * storage node
@@ -32,9 +40,35 @@ const PORT_FLAG = "--port"
 // controller addr to connect to
 const CONTROLLER_HOSTNAME_FLAG = "--hostname"
 const CONTROLLER_PORT_FLAG = "--host-port"
+
+// several controllers (leader + standbys), an odd number: --controllers host1:port1,host2:port2,host3:port3
+const CONTROLLERS_FLAG = "--controllers"
+const EDIT_LOG_FLAG = "--edit-log"
 const COMPUTE_ENGINE_HOSTNAME_FLAG = "--compute-engine-hostname"
 const COMPUTE_ENGINE_PORT_FLAG = "--compute-engine-port"
 
+// client flags:
+// default replication factor for uploaded files
+const REPLICATION_FLAG = "--replication"
+
+// default compression codec for uploaded files
+const COMPRESSION_FLAG = "--compression"
+
+// controller: MB the balancer may move per round, 0 disables it
+const BALANCER_BANDWIDTH_FLAG = "--balancer-bandwidth"
+
+// optional: fraction of chunks reported before the controller leaves safe mode
+const SAFE_MODE_THRESHOLD_FLAG = "--safe-mode-threshold"
+
+// compute engine: map and reduce tasks each storage node runs at once
+const TASK_SLOTS_FLAG = "--task-slots"
+
+// compute engine: runs of a failed task before giving up on the job
+const MAX_TASK_ATTEMPTS_FLAG = "--max-task-attempts"
+
+// compute engine: jobs running at once, later ones wait in a FIFO queue
+const CONCURRENT_JOBS_FLAG = "--concurrent-jobs"
+
 // Error messages
 const MISSING_APP_ERROR_MSG = "Specify App you want to run with " + APP_FLAG + " <controller/storage-node/client>"
 const MISSING_LOCAL_PORT_ERROR_MSG = "Specify the Controller Port with " + PORT_FLAG + " <int>"
@@ -43,8 +77,20 @@ const MISSING_COMPUTE_ENGINE_HOSTNAME_FLAG_ERROR_MSG = "Specify the Compute Engi
 const MISSING_CONTROLLER_PORT_FLAG_ERROR_MSG = "Specify the Controller Port with " + CONTROLLER_PORT_FLAG + " <int>"
 const MISSING_COMPUTE_ENGINE_PORT_FLAG_ERROR_MSG = "Specify the Compute Engine Port with " + CONTROLLER_PORT_FLAG + " <int>"
 const MISSING_STORAGE_NODE_DIR_ERROR_MSG = "Specify storage folder with " + STORAGE_NODE_DIR + "</home/username/storage-folder"
+const MISSING_STORAGE_BACKEND_ERROR_MSG = "Specify the storage backend with " + STORAGE_BACKEND_FLAG + " <local/memory/jbod>"
 const MISSING_PLUGINS_DIR_ERROR_MSG = "Specify storage folder for plugins with " + PLUGINS_DIR + "</home/username/plugins-folder"
 const MISSING_COMPUTE_STORAGE_DIR_ERROR_MSG = "Specify a temp storage dir for computations with " + COMPUTE_STORAGE_DIR + "</f1/f2/temp-compute-storage-folder"
+const INVALID_REPLICATION_ERROR_MSG = "Specify the replication factor with " + REPLICATION_FLAG + " <int>"
+const MISSING_MASTER_KEY_FILE_ERROR_MSG = "Specify the master keyfile with " + MASTER_KEY_FILE_FLAG + " </f1/f2/master.key>"
+const INVALID_BALANCER_BANDWIDTH_ERROR_MSG = "Specify the balancer bandwidth with " + BALANCER_BANDWIDTH_FLAG + " <MB per round>"
+const INVALID_SAFE_MODE_THRESHOLD_ERROR_MSG = "Specify the safe mode threshold with " + SAFE_MODE_THRESHOLD_FLAG + " <0..1>"
+const INVALID_TASK_SLOTS_ERROR_MSG = "Specify the tasks each storage node runs at once with " + TASK_SLOTS_FLAG + " <int>"
+const INVALID_MAX_TASK_ATTEMPTS_ERROR_MSG = "Specify the runs of a task before its job fails with " + MAX_TASK_ATTEMPTS_FLAG + " <int>"
+const INVALID_CONCURRENT_JOBS_ERROR_MSG = "Specify the jobs running at once with " + CONCURRENT_JOBS_FLAG + " <int>"
+const INVALID_RESERVED_SPACE_ERROR_MSG = "Specify the space kept free on every storage dir with " + RESERVED_SPACE_FLAG + " <MB>"
+const MISSING_CONTROLLERS_ERROR_MSG = "Specify the controllers with " + CONTROLLERS_FLAG + " <host1:port1,host2:port2>"
+const MISSING_EDIT_LOG_ERROR_MSG = "Specify the controller edit log file with " + EDIT_LOG_FLAG + " </f1/f2/edits.log>"
+const MISSING_COMPRESSION_ERROR_MSG = "Specify the compression codec with " + COMPRESSION_FLAG + " <gzip/zstd/snappy>"
 
 func GetApp() string {
 	return argsGet(APP_FLAG, MISSING_APP_ERROR_MSG)
@@ -64,6 +110,39 @@ func GetControllerHostname() string {
 	return argsGet(CONTROLLER_HOSTNAME_FLAG, MISSING_CONTROLLER_HOSTNAME_FLAG_ERROR_MSG)
 }
 
+/** Controllers given with --controllers; nil when there is a single one */
+func GetControllers() []string {
+	if !Contains(CONTROLLERS_FLAG) {
+		return nil
+	}
+	controllers := make([]string, 0)
+	for _, addr := range strings.Split(argsGet(CONTROLLERS_FLAG, MISSING_CONTROLLERS_ERROR_MSG), ",") {
+		if addr != "" {
+			controllers = append(controllers, addr)
+		}
+	}
+	if len(controllers) == 0 {
+		log.Fatalln(MISSING_CONTROLLERS_ERROR_MSG)
+	}
+	return controllers
+}
+
+/** Addresses to reach the cluster: --controllers, or else --hostname and --host-port */
+func GetControllerAddrs() []string {
+	if controllers := GetControllers(); controllers != nil {
+		return controllers
+	}
+	return []string{GetAddr(GetControllerHostname(), GetControllerPort())}
+}
+
+/** Optional; without it the edit log only lives in memory */
+func GetEditLog() string {
+	if !Contains(EDIT_LOG_FLAG) {
+		return ""
+	}
+	return argsGet(EDIT_LOG_FLAG, MISSING_EDIT_LOG_ERROR_MSG)
+}
+
 func GetComputeEngineHostname() string {
 	return argsGet(COMPUTE_ENGINE_HOSTNAME_FLAG, MISSING_COMPUTE_ENGINE_HOSTNAME_FLAG_ERROR_MSG)
 }
@@ -92,6 +171,37 @@ func GetStorageDir() string {
 	return argsGet(STORAGE_NODE_DIR, MISSING_STORAGE_NODE_DIR_ERROR_MSG)
 }
 
+/**
+* Storage nodes accept several storage dirs, either repeating the flag or
+* comma separated: --storage-dir /disk1/adfs,/disk2/adfs
+ */
+func GetStorageDirs() []string {
+	dirs := make([]string, 0)
+	args := os.Args
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] != STORAGE_NODE_DIR {
+			continue
+		}
+		for _, dir := range strings.Split(args[i+1], ",") {
+			if dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	if len(dirs) == 0 {
+		log.Fatalln(MISSING_STORAGE_NODE_DIR_ERROR_MSG)
+	}
+	return dirs
+}
+
+/** Optional; defaults to the local filesystem (JBOD with several storage dirs) */
+func GetStorageBackend() string {
+	if !Contains(STORAGE_BACKEND_FLAG) {
+		return ""
+	}
+	return argsGet(STORAGE_BACKEND_FLAG, MISSING_STORAGE_BACKEND_ERROR_MSG)
+}
+
 func GetPluginsDir() string {
 	return argsGet(PLUGINS_DIR, MISSING_PLUGINS_DIR_ERROR_MSG)
 }
@@ -100,6 +210,116 @@ func GetComputeStorageDir() string {
 	return argsGet(COMPUTE_STORAGE_DIR, MISSING_COMPUTE_STORAGE_DIR_ERROR_MSG)
 }
 
+/** Replication is optional; 0 lets the Controller pick the default */
+func GetReplication() int {
+	if !Contains(REPLICATION_FLAG) {
+		return 0
+	}
+	replication := argsGet(REPLICATION_FLAG, INVALID_REPLICATION_ERROR_MSG)
+	if r, err := strconv.Atoi(replication); err != nil || r < 0 {
+		log.Fatalln(INVALID_REPLICATION_ERROR_MSG)
+		return 0
+	} else {
+		return r
+	}
+}
+
+/** Returns bytes; defaultReserved (bytes) when the flag is missing */
+func GetReservedSpace(defaultReserved int64) int64 {
+	if !Contains(RESERVED_SPACE_FLAG) {
+		return defaultReserved
+	}
+	reserved := argsGet(RESERVED_SPACE_FLAG, INVALID_RESERVED_SPACE_ERROR_MSG)
+	if mb, err := strconv.Atoi(reserved); err != nil || mb < 0 {
+		log.Fatalln(INVALID_RESERVED_SPACE_ERROR_MSG)
+		return 0
+	} else {
+		return int64(mb) << 20
+	}
+}
+
+/** Returns bytes per round; defaultBandwidth (bytes) when the flag is missing */
+func GetBalancerBandwidth(defaultBandwidth int64) int64 {
+	if !Contains(BALANCER_BANDWIDTH_FLAG) {
+		return defaultBandwidth
+	}
+	bandwidth := argsGet(BALANCER_BANDWIDTH_FLAG, INVALID_BALANCER_BANDWIDTH_ERROR_MSG)
+	if mb, err := strconv.Atoi(bandwidth); err != nil || mb < 0 {
+		log.Fatalln(INVALID_BALANCER_BANDWIDTH_ERROR_MSG)
+		return 0
+	} else {
+		return int64(mb) << 20
+	}
+}
+
+func GetSafeModeThreshold(defaultThreshold float64) float64 {
+	if !Contains(SAFE_MODE_THRESHOLD_FLAG) {
+		return defaultThreshold
+	}
+	threshold := argsGet(SAFE_MODE_THRESHOLD_FLAG, INVALID_SAFE_MODE_THRESHOLD_ERROR_MSG)
+	if t, err := strconv.ParseFloat(threshold, 64); err != nil || t < 0 || t > 1 {
+		log.Fatalln(INVALID_SAFE_MODE_THRESHOLD_ERROR_MSG)
+		return 0
+	} else {
+		return t
+	}
+}
+
+func GetTaskSlots(defaultSlots int) int {
+	if !Contains(TASK_SLOTS_FLAG) {
+		return defaultSlots
+	}
+	slots := argsGet(TASK_SLOTS_FLAG, INVALID_TASK_SLOTS_ERROR_MSG)
+	if s, err := strconv.Atoi(slots); err != nil || s < 1 {
+		log.Fatalln(INVALID_TASK_SLOTS_ERROR_MSG)
+		return 0
+	} else {
+		return s
+	}
+}
+
+func GetMaxTaskAttempts(defaultAttempts int) int {
+	if !Contains(MAX_TASK_ATTEMPTS_FLAG) {
+		return defaultAttempts
+	}
+	attempts := argsGet(MAX_TASK_ATTEMPTS_FLAG, INVALID_MAX_TASK_ATTEMPTS_ERROR_MSG)
+	if a, err := strconv.Atoi(attempts); err != nil || a < 1 {
+		log.Fatalln(INVALID_MAX_TASK_ATTEMPTS_ERROR_MSG)
+		return 0
+	} else {
+		return a
+	}
+}
+
+func GetConcurrentJobs(defaultJobs int) int {
+	if !Contains(CONCURRENT_JOBS_FLAG) {
+		return defaultJobs
+	}
+	jobs := argsGet(CONCURRENT_JOBS_FLAG, INVALID_CONCURRENT_JOBS_ERROR_MSG)
+	if j, err := strconv.Atoi(jobs); err != nil || j < 1 {
+		log.Fatalln(INVALID_CONCURRENT_JOBS_ERROR_MSG)
+		return 0
+	} else {
+		return j
+	}
+}
+
+/** Encryption at rest is optional; no keyfile means chunks are stored in plaintext */
+func GetMasterKeyFile() string {
+	if !Contains(MASTER_KEY_FILE_FLAG) {
+		return ""
+	}
+	return argsGet(MASTER_KEY_FILE_FLAG, MISSING_MASTER_KEY_FILE_ERROR_MSG)
+}
+
+/** Compression is optional; no codec means chunks are stored raw */
+func GetCompression() string {
+	if !Contains(COMPRESSION_FLAG) {
+		return ""
+	}
+	return argsGet(COMPRESSION_FLAG, MISSING_COMPRESSION_ERROR_MSG)
+}
+
 func argsGet(flag, errorMsg string) string {
 	args := os.Args
 	if val, err := getFlagValue(args, flag, MISSING_APP_ERROR_MSG); err != nil {
@@ -3,10 +3,15 @@ package helpers
 import (
 	"adfs/messages"
 	"github.com/sirupsen/logrus"
+	"math/rand"
 	"os"
 	"strconv"
+	"time"
 )
 
+const RECONNECT_BACKOFF_MIN_S = 1
+const RECONNECT_BACKOFF_MAX_S = 60
+
 func GetAddr(host string, port int) string {
 	return host + ":" + strconv.Itoa(port)
 }
@@ -40,3 +45,33 @@ func CloseMessageHandlers(messageHandlers []*messages.MessageHandler) {
 		}
 	}
 }
+
+/**
+* Calls connect until it succeeds, doubling the wait between attempts up to
+* RECONNECT_BACKOFF_MAX_S. Returns false if quit fires first.
+ */
+func RetryWithBackoff(what string, connect func() error, quit <-chan bool) bool {
+	delay := RECONNECT_BACKOFF_MIN_S * time.Second
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil {
+			return true
+		}
+		// jitter keeps a whole cluster from reconnecting in lockstep
+		wait := delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		logrus.WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"attempt": attempt,
+			"retryIn": wait.Round(time.Millisecond).String(),
+		}).Warn("Could not reach " + what)
+		select {
+		case <-quit:
+			return false
+		case <-time.After(wait):
+		}
+		delay *= 2
+		if delay > RECONNECT_BACKOFF_MAX_S*time.Second {
+			delay = RECONNECT_BACKOFF_MAX_S * time.Second
+		}
+	}
+}
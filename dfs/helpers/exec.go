@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -36,23 +37,27 @@ func ClearTerminal() {
 	}
 }
 
-func BuildGoPlugin(path string) error {
+/**
+* Builds a Go job into a plugin next to it and returns the .so path. The job
+* must live inside the adfs module so it's built against the same packages as
+* the storage nodes loading it.
+ */
+func BuildGoPlugin(path string) (string, error) {
 	if !isGoFile(path) {
-		return errors.New("provided file is not a go file")
+		return "", errors.New("provided file is not a go file")
 	}
-	_, err := exec.Command(buildGoPluginCmd(path)).Output()
+	// go build resolves -o against cmd.Dir, not against our working dir
+	path, err := filepath.Abs(path)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
-}
-
-func buildGoPluginCmd(path string) string {
-	return fmt.Sprintf(
-		"go build -buildmode=plugin -o %s %s",
-		getPluginName(path),
-		path,
-	)
+	pluginPath := getPluginName(path)
+	cmd := exec.Command("go", "build", "-buildmode=plugin", "-o", pluginPath, filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return pluginPath, nil
 }
 
 func getPluginName(path string) string {
@@ -60,6 +65,5 @@ func getPluginName(path string) string {
 }
 
 func isGoFile(path string) bool {
-	extension := path[len(path)-3:]
-	return extension == ".go"
+	return strings.HasSuffix(path, ".go")
 }
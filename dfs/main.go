@@ -17,34 +17,47 @@ func main() {
 	switch app {
 	case h.CONTROLLER_APP:
 		h.PrintTitle("CONTROLLER")
-		controller.Init(controller.Config{Port: h.GetLocalPort()})
+		controller.Init(controller.Config{
+			Port:              h.GetLocalPort(),
+			BalancerBandwidth: h.GetBalancerBandwidth(controller.DEFAULT_BALANCER_BANDWIDTH),
+			SafeModeThreshold: h.GetSafeModeThreshold(controller.DEFAULT_SAFE_MODE_THRESHOLD),
+			Controllers:       h.GetControllers(),
+			EditLog:           h.GetEditLog(),
+		})
 		return
 	case h.COMPUTE_ENGINE_APP:
 		h.PrintTitle("COMPUTE ENGINE")
 		compute_engine.Init(compute_engine.Config{
-			Port:               h.GetLocalPort(),
-			ControllerHostname: h.GetControllerHostname(),
-			ControllerPort:     h.GetControllerPort(),
+			Port:            h.GetLocalPort(),
+			ControllerAddrs: h.GetControllerAddrs(),
+			TaskSlots:       h.GetTaskSlots(compute_engine.DEFAULT_TASK_SLOTS),
+			MaxTaskAttempts: h.GetMaxTaskAttempts(compute_engine.DEFAULT_MAX_TASK_ATTEMPTS),
+			ConcurrentJobs:  h.GetConcurrentJobs(compute_engine.DEFAULT_CONCURRENT_JOBS),
 		})
 		return
 	case h.STORAGE_NODE_APP:
 		h.PrintTitle("S.NODE")
+		storageDirs := h.GetStorageDirs()
 		config := storageNode.Config{
-			Port:               h.GetLocalPort(),
-			ControllerHostname: h.GetControllerHostname(),
-			ControllerPort:     h.GetControllerPort(),
-			StorageDir:         h.GetStorageDir(),
-			PluginsDir:         h.GetPluginsDir(),
-			ComputeStorageDir:  h.GetComputeStorageDir(),
+			Port:              h.GetLocalPort(),
+			ControllerAddrs:   h.GetControllerAddrs(),
+			StorageDir:        storageDirs[0],
+			StorageDirs:       storageDirs,
+			StorageBackend:    h.GetStorageBackend(),
+			ReservedSpace:     h.GetReservedSpace(storageNode.DEFAULT_RESERVED_SPACE),
+			PluginsDir:        h.GetPluginsDir(),
+			ComputeStorageDir: h.GetComputeStorageDir(),
+			MasterKeyFile:     h.GetMasterKeyFile(),
 		}
 		storageNode.Init(config)
 		return
 	case h.CLIENT_APP:
 		config := client.Config{
-			HomeDir:        h.GetHomeDir(),
-			ControllerHost: h.GetControllerHostname(),
-			ControllerPort: h.GetControllerPort(),
-			StorageDir:     h.GetStorageDir(),
+			HomeDir:         h.GetHomeDir(),
+			ControllerAddrs: h.GetControllerAddrs(),
+			StorageDir:      h.GetStorageDir(),
+			Replication:     h.GetReplication(),
+			Compression:     h.GetCompression(),
 		}
 		client.Init(config)
 		return
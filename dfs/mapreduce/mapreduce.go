@@ -0,0 +1,105 @@
+package mapreduce
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const DELIMITER = "\t"
+
+type Emit func(key, value string)
+type Mapper func(lineNumber int, lineText string, emit Emit)
+type Reducer func(key string, values []string, emit Emit)
+
+/**
+* Entry point for plugins. Serves the worker protocol when started as a worker
+* and the one-record-per-exec mode otherwise, so the same binary runs on
+* compute engines speaking either:
+*
+*	func main() {
+*		mapreduce.Run(wordCountMap, wordCountReduce)
+*	}
+ */
+func Run(mapper Mapper, reducer Reducer) {
+	args := os.Args
+	var err error
+	if len(args) == 3 && args[1] == WORKER {
+		err = serve(strings.ToUpper(args[2]), mapper, reducer, os.Stdin, os.Stdout)
+	} else if len(args) == 4 {
+		err = runOnce(strings.ToUpper(args[1]), args[2], args[3], mapper, reducer)
+	} else {
+		err = fmt.Errorf("usage: %s WORKER MAP|REDUCE or %s MAP|REDUCE <key> <value>", args[0], args[0])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR", err.Error())
+		os.Exit(1)
+	}
+}
+
+func serve(job string, mapper Mapper, reducer Reducer, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	writer := bufio.NewWriter(out)
+	// the engine waits for the handshake before sending anything
+	if _, err := writer.WriteString(HANDSHAKE); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	var writeErr error
+	emit := func(key, value string) {
+		if writeErr == nil {
+			writeErr = WriteRecord(writer, []byte(key), []byte(value))
+		}
+	}
+	for {
+		key, value, err := ReadRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := call(job, string(key), string(value), mapper, reducer, emit); err != nil {
+			return err
+		}
+		if writeErr != nil {
+			return writeErr
+		}
+	}
+	return writer.Flush()
+}
+
+func runOnce(job, key, value string, mapper Mapper, reducer Reducer) error {
+	writer := bufio.NewWriter(os.Stdout)
+	emit := func(key, value string) {
+		writer.WriteString(key + DELIMITER + value + "\n")
+	}
+	if job == REDUCE {
+		value = strings.TrimSuffix(value, DELIMITER) // argv mode terminates the values with a tab
+	}
+	if err := call(job, key, value, mapper, reducer, emit); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+func call(job, key, value string, mapper Mapper, reducer Reducer, emit Emit) error {
+	switch job {
+	case MAP:
+		lineNumber, err := strconv.Atoi(key)
+		if err != nil {
+			return err
+		}
+		mapper(lineNumber, value, emit)
+	case REDUCE:
+		reducer(key, strings.Split(value, DELIMITER), emit)
+	default:
+		return fmt.Errorf("job %s not recognized. Expected %s or %s", job, MAP, REDUCE)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package mapreduce
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+/**
+* Worker protocol between the compute engine and a plugin. The engine starts
+* the plugin once per task as `<plugin> WORKER MAP|REDUCE`. The plugin answers
+* with HANDSHAKE on stdout, then reads records from stdin until EOF and writes
+* its output records to stdout. A record is a key and a value, each prefixed
+* by its length as a big endian uint32.
+*
+* MAP input: key is the line number, value the line.
+* REDUCE input: key is the key, value its values separated by tabs.
+ */
+const WORKER = "WORKER"
+const MAP = "MAP"
+const REDUCE = "REDUCE"
+const HANDSHAKE = "ADFS-WORKER/1\n"
+
+// a reducer record holds every value of its key, so allow big ones
+const MAX_FIELD_SIZE = 64 * 1024 * 1024
+
+var ErrFieldTooLarge = errors.New("record field larger than " + strconv.Itoa(MAX_FIELD_SIZE) + " bytes")
+
+func WriteRecord(w *bufio.Writer, key, value []byte) error {
+	if err := writeField(w, key); err != nil {
+		return err
+	}
+	return writeField(w, value)
+}
+
+/** Returns io.EOF once the stream ends cleanly between records */
+func ReadRecord(r *bufio.Reader) (key, value []byte, err error) {
+	if key, err = readField(r); err != nil {
+		return nil, nil, err
+	}
+	if value, err = readField(r); err == io.EOF {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return key, value, err
+}
+
+func writeField(w *bufio.Writer, field []byte) error {
+	if len(field) > MAX_FIELD_SIZE {
+		return ErrFieldTooLarge
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(field)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(field)
+	return err
+}
+
+func readField(r *bufio.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err // io.EOF only if nothing was read
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > MAX_FIELD_SIZE {
+		return nil, ErrFieldTooLarge
+	}
+	field := make([]byte, n)
+	if _, err := io.ReadFull(r, field); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return field, nil
+}
@@ -0,0 +1,128 @@
+package mapreduce
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func roundTrip(t *testing.T, key, value []byte) ([]byte, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	if err := WriteRecord(writer, key, value); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(&buf)
+	gotKey, gotValue, err := ReadRecord(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReadRecord(reader); err != io.EOF {
+		t.Fatalf("got %v after the only record, want io.EOF", err)
+	}
+	return gotKey, gotValue
+}
+
+func TestRecordRoundTripEmptyKeyAndValue(t *testing.T) {
+	key, value := roundTrip(t, []byte{}, nil)
+	if len(key) != 0 || len(value) != 0 {
+		t.Errorf("got %q %q, want empty key and value", key, value)
+	}
+}
+
+func TestRecordRoundTripLargeValue(t *testing.T) {
+	large := bytes.Repeat([]byte("values\t"), 1<<20)
+	key, value := roundTrip(t, []byte("k"), large)
+	if string(key) != "k" || !bytes.Equal(value, large) {
+		t.Errorf("got key %q and a %d byte value, want k and %d bytes", key, len(value), len(large))
+	}
+}
+
+func TestWriteRecordRefusesOversizedFields(t *testing.T) {
+	writer := bufio.NewWriter(io.Discard)
+	if err := WriteRecord(writer, make([]byte, MAX_FIELD_SIZE+1), nil); err != ErrFieldTooLarge {
+		t.Errorf("got %v, want ErrFieldTooLarge", err)
+	}
+}
+
+func TestReadRecordTruncatedStream(t *testing.T) {
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	if err := WriteRecord(writer, []byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	writer.Flush()
+	record := buf.Bytes()
+
+	tests := []struct {
+		name string
+		cut  int
+	}{
+		{"inside the key length", 2},
+		{"inside the key", 6},
+		{"between key and value", 7},
+		{"inside the value length", 9},
+		{"inside the value", len(record) - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bufio.NewReader(bytes.NewReader(record[:tt.cut]))
+			_, _, err := ReadRecord(reader)
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("got %v, want io.ErrUnexpectedEOF", err)
+			}
+		})
+	}
+}
+
+func TestServeAnswersHandshakeThenMaps(t *testing.T) {
+	var in bytes.Buffer
+	writer := bufio.NewWriter(&in)
+	WriteRecord(writer, []byte("0"), []byte("a b"))
+	WriteRecord(writer, []byte("1"), []byte("c"))
+	writer.Flush()
+
+	mapper := func(lineNumber int, lineText string, emit Emit) {
+		for _, word := range strings.Fields(lineText) {
+			emit(word, "1")
+		}
+	}
+	var out bytes.Buffer
+	if err := serve(MAP, mapper, nil, &in, &out); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(&out)
+	handshake := make([]byte, len(HANDSHAKE))
+	if _, err := io.ReadFull(reader, handshake); err != nil || string(handshake) != HANDSHAKE {
+		t.Fatalf("got handshake %q (%v), want %q", handshake, err, HANDSHAKE)
+	}
+	got := []string{}
+	for {
+		key, value, err := ReadRecord(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(key)+"="+string(value))
+	}
+	if want := "a=1 b=1 c=1"; strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
+
+func TestServeFailsOnTruncatedInput(t *testing.T) {
+	in := bytes.NewReader([]byte{0, 0, 0, 5, 'a'})
+	err := serve(MAP, func(int, string, Emit) {}, nil, in, io.Discard)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v, want io.ErrUnexpectedEOF", err)
+	}
+}
@@ -30,18 +30,38 @@ const (
 	ActionType_COMPUTE       ActionType = 4
 	ActionType_CLUSTER_STATS ActionType = 5
 	ActionType_COMPUTE_STORE ActionType = 6
+	ActionType_SETREP        ActionType = 7
+	ActionType_REPLICATE     ActionType = 8  // controller -> storage node
+	ActionType_SETPOLICY     ActionType = 9  // storage policy of a directory
+	ActionType_DECOMMISSION  ActionType = 10 // drain a storage node before taking it out of service
+	ActionType_WHO_IS_LEADER ActionType = 11 // controllers answer with an Ack, ok only from the leader
+	ActionType_SAFEMODE      ActionType = 12 // admin: enter or leave safe mode (read-only namespace)
+	ActionType_FSCK          ActionType = 13 // health report of the files under file_name
+	ActionType_SETQUOTA      ActionType = 14 // file count and byte limits of a directory
+	ActionType_SAMPLE        ActionType = 15 // compute engine -> storage node: random keys of a local chunk
+	ActionType_DISCARD       ActionType = 16 // compute engine -> storage node: drop the mapper output of a map attempt
 )
 
 // Enum value maps for ActionType.
 var (
 	ActionType_name = map[int32]string{
-		0: "LS",
-		1: "GET",
-		2: "PUT",
-		3: "RM",
-		4: "COMPUTE",
-		5: "CLUSTER_STATS",
-		6: "COMPUTE_STORE",
+		0:  "LS",
+		1:  "GET",
+		2:  "PUT",
+		3:  "RM",
+		4:  "COMPUTE",
+		5:  "CLUSTER_STATS",
+		6:  "COMPUTE_STORE",
+		7:  "SETREP",
+		8:  "REPLICATE",
+		9:  "SETPOLICY",
+		10: "DECOMMISSION",
+		11: "WHO_IS_LEADER",
+		12: "SAFEMODE",
+		13: "FSCK",
+		14: "SETQUOTA",
+		15: "SAMPLE",
+		16: "DISCARD",
 	}
 	ActionType_value = map[string]int32{
 		"LS":            0,
@@ -51,6 +71,16 @@ var (
 		"COMPUTE":       4,
 		"CLUSTER_STATS": 5,
 		"COMPUTE_STORE": 6,
+		"SETREP":        7,
+		"REPLICATE":     8,
+		"SETPOLICY":     9,
+		"DECOMMISSION":  10,
+		"WHO_IS_LEADER": 11,
+		"SAFEMODE":      12,
+		"FSCK":          13,
+		"SETQUOTA":      14,
+		"SAMPLE":        15,
+		"DISCARD":       16,
 	}
 )
 
@@ -127,13 +157,67 @@ func (ComputeType) EnumDescriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{1}
 }
 
+type PartitionerType int32
+
+const (
+	PartitionerType_partition_hash        PartitionerType = 0
+	PartitionerType_partition_key_prefix  PartitionerType = 1
+	PartitionerType_partition_range       PartitionerType = 2
+	PartitionerType_partition_total_order PartitionerType = 3 // range boundaries sampled from the input by the compute engine
+)
+
+// Enum value maps for PartitionerType.
+var (
+	PartitionerType_name = map[int32]string{
+		0: "partition_hash",
+		1: "partition_key_prefix",
+		2: "partition_range",
+		3: "partition_total_order",
+	}
+	PartitionerType_value = map[string]int32{
+		"partition_hash":        0,
+		"partition_key_prefix":  1,
+		"partition_range":       2,
+		"partition_total_order": 3,
+	}
+)
+
+func (x PartitionerType) Enum() *PartitionerType {
+	p := new(PartitionerType)
+	*p = x
+	return p
+}
+
+func (x PartitionerType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PartitionerType) Descriptor() protoreflect.EnumDescriptor {
+	return file_dfs_proto_enumTypes[2].Descriptor()
+}
+
+func (PartitionerType) Type() protoreflect.EnumType {
+	return &file_dfs_proto_enumTypes[2]
+}
+
+func (x PartitionerType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PartitionerType.Descriptor instead.
+func (PartitionerType) EnumDescriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{2}
+}
+
 type JobStatus int32
 
 const (
-	JobStatus_job_accepted JobStatus = 0
-	JobStatus_job_mappers  JobStatus = 1
-	JobStatus_job_reducers JobStatus = 2
-	JobStatus_job_done     JobStatus = 4
+	JobStatus_job_accepted  JobStatus = 0
+	JobStatus_job_mappers   JobStatus = 1
+	JobStatus_job_reducers  JobStatus = 2
+	JobStatus_job_done      JobStatus = 4
+	JobStatus_job_queued    JobStatus = 5 // waiting for a free job slot of the compute engine
+	JobStatus_job_uploading JobStatus = 6 // reducer -> compute engine: the controller accepted the output, upload under way
 )
 
 // Enum value maps for JobStatus.
@@ -143,12 +227,16 @@ var (
 		1: "job_mappers",
 		2: "job_reducers",
 		4: "job_done",
+		5: "job_queued",
+		6: "job_uploading",
 	}
 	JobStatus_value = map[string]int32{
-		"job_accepted": 0,
-		"job_mappers":  1,
-		"job_reducers": 2,
-		"job_done":     4,
+		"job_accepted":  0,
+		"job_mappers":   1,
+		"job_reducers":  2,
+		"job_done":      4,
+		"job_queued":    5,
+		"job_uploading": 6,
 	}
 )
 
@@ -163,11 +251,11 @@ func (x JobStatus) String() string {
 }
 
 func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_dfs_proto_enumTypes[2].Descriptor()
+	return file_dfs_proto_enumTypes[3].Descriptor()
 }
 
 func (JobStatus) Type() protoreflect.EnumType {
-	return &file_dfs_proto_enumTypes[2]
+	return &file_dfs_proto_enumTypes[3]
 }
 
 func (x JobStatus) Number() protoreflect.EnumNumber {
@@ -176,7 +264,7 @@ func (x JobStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use JobStatus.Descriptor instead.
 func (JobStatus) EnumDescriptor() ([]byte, []int) {
-	return file_dfs_proto_rawDescGZIP(), []int{2}
+	return file_dfs_proto_rawDescGZIP(), []int{3}
 }
 
 type ActionRequest struct {
@@ -195,6 +283,18 @@ type ActionRequest struct {
 	Data           []byte      `protobuf:"bytes,9,opt,name=data,proto3" json:"data,omitempty"`
 	ReducerNumber  int32       `protobuf:"varint,10,opt,name=reducer_number,json=reducerNumber,proto3" json:"reducer_number,omitempty"`   // reduce
 	OutputFilename string      `protobuf:"bytes,11,opt,name=output_filename,json=outputFilename,proto3" json:"output_filename,omitempty"` // compute
+	Replication    int32       `protobuf:"varint,12,opt,name=replication,proto3" json:"replication,omitempty"`                            // put/setrep
+	Targets        []*Node     `protobuf:"bytes,13,rep,name=targets,proto3" json:"targets,omitempty"`                                     // replicate
+	StoragePolicy  string      `protobuf:"bytes,14,opt,name=storage_policy,json=storagePolicy,proto3" json:"storage_policy,omitempty"`    // put/setpolicy; "replicated" or "RS-<data>-<parity>"
+	SafeMode       string      `protobuf:"bytes,15,opt,name=safe_mode,json=safeMode,proto3" json:"safe_mode,omitempty"`                   // safemode: "enter" or "leave"
+	Repair         bool        `protobuf:"varint,16,opt,name=repair,proto3" json:"repair,omitempty"`                                      // fsck: also fix what can be fixed
+	FileSize       int64       `protobuf:"varint,17,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`                  // put: bytes to upload, checked against quotas
+	QuotaFiles     int64       `protobuf:"varint,18,opt,name=quota_files,json=quotaFiles,proto3" json:"quota_files,omitempty"`            // setquota: max files under the directory; 0 means no limit
+	QuotaBytes     int64       `protobuf:"varint,19,opt,name=quota_bytes,json=quotaBytes,proto3" json:"quota_bytes,omitempty"`            // setquota: max bytes stored (replicas included); 0 means no limit
+	SampleSize     int32       `protobuf:"varint,20,opt,name=sample_size,json=sampleSize,proto3" json:"sample_size,omitempty"`            // sample: number of keys wanted
+	Partitions     []int32     `protobuf:"varint,21,rep,packed,name=partitions,proto3" json:"partitions,omitempty"`                       // map: only shuffle these partitions, all when empty; re-runs lost map output
+	Attempt        int32       `protobuf:"varint,22,opt,name=attempt,proto3" json:"attempt,omitempty"`                                    // map: id of this run of the task, which names its output files
+	JobId          string      `protobuf:"bytes,23,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`                            // map/reduce/compute_store/discard: job owning the intermediate files; discard without file_name drops them all
 }
 
 func (x *ActionRequest) Reset() {
@@ -306,13 +406,101 @@ func (x *ActionRequest) GetOutputFilename() string {
 	return ""
 }
 
+func (x *ActionRequest) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *ActionRequest) GetTargets() []*Node {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *ActionRequest) GetStoragePolicy() string {
+	if x != nil {
+		return x.StoragePolicy
+	}
+	return ""
+}
+
+func (x *ActionRequest) GetSafeMode() string {
+	if x != nil {
+		return x.SafeMode
+	}
+	return ""
+}
+
+func (x *ActionRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+func (x *ActionRequest) GetFileSize() int64 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *ActionRequest) GetQuotaFiles() int64 {
+	if x != nil {
+		return x.QuotaFiles
+	}
+	return 0
+}
+
+func (x *ActionRequest) GetQuotaBytes() int64 {
+	if x != nil {
+		return x.QuotaBytes
+	}
+	return 0
+}
+
+func (x *ActionRequest) GetSampleSize() int32 {
+	if x != nil {
+		return x.SampleSize
+	}
+	return 0
+}
+
+func (x *ActionRequest) GetPartitions() []int32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *ActionRequest) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *ActionRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
 type Plugin struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Plugin []byte `protobuf:"bytes,2,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	Name        string       `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Plugin      []byte       `protobuf:"bytes,2,opt,name=plugin,proto3" json:"plugin,omitempty"`
+	Streaming   *Streaming   `protobuf:"bytes,3,opt,name=streaming,proto3" json:"streaming,omitempty"`     // set for streaming jobs, which ship no plugin
+	Combine     bool         `protobuf:"varint,4,opt,name=combine,proto3" json:"combine,omitempty"`        // run the reducer over each mapper partition before shuffling it
+	Reducers    int32        `protobuf:"varint,5,opt,name=reducers,proto3" json:"reducers,omitempty"`      // 0 lets the compute engine pick
+	Partitioner *Partitioner `protobuf:"bytes,6,opt,name=partitioner,proto3" json:"partitioner,omitempty"` // hash partitioning if unset
 }
 
 func (x *Plugin) Reset() {
@@ -361,16 +549,47 @@ func (x *Plugin) GetPlugin() []byte {
 	return nil
 }
 
-type Registration struct {
+func (x *Plugin) GetStreaming() *Streaming {
+	if x != nil {
+		return x.Streaming
+	}
+	return nil
+}
+
+func (x *Plugin) GetCombine() bool {
+	if x != nil {
+		return x.Combine
+	}
+	return false
+}
+
+func (x *Plugin) GetReducers() int32 {
+	if x != nil {
+		return x.Reducers
+	}
+	return 0
+}
+
+func (x *Plugin) GetPartitioner() *Partitioner {
+	if x != nil {
+		return x.Partitioner
+	}
+	return nil
+}
+
+// decides which reducer each mapper output key goes to
+type Partitioner struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Node *Node `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Type         PartitionerType `protobuf:"varint,1,opt,name=type,proto3,enum=PartitionerType" json:"type,omitempty"`
+	PrefixLength int32           `protobuf:"varint,2,opt,name=prefix_length,json=prefixLength,proto3" json:"prefix_length,omitempty"` // key_prefix: keys sharing their first prefix_length bytes go to the same reducer
+	Boundaries   []string        `protobuf:"bytes,3,rep,name=boundaries,proto3" json:"boundaries,omitempty"`                          // range: sorted split keys; reducer i gets keys from boundaries[i-1] up to, excluding, boundaries[i]
 }
 
-func (x *Registration) Reset() {
-	*x = Registration{}
+func (x *Partitioner) Reset() {
+	*x = Partitioner{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -378,13 +597,13 @@ func (x *Registration) Reset() {
 	}
 }
 
-func (x *Registration) String() string {
+func (x *Partitioner) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Registration) ProtoMessage() {}
+func (*Partitioner) ProtoMessage() {}
 
-func (x *Registration) ProtoReflect() protoreflect.Message {
+func (x *Partitioner) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -396,33 +615,45 @@ func (x *Registration) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Registration.ProtoReflect.Descriptor instead.
-func (*Registration) Descriptor() ([]byte, []int) {
+// Deprecated: Use Partitioner.ProtoReflect.Descriptor instead.
+func (*Partitioner) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *Registration) GetNode() *Node {
+func (x *Partitioner) GetType() PartitionerType {
 	if x != nil {
-		return x.Node
+		return x.Type
+	}
+	return PartitionerType_partition_hash
+}
+
+func (x *Partitioner) GetPrefixLength() int32 {
+	if x != nil {
+		return x.PrefixLength
+	}
+	return 0
+}
+
+func (x *Partitioner) GetBoundaries() []string {
+	if x != nil {
+		return x.Boundaries
 	}
 	return nil
 }
 
-// Storage Node heartbeat to Controller;
-// It will send its File Index on each heartbeat.
-// Not ideal.
-type Heartbeat struct {
+// shell commands run on the storage nodes; records are tab separated key/value lines over stdin/stdout
+type Streaming struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Chunks      []*Chunk `protobuf:"bytes,1,rep,name=Chunks,proto3" json:"Chunks,omitempty"`
-	StorageNode *Node    `protobuf:"bytes,2,opt,name=storage_node,json=storageNode,proto3" json:"storage_node,omitempty"`
-	Stats       *Stats   `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
+	Mapper   string `protobuf:"bytes,1,opt,name=mapper,proto3" json:"mapper,omitempty"`
+	Reducer  string `protobuf:"bytes,2,opt,name=reducer,proto3" json:"reducer,omitempty"`
+	Combiner string `protobuf:"bytes,3,opt,name=combiner,proto3" json:"combiner,omitempty"` // optional, same input and output as the reducer
 }
 
-func (x *Heartbeat) Reset() {
-	*x = Heartbeat{}
+func (x *Streaming) Reset() {
+	*x = Streaming{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -430,13 +661,13 @@ func (x *Heartbeat) Reset() {
 	}
 }
 
-func (x *Heartbeat) String() string {
+func (x *Streaming) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Heartbeat) ProtoMessage() {}
+func (*Streaming) ProtoMessage() {}
 
-func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+func (x *Streaming) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -448,45 +679,44 @@ func (x *Heartbeat) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
-func (*Heartbeat) Descriptor() ([]byte, []int) {
+// Deprecated: Use Streaming.ProtoReflect.Descriptor instead.
+func (*Streaming) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *Heartbeat) GetChunks() []*Chunk {
+func (x *Streaming) GetMapper() string {
 	if x != nil {
-		return x.Chunks
+		return x.Mapper
 	}
-	return nil
+	return ""
 }
 
-func (x *Heartbeat) GetStorageNode() *Node {
+func (x *Streaming) GetReducer() string {
 	if x != nil {
-		return x.StorageNode
+		return x.Reducer
 	}
-	return nil
+	return ""
 }
 
-func (x *Heartbeat) GetStats() *Stats {
+func (x *Streaming) GetCombiner() string {
 	if x != nil {
-		return x.Stats
+		return x.Combiner
 	}
-	return nil
+	return ""
 }
 
-type Stats struct {
+// records going in and out of the combiners, summed over the mappers
+type Counters struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Downloaded int32 `protobuf:"varint,1,opt,name=downloaded,proto3" json:"downloaded,omitempty"`
-	Uploaded   int32 `protobuf:"varint,2,opt,name=uploaded,proto3" json:"uploaded,omitempty"`
-	Replicated int32 `protobuf:"varint,3,opt,name=replicated,proto3" json:"replicated,omitempty"`
-	FreeSpace  int32 `protobuf:"varint,4,opt,name=free_space,json=freeSpace,proto3" json:"free_space,omitempty"`
+	CombineInputRecords  int64 `protobuf:"varint,1,opt,name=combine_input_records,json=combineInputRecords,proto3" json:"combine_input_records,omitempty"`
+	CombineOutputRecords int64 `protobuf:"varint,2,opt,name=combine_output_records,json=combineOutputRecords,proto3" json:"combine_output_records,omitempty"`
 }
 
-func (x *Stats) Reset() {
-	*x = Stats{}
+func (x *Counters) Reset() {
+	*x = Counters{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -494,13 +724,13 @@ func (x *Stats) Reset() {
 	}
 }
 
-func (x *Stats) String() string {
+func (x *Counters) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Stats) ProtoMessage() {}
+func (*Counters) ProtoMessage() {}
 
-func (x *Stats) ProtoReflect() protoreflect.Message {
+func (x *Counters) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -512,49 +742,36 @@ func (x *Stats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
-func (*Stats) Descriptor() ([]byte, []int) {
+// Deprecated: Use Counters.ProtoReflect.Descriptor instead.
+func (*Counters) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *Stats) GetDownloaded() int32 {
-	if x != nil {
-		return x.Downloaded
-	}
-	return 0
-}
-
-func (x *Stats) GetUploaded() int32 {
-	if x != nil {
-		return x.Uploaded
-	}
-	return 0
-}
-
-func (x *Stats) GetReplicated() int32 {
+func (x *Counters) GetCombineInputRecords() int64 {
 	if x != nil {
-		return x.Replicated
+		return x.CombineInputRecords
 	}
 	return 0
 }
 
-func (x *Stats) GetFreeSpace() int32 {
+func (x *Counters) GetCombineOutputRecords() int64 {
 	if x != nil {
-		return x.FreeSpace
+		return x.CombineOutputRecords
 	}
 	return 0
 }
 
-type Files struct {
+type Registration struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Files []*File `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	Node   *Node    `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	Chunks []*Chunk `protobuf:"bytes,2,rep,name=chunks,proto3" json:"chunks,omitempty"` // full block report, lets the Controller reconcile rejoining nodes
 }
 
-func (x *Files) Reset() {
-	*x = Files{}
+func (x *Registration) Reset() {
+	*x = Registration{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -562,13 +779,13 @@ func (x *Files) Reset() {
 	}
 }
 
-func (x *Files) String() string {
+func (x *Registration) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Files) ProtoMessage() {}
+func (*Registration) ProtoMessage() {}
 
-func (x *Files) ProtoReflect() protoreflect.Message {
+func (x *Registration) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -580,30 +797,40 @@ func (x *Files) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Files.ProtoReflect.Descriptor instead.
-func (*Files) Descriptor() ([]byte, []int) {
+// Deprecated: Use Registration.ProtoReflect.Descriptor instead.
+func (*Registration) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *Files) GetFiles() []*File {
+func (x *Registration) GetNode() *Node {
 	if x != nil {
-		return x.Files
+		return x.Node
 	}
 	return nil
 }
 
-type File struct {
+func (x *Registration) GetChunks() []*Chunk {
+	if x != nil {
+		return x.Chunks
+	}
+	return nil
+}
+
+// Storage Node heartbeat to Controller;
+// It will send its File Index on each heartbeat.
+// Not ideal.
+type Heartbeat struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name    string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Dirname string   `protobuf:"bytes,2,opt,name=dirname,proto3" json:"dirname,omitempty"`
-	Chunks  []*Chunk `protobuf:"bytes,3,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	Chunks      []*Chunk `protobuf:"bytes,1,rep,name=Chunks,proto3" json:"Chunks,omitempty"`
+	StorageNode *Node    `protobuf:"bytes,2,opt,name=storage_node,json=storageNode,proto3" json:"storage_node,omitempty"`
+	Stats       *Stats   `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
 }
 
-func (x *File) Reset() {
-	*x = File{}
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -611,13 +838,13 @@ func (x *File) Reset() {
 	}
 }
 
-func (x *File) String() string {
+func (x *Heartbeat) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*File) ProtoMessage() {}
+func (*Heartbeat) ProtoMessage() {}
 
-func (x *File) ProtoReflect() protoreflect.Message {
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -629,49 +856,50 @@ func (x *File) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use File.ProtoReflect.Descriptor instead.
-func (*File) Descriptor() ([]byte, []int) {
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *File) GetName() string {
+func (x *Heartbeat) GetChunks() []*Chunk {
 	if x != nil {
-		return x.Name
+		return x.Chunks
 	}
-	return ""
+	return nil
 }
 
-func (x *File) GetDirname() string {
+func (x *Heartbeat) GetStorageNode() *Node {
 	if x != nil {
-		return x.Dirname
+		return x.StorageNode
 	}
-	return ""
+	return nil
 }
 
-func (x *File) GetChunks() []*Chunk {
+func (x *Heartbeat) GetStats() *Stats {
 	if x != nil {
-		return x.Chunks
+		return x.Stats
 	}
 	return nil
 }
 
-type Chunk struct {
+type Stats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FileName     string           `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	ChunkName    string           `protobuf:"bytes,2,opt,name=chunk_name,json=chunkName,proto3" json:"chunk_name,omitempty"`
-	Serial       int32            `protobuf:"varint,3,opt,name=serial,proto3" json:"serial,omitempty"`
-	Size         int64            `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
-	Data         []byte           `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
-	StorageNodes map[string]*Node `protobuf:"bytes,6,rep,name=storage_nodes,json=storageNodes,proto3" json:"storage_nodes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	Offset       int32            `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
-	FileSize     int32            `protobuf:"varint,8,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	Downloaded int32        `protobuf:"varint,1,opt,name=downloaded,proto3" json:"downloaded,omitempty"`
+	Uploaded   int32        `protobuf:"varint,2,opt,name=uploaded,proto3" json:"uploaded,omitempty"`
+	Replicated int32        `protobuf:"varint,3,opt,name=replicated,proto3" json:"replicated,omitempty"`
+	FreeSpace  int32        `protobuf:"varint,4,opt,name=free_space,json=freeSpace,proto3" json:"free_space,omitempty"` // GB available for chunks
+	Capacity   int64        `protobuf:"varint,5,opt,name=capacity,proto3" json:"capacity,omitempty"`                    // bytes, all storage dirs
+	Used       int64        `protobuf:"varint,6,opt,name=used,proto3" json:"used,omitempty"`
+	Reserved   int64        `protobuf:"varint,7,opt,name=reserved,proto3" json:"reserved,omitempty"`   // kept free; chunks are refused below it
+	Available  int64        `protobuf:"varint,8,opt,name=available,proto3" json:"available,omitempty"` // bytes left for chunks (free space minus reserve)
+	Disks      []*DiskStats `protobuf:"bytes,9,rep,name=disks,proto3" json:"disks,omitempty"`
 }
 
-func (x *Chunk) Reset() {
-	*x = Chunk{}
+func (x *Stats) Reset() {
+	*x = Stats{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -679,13 +907,13 @@ func (x *Chunk) Reset() {
 	}
 }
 
-func (x *Chunk) String() string {
+func (x *Stats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Chunk) ProtoMessage() {}
+func (*Stats) ProtoMessage() {}
 
-func (x *Chunk) ProtoReflect() protoreflect.Message {
+func (x *Stats) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -697,80 +925,88 @@ func (x *Chunk) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Chunk.ProtoReflect.Descriptor instead.
-func (*Chunk) Descriptor() ([]byte, []int) {
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *Chunk) GetFileName() string {
+func (x *Stats) GetDownloaded() int32 {
 	if x != nil {
-		return x.FileName
+		return x.Downloaded
 	}
-	return ""
+	return 0
 }
 
-func (x *Chunk) GetChunkName() string {
+func (x *Stats) GetUploaded() int32 {
 	if x != nil {
-		return x.ChunkName
+		return x.Uploaded
 	}
-	return ""
+	return 0
 }
 
-func (x *Chunk) GetSerial() int32 {
+func (x *Stats) GetReplicated() int32 {
 	if x != nil {
-		return x.Serial
+		return x.Replicated
 	}
 	return 0
 }
 
-func (x *Chunk) GetSize() int64 {
+func (x *Stats) GetFreeSpace() int32 {
 	if x != nil {
-		return x.Size
+		return x.FreeSpace
 	}
 	return 0
 }
 
-func (x *Chunk) GetData() []byte {
+func (x *Stats) GetCapacity() int64 {
 	if x != nil {
-		return x.Data
+		return x.Capacity
 	}
-	return nil
+	return 0
 }
 
-func (x *Chunk) GetStorageNodes() map[string]*Node {
+func (x *Stats) GetUsed() int64 {
 	if x != nil {
-		return x.StorageNodes
+		return x.Used
 	}
-	return nil
+	return 0
 }
 
-func (x *Chunk) GetOffset() int32 {
+func (x *Stats) GetReserved() int64 {
 	if x != nil {
-		return x.Offset
+		return x.Reserved
 	}
 	return 0
 }
 
-func (x *Chunk) GetFileSize() int32 {
+func (x *Stats) GetAvailable() int64 {
 	if x != nil {
-		return x.FileSize
+		return x.Available
 	}
 	return 0
 }
 
-type Node struct {
+func (x *Stats) GetDisks() []*DiskStats {
+	if x != nil {
+		return x.Disks
+	}
+	return nil
+}
+
+type DiskStats struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Uuid     string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	Hostname string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Port     int32  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
-	Stats    *Stats `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	Dir       string `protobuf:"bytes,1,opt,name=dir,proto3" json:"dir,omitempty"`
+	Capacity  int64  `protobuf:"varint,2,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	Used      int64  `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`
+	Reserved  int64  `protobuf:"varint,4,opt,name=reserved,proto3" json:"reserved,omitempty"`
+	Available int64  `protobuf:"varint,5,opt,name=available,proto3" json:"available,omitempty"`
 }
 
-func (x *Node) Reset() {
-	*x = Node{}
+func (x *DiskStats) Reset() {
+	*x = DiskStats{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -778,13 +1014,13 @@ func (x *Node) Reset() {
 	}
 }
 
-func (x *Node) String() string {
+func (x *DiskStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Node) ProtoMessage() {}
+func (*DiskStats) ProtoMessage() {}
 
-func (x *Node) ProtoReflect() protoreflect.Message {
+func (x *DiskStats) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -796,49 +1032,57 @@ func (x *Node) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Node.ProtoReflect.Descriptor instead.
-func (*Node) Descriptor() ([]byte, []int) {
+// Deprecated: Use DiskStats.ProtoReflect.Descriptor instead.
+func (*DiskStats) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *Node) GetUuid() string {
+func (x *DiskStats) GetDir() string {
 	if x != nil {
-		return x.Uuid
+		return x.Dir
 	}
 	return ""
 }
 
-func (x *Node) GetHostname() string {
+func (x *DiskStats) GetCapacity() int64 {
 	if x != nil {
-		return x.Hostname
+		return x.Capacity
 	}
-	return ""
+	return 0
 }
 
-func (x *Node) GetPort() int32 {
+func (x *DiskStats) GetUsed() int64 {
 	if x != nil {
-		return x.Port
+		return x.Used
 	}
 	return 0
 }
 
-func (x *Node) GetStats() *Stats {
+func (x *DiskStats) GetReserved() int64 {
 	if x != nil {
-		return x.Stats
+		return x.Reserved
 	}
-	return nil
+	return 0
 }
 
-type StorageNodes struct {
+func (x *DiskStats) GetAvailable() int64 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+type Files struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Nodes []*Node `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Files  []*File  `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	Quotas []*Quota `protobuf:"bytes,2,rep,name=quotas,proto3" json:"quotas,omitempty"`
 }
 
-func (x *StorageNodes) Reset() {
-	*x = StorageNodes{}
+func (x *Files) Reset() {
+	*x = Files{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -846,13 +1090,13 @@ func (x *StorageNodes) Reset() {
 	}
 }
 
-func (x *StorageNodes) String() string {
+func (x *Files) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StorageNodes) ProtoMessage() {}
+func (*Files) ProtoMessage() {}
 
-func (x *StorageNodes) ProtoReflect() protoreflect.Message {
+func (x *Files) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -864,29 +1108,39 @@ func (x *StorageNodes) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StorageNodes.ProtoReflect.Descriptor instead.
-func (*StorageNodes) Descriptor() ([]byte, []int) {
+// Deprecated: Use Files.ProtoReflect.Descriptor instead.
+func (*Files) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *StorageNodes) GetNodes() []*Node {
+func (x *Files) GetFiles() []*File {
 	if x != nil {
-		return x.Nodes
+		return x.Files
 	}
 	return nil
 }
 
-type Ack struct {
+func (x *Files) GetQuotas() []*Quota {
+	if x != nil {
+		return x.Quotas
+	}
+	return nil
+}
+
+type Quota struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Ok           bool   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
-	ErrorMessage string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Dirname  string `protobuf:"bytes,1,opt,name=dirname,proto3" json:"dirname,omitempty"`
+	MaxFiles int64  `protobuf:"varint,2,opt,name=max_files,json=maxFiles,proto3" json:"max_files,omitempty"` // 0 means no limit
+	MaxBytes int64  `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	Files    int64  `protobuf:"varint,4,opt,name=files,proto3" json:"files,omitempty"` // usage, pending uploads included
+	Bytes    int64  `protobuf:"varint,5,opt,name=bytes,proto3" json:"bytes,omitempty"`
 }
 
-func (x *Ack) Reset() {
-	*x = Ack{}
+func (x *Quota) Reset() {
+	*x = Quota{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -894,13 +1148,13 @@ func (x *Ack) Reset() {
 	}
 }
 
-func (x *Ack) String() string {
+func (x *Quota) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Ack) ProtoMessage() {}
+func (*Quota) ProtoMessage() {}
 
-func (x *Ack) ProtoReflect() protoreflect.Message {
+func (x *Quota) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -912,38 +1166,60 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
-func (*Ack) Descriptor() ([]byte, []int) {
+// Deprecated: Use Quota.ProtoReflect.Descriptor instead.
+func (*Quota) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Ack) GetOk() bool {
+func (x *Quota) GetDirname() string {
 	if x != nil {
-		return x.Ok
+		return x.Dirname
 	}
-	return false
+	return ""
 }
 
-func (x *Ack) GetErrorMessage() string {
+func (x *Quota) GetMaxFiles() int64 {
 	if x != nil {
-		return x.ErrorMessage
+		return x.MaxFiles
 	}
-	return ""
+	return 0
 }
 
-type ComputationStatus struct {
+func (x *Quota) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *Quota) GetFiles() int64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *Quota) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+type File struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Ok           bool             `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
-	ErrorMessage string           `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
-	Status       JobStatus        `protobuf:"varint,3,opt,name=status,proto3,enum=JobStatus" json:"status,omitempty"`
-	FilesTable   map[string]*Node `protobuf:"bytes,4,rep,name=files_table,json=filesTable,proto3" json:"files_table,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Name          string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Dirname       string   `protobuf:"bytes,2,opt,name=dirname,proto3" json:"dirname,omitempty"`
+	Chunks        []*Chunk `protobuf:"bytes,3,rep,name=chunks,proto3" json:"chunks,omitempty"`
+	Replication   int32    `protobuf:"varint,4,opt,name=replication,proto3" json:"replication,omitempty"`
+	StoragePolicy string   `protobuf:"bytes,5,opt,name=storage_policy,json=storagePolicy,proto3" json:"storage_policy,omitempty"`
 }
 
-func (x *ComputationStatus) Reset() {
-	*x = ComputationStatus{}
+func (x *File) Reset() {
+	*x = File{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -951,13 +1227,13 @@ func (x *ComputationStatus) Reset() {
 	}
 }
 
-func (x *ComputationStatus) String() string {
+func (x *File) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ComputationStatus) ProtoMessage() {}
+func (*File) ProtoMessage() {}
 
-func (x *ComputationStatus) ProtoReflect() protoreflect.Message {
+func (x *File) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -969,64 +1245,78 @@ func (x *ComputationStatus) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ComputationStatus.ProtoReflect.Descriptor instead.
-func (*ComputationStatus) Descriptor() ([]byte, []int) {
+// Deprecated: Use File.ProtoReflect.Descriptor instead.
+func (*File) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *ComputationStatus) GetOk() bool {
+func (x *File) GetName() string {
 	if x != nil {
-		return x.Ok
+		return x.Name
 	}
-	return false
+	return ""
 }
 
-func (x *ComputationStatus) GetErrorMessage() string {
+func (x *File) GetDirname() string {
 	if x != nil {
-		return x.ErrorMessage
+		return x.Dirname
 	}
 	return ""
 }
 
-func (x *ComputationStatus) GetStatus() JobStatus {
+func (x *File) GetChunks() []*Chunk {
 	if x != nil {
-		return x.Status
+		return x.Chunks
 	}
-	return JobStatus_job_accepted
+	return nil
 }
 
-func (x *ComputationStatus) GetFilesTable() map[string]*Node {
+func (x *File) GetReplication() int32 {
 	if x != nil {
-		return x.FilesTable
+		return x.Replication
 	}
-	return nil
+	return 0
 }
 
-type Wrapper struct {
+func (x *File) GetStoragePolicy() string {
+	if x != nil {
+		return x.StoragePolicy
+	}
+	return ""
+}
+
+type Chunk struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// should have added here
-	// bool ok
-	// string error_message
-	// So I don't have to juggle between Ack Messages and the expected message
-	//
-	// Types that are assignable to Msg:
-	//	*Wrapper_RegistrationMessage
-	//	*Wrapper_HeartbeatMessage
-	//	*Wrapper_FilesMessage
-	//	*Wrapper_FileMessage
-	//	*Wrapper_StorageNodesMessage
-	//	*Wrapper_ActionRequestMessage
-	//	*Wrapper_ChunkMessage
-	//	*Wrapper_ComputationStatusMessage
-	//	*Wrapper_AckMessage
-	Msg isWrapper_Msg `protobuf_oneof:"msg"`
+	FileName     string           `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	ChunkName    string           `protobuf:"bytes,2,opt,name=chunk_name,json=chunkName,proto3" json:"chunk_name,omitempty"`
+	Serial       int32            `protobuf:"varint,3,opt,name=serial,proto3" json:"serial,omitempty"`
+	Size         int64            `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Data         []byte           `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
+	StorageNodes map[string]*Node `protobuf:"bytes,6,rep,name=storage_nodes,json=storageNodes,proto3" json:"storage_nodes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Offset       int32            `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	FileSize     int32            `protobuf:"varint,8,opt,name=file_size,json=fileSize,proto3" json:"file_size,omitempty"`
+	Replication  int32            `protobuf:"varint,9,opt,name=replication,proto3" json:"replication,omitempty"`
+	// erasure coding; data_shards is 0 for replicated files
+	Stripe       int32   `protobuf:"varint,10,opt,name=stripe,proto3" json:"stripe,omitempty"`
+	StripeIndex  int32   `protobuf:"varint,11,opt,name=stripe_index,json=stripeIndex,proto3" json:"stripe_index,omitempty"` // >= data_shards means parity chunk
+	StripeWidth  int32   `protobuf:"varint,12,opt,name=stripe_width,json=stripeWidth,proto3" json:"stripe_width,omitempty"` // number of real data chunks in stripe
+	DataShards   int32   `protobuf:"varint,13,opt,name=data_shards,json=dataShards,proto3" json:"data_shards,omitempty"`
+	ParityShards int32   `protobuf:"varint,14,opt,name=parity_shards,json=parityShards,proto3" json:"parity_shards,omitempty"`
+	ShardSize    int64   `protobuf:"varint,15,opt,name=shard_size,json=shardSize,proto3" json:"shard_size,omitempty"`
+	StripeSizes  []int64 `protobuf:"varint,16,rep,packed,name=stripe_sizes,json=stripeSizes,proto3" json:"stripe_sizes,omitempty"` // sizes of the data chunks in stripe
+	Codec        string  `protobuf:"bytes,17,opt,name=codec,proto3" json:"codec,omitempty"`                                        // compression codec applied to data; empty means raw
+	RawSize      int64   `protobuf:"varint,18,opt,name=raw_size,json=rawSize,proto3" json:"raw_size,omitempty"`                    // size of data once decompressed
+	Encrypted    bool    `protobuf:"varint,19,opt,name=encrypted,proto3" json:"encrypted,omitempty"`                               // data sealed at rest by the storage node holding it
+	Checksum     uint32  `protobuf:"varint,20,opt,name=checksum,proto3" json:"checksum,omitempty"`                                 // crc32 of data as stored; 0 for chunks stored before checksums
+	Corrupt      bool    `protobuf:"varint,21,opt,name=corrupt,proto3" json:"corrupt,omitempty"`                                   // block reports: replica failed verification on the reporting node
+	TotalChunks  int32   `protobuf:"varint,22,opt,name=total_chunks,json=totalChunks,proto3" json:"total_chunks,omitempty"`        // data chunks the file was split into at upload; 0 for chunks stored before it was recorded
 }
 
-func (x *Wrapper) Reset() {
-	*x = Wrapper{}
+func (x *Chunk) Reset() {
+	*x = Chunk{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_dfs_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1034,13 +1324,13 @@ func (x *Wrapper) Reset() {
 	}
 }
 
-func (x *Wrapper) String() string {
+func (x *Chunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Wrapper) ProtoMessage() {}
+func (*Chunk) ProtoMessage() {}
 
-func (x *Wrapper) ProtoReflect() protoreflect.Message {
+func (x *Chunk) ProtoReflect() protoreflect.Message {
 	mi := &file_dfs_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1052,37 +1342,623 @@ func (x *Wrapper) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Wrapper.ProtoReflect.Descriptor instead.
-func (*Wrapper) Descriptor() ([]byte, []int) {
+// Deprecated: Use Chunk.ProtoReflect.Descriptor instead.
+func (*Chunk) Descriptor() ([]byte, []int) {
 	return file_dfs_proto_rawDescGZIP(), []int{12}
 }
 
-func (m *Wrapper) GetMsg() isWrapper_Msg {
-	if m != nil {
-		return m.Msg
+func (x *Chunk) GetFileName() string {
+	if x != nil {
+		return x.FileName
 	}
-	return nil
+	return ""
 }
 
-func (x *Wrapper) GetRegistrationMessage() *Registration {
-	if x, ok := x.GetMsg().(*Wrapper_RegistrationMessage); ok {
-		return x.RegistrationMessage
+func (x *Chunk) GetChunkName() string {
+	if x != nil {
+		return x.ChunkName
 	}
-	return nil
+	return ""
 }
 
-func (x *Wrapper) GetHeartbeatMessage() *Heartbeat {
-	if x, ok := x.GetMsg().(*Wrapper_HeartbeatMessage); ok {
-		return x.HeartbeatMessage
+func (x *Chunk) GetSerial() int32 {
+	if x != nil {
+		return x.Serial
 	}
-	return nil
+	return 0
 }
 
-func (x *Wrapper) GetFilesMessage() *Files {
-	if x, ok := x.GetMsg().(*Wrapper_FilesMessage); ok {
-		return x.FilesMessage
+func (x *Chunk) GetSize() int64 {
+	if x != nil {
+		return x.Size
 	}
-	return nil
+	return 0
+}
+
+func (x *Chunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *Chunk) GetStorageNodes() map[string]*Node {
+	if x != nil {
+		return x.StorageNodes
+	}
+	return nil
+}
+
+func (x *Chunk) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Chunk) GetFileSize() int32 {
+	if x != nil {
+		return x.FileSize
+	}
+	return 0
+}
+
+func (x *Chunk) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *Chunk) GetStripe() int32 {
+	if x != nil {
+		return x.Stripe
+	}
+	return 0
+}
+
+func (x *Chunk) GetStripeIndex() int32 {
+	if x != nil {
+		return x.StripeIndex
+	}
+	return 0
+}
+
+func (x *Chunk) GetStripeWidth() int32 {
+	if x != nil {
+		return x.StripeWidth
+	}
+	return 0
+}
+
+func (x *Chunk) GetDataShards() int32 {
+	if x != nil {
+		return x.DataShards
+	}
+	return 0
+}
+
+func (x *Chunk) GetParityShards() int32 {
+	if x != nil {
+		return x.ParityShards
+	}
+	return 0
+}
+
+func (x *Chunk) GetShardSize() int64 {
+	if x != nil {
+		return x.ShardSize
+	}
+	return 0
+}
+
+func (x *Chunk) GetStripeSizes() []int64 {
+	if x != nil {
+		return x.StripeSizes
+	}
+	return nil
+}
+
+func (x *Chunk) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *Chunk) GetRawSize() int64 {
+	if x != nil {
+		return x.RawSize
+	}
+	return 0
+}
+
+func (x *Chunk) GetEncrypted() bool {
+	if x != nil {
+		return x.Encrypted
+	}
+	return false
+}
+
+func (x *Chunk) GetChecksum() uint32 {
+	if x != nil {
+		return x.Checksum
+	}
+	return 0
+}
+
+func (x *Chunk) GetCorrupt() bool {
+	if x != nil {
+		return x.Corrupt
+	}
+	return false
+}
+
+func (x *Chunk) GetTotalChunks() int32 {
+	if x != nil {
+		return x.TotalChunks
+	}
+	return 0
+}
+
+type Node struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uuid          string `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Hostname      string `protobuf:"bytes,2,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Port          int32  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Stats         *Stats `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	State         string `protobuf:"bytes,5,opt,name=state,proto3" json:"state,omitempty"`                                         // online/draining/decommissioned
+	ChunksToDrain int64  `protobuf:"varint,6,opt,name=chunks_to_drain,json=chunksToDrain,proto3" json:"chunks_to_drain,omitempty"` // chunks still lacking copies on other nodes while draining
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Node) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *Node) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *Node) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *Node) GetStats() *Stats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *Node) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Node) GetChunksToDrain() int64 {
+	if x != nil {
+		return x.ChunksToDrain
+	}
+	return 0
+}
+
+type StorageNodes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nodes         []*Node   `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	StoragePolicy string    `protobuf:"bytes,2,opt,name=storage_policy,json=storagePolicy,proto3" json:"storage_policy,omitempty"` // PUT response: policy the file must be stored with
+	SafeMode      *SafeMode `protobuf:"bytes,3,opt,name=safe_mode,json=safeMode,proto3" json:"safe_mode,omitempty"`                // cluster stats response
+}
+
+func (x *StorageNodes) Reset() {
+	*x = StorageNodes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageNodes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageNodes) ProtoMessage() {}
+
+func (x *StorageNodes) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageNodes.ProtoReflect.Descriptor instead.
+func (*StorageNodes) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StorageNodes) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *StorageNodes) GetStoragePolicy() string {
+	if x != nil {
+		return x.StoragePolicy
+	}
+	return ""
+}
+
+func (x *StorageNodes) GetSafeMode() *SafeMode {
+	if x != nil {
+		return x.SafeMode
+	}
+	return nil
+}
+
+type SafeMode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	On             bool    `protobuf:"varint,1,opt,name=on,proto3" json:"on,omitempty"`
+	Manual         bool    `protobuf:"varint,2,opt,name=manual,proto3" json:"manual,omitempty"`                                       // entered by an admin; only an admin can leave it
+	ReportedChunks int64   `protobuf:"varint,3,opt,name=reported_chunks,json=reportedChunks,proto3" json:"reported_chunks,omitempty"` // chunks with at least one replica reported
+	ExpectedChunks int64   `protobuf:"varint,4,opt,name=expected_chunks,json=expectedChunks,proto3" json:"expected_chunks,omitempty"`
+	Threshold      float64 `protobuf:"fixed64,5,opt,name=threshold,proto3" json:"threshold,omitempty"` // fraction of expected chunks needed to leave automatically
+}
+
+func (x *SafeMode) Reset() {
+	*x = SafeMode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SafeMode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SafeMode) ProtoMessage() {}
+
+func (x *SafeMode) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SafeMode.ProtoReflect.Descriptor instead.
+func (*SafeMode) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SafeMode) GetOn() bool {
+	if x != nil {
+		return x.On
+	}
+	return false
+}
+
+func (x *SafeMode) GetManual() bool {
+	if x != nil {
+		return x.Manual
+	}
+	return false
+}
+
+func (x *SafeMode) GetReportedChunks() int64 {
+	if x != nil {
+		return x.ReportedChunks
+	}
+	return 0
+}
+
+func (x *SafeMode) GetExpectedChunks() int64 {
+	if x != nil {
+		return x.ExpectedChunks
+	}
+	return 0
+}
+
+func (x *SafeMode) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+type Ack struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ok           bool   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	ErrorMessage string `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Ack) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *Ack) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+type ComputationStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ok           bool             `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	ErrorMessage string           `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Status       JobStatus        `protobuf:"varint,3,opt,name=status,proto3,enum=JobStatus" json:"status,omitempty"`
+	FilesTable   map[string]*Node `protobuf:"bytes,4,rep,name=files_table,json=filesTable,proto3" json:"files_table,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Counters     *Counters        `protobuf:"bytes,5,opt,name=counters,proto3" json:"counters,omitempty"`
+	JobId        string           `protobuf:"bytes,6,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"` // job_queued: id the compute engine gave the job
+}
+
+func (x *ComputationStatus) Reset() {
+	*x = ComputationStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComputationStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComputationStatus) ProtoMessage() {}
+
+func (x *ComputationStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComputationStatus.ProtoReflect.Descriptor instead.
+func (*ComputationStatus) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ComputationStatus) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *ComputationStatus) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *ComputationStatus) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_job_accepted
+}
+
+func (x *ComputationStatus) GetFilesTable() map[string]*Node {
+	if x != nil {
+		return x.FilesTable
+	}
+	return nil
+}
+
+func (x *ComputationStatus) GetCounters() *Counters {
+	if x != nil {
+		return x.Counters
+	}
+	return nil
+}
+
+func (x *ComputationStatus) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type Wrapper struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// should have added here
+	// bool ok
+	// string error_message
+	// So I don't have to juggle between Ack Messages and the expected message
+	//
+	// Types that are assignable to Msg:
+	//	*Wrapper_RegistrationMessage
+	//	*Wrapper_HeartbeatMessage
+	//	*Wrapper_FilesMessage
+	//	*Wrapper_FileMessage
+	//	*Wrapper_StorageNodesMessage
+	//	*Wrapper_ActionRequestMessage
+	//	*Wrapper_ChunkMessage
+	//	*Wrapper_ComputationStatusMessage
+	//	*Wrapper_AckMessage
+	//	*Wrapper_VoteRequestMessage
+	//	*Wrapper_VoteResponseMessage
+	//	*Wrapper_AppendEntriesMessage
+	//	*Wrapper_AppendEntriesResponseMessage
+	//	*Wrapper_FsckReportMessage
+	//	*Wrapper_SamplesMessage
+	Msg isWrapper_Msg `protobuf_oneof:"msg"`
+}
+
+func (x *Wrapper) Reset() {
+	*x = Wrapper{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Wrapper) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wrapper) ProtoMessage() {}
+
+func (x *Wrapper) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wrapper.ProtoReflect.Descriptor instead.
+func (*Wrapper) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{18}
+}
+
+func (m *Wrapper) GetMsg() isWrapper_Msg {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (x *Wrapper) GetRegistrationMessage() *Registration {
+	if x, ok := x.GetMsg().(*Wrapper_RegistrationMessage); ok {
+		return x.RegistrationMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetHeartbeatMessage() *Heartbeat {
+	if x, ok := x.GetMsg().(*Wrapper_HeartbeatMessage); ok {
+		return x.HeartbeatMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetFilesMessage() *Files {
+	if x, ok := x.GetMsg().(*Wrapper_FilesMessage); ok {
+		return x.FilesMessage
+	}
+	return nil
 }
 
 func (x *Wrapper) GetFileMessage() *File {
@@ -1092,103 +1968,752 @@ func (x *Wrapper) GetFileMessage() *File {
 	return nil
 }
 
-func (x *Wrapper) GetStorageNodesMessage() *StorageNodes {
-	if x, ok := x.GetMsg().(*Wrapper_StorageNodesMessage); ok {
-		return x.StorageNodesMessage
+func (x *Wrapper) GetStorageNodesMessage() *StorageNodes {
+	if x, ok := x.GetMsg().(*Wrapper_StorageNodesMessage); ok {
+		return x.StorageNodesMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetActionRequestMessage() *ActionRequest {
+	if x, ok := x.GetMsg().(*Wrapper_ActionRequestMessage); ok {
+		return x.ActionRequestMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetChunkMessage() *Chunk {
+	if x, ok := x.GetMsg().(*Wrapper_ChunkMessage); ok {
+		return x.ChunkMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetComputationStatusMessage() *ComputationStatus {
+	if x, ok := x.GetMsg().(*Wrapper_ComputationStatusMessage); ok {
+		return x.ComputationStatusMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetAckMessage() *Ack {
+	if x, ok := x.GetMsg().(*Wrapper_AckMessage); ok {
+		return x.AckMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetVoteRequestMessage() *VoteRequest {
+	if x, ok := x.GetMsg().(*Wrapper_VoteRequestMessage); ok {
+		return x.VoteRequestMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetVoteResponseMessage() *VoteResponse {
+	if x, ok := x.GetMsg().(*Wrapper_VoteResponseMessage); ok {
+		return x.VoteResponseMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetAppendEntriesMessage() *AppendEntries {
+	if x, ok := x.GetMsg().(*Wrapper_AppendEntriesMessage); ok {
+		return x.AppendEntriesMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetAppendEntriesResponseMessage() *AppendEntriesResponse {
+	if x, ok := x.GetMsg().(*Wrapper_AppendEntriesResponseMessage); ok {
+		return x.AppendEntriesResponseMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetFsckReportMessage() *FsckReport {
+	if x, ok := x.GetMsg().(*Wrapper_FsckReportMessage); ok {
+		return x.FsckReportMessage
+	}
+	return nil
+}
+
+func (x *Wrapper) GetSamplesMessage() *Samples {
+	if x, ok := x.GetMsg().(*Wrapper_SamplesMessage); ok {
+		return x.SamplesMessage
+	}
+	return nil
+}
+
+type isWrapper_Msg interface {
+	isWrapper_Msg()
+}
+
+type Wrapper_RegistrationMessage struct {
+	RegistrationMessage *Registration `protobuf:"bytes,1,opt,name=registration_message,json=registrationMessage,proto3,oneof"`
+}
+
+type Wrapper_HeartbeatMessage struct {
+	HeartbeatMessage *Heartbeat `protobuf:"bytes,2,opt,name=heartbeat_message,json=heartbeatMessage,proto3,oneof"`
+}
+
+type Wrapper_FilesMessage struct {
+	FilesMessage *Files `protobuf:"bytes,3,opt,name=files_message,json=filesMessage,proto3,oneof"`
+}
+
+type Wrapper_FileMessage struct {
+	FileMessage *File `protobuf:"bytes,4,opt,name=file_message,json=fileMessage,proto3,oneof"`
+}
+
+type Wrapper_StorageNodesMessage struct {
+	StorageNodesMessage *StorageNodes `protobuf:"bytes,5,opt,name=storage_nodes_message,json=storageNodesMessage,proto3,oneof"`
+}
+
+type Wrapper_ActionRequestMessage struct {
+	ActionRequestMessage *ActionRequest `protobuf:"bytes,6,opt,name=action_request_message,json=actionRequestMessage,proto3,oneof"`
+}
+
+type Wrapper_ChunkMessage struct {
+	ChunkMessage *Chunk `protobuf:"bytes,7,opt,name=chunk_message,json=chunkMessage,proto3,oneof"`
+}
+
+type Wrapper_ComputationStatusMessage struct {
+	ComputationStatusMessage *ComputationStatus `protobuf:"bytes,8,opt,name=computation_status_message,json=computationStatusMessage,proto3,oneof"`
+}
+
+type Wrapper_AckMessage struct {
+	AckMessage *Ack `protobuf:"bytes,9,opt,name=ack_message,json=ackMessage,proto3,oneof"`
+}
+
+type Wrapper_VoteRequestMessage struct {
+	VoteRequestMessage *VoteRequest `protobuf:"bytes,10,opt,name=vote_request_message,json=voteRequestMessage,proto3,oneof"`
+}
+
+type Wrapper_VoteResponseMessage struct {
+	VoteResponseMessage *VoteResponse `protobuf:"bytes,11,opt,name=vote_response_message,json=voteResponseMessage,proto3,oneof"`
+}
+
+type Wrapper_AppendEntriesMessage struct {
+	AppendEntriesMessage *AppendEntries `protobuf:"bytes,12,opt,name=append_entries_message,json=appendEntriesMessage,proto3,oneof"`
+}
+
+type Wrapper_AppendEntriesResponseMessage struct {
+	AppendEntriesResponseMessage *AppendEntriesResponse `protobuf:"bytes,13,opt,name=append_entries_response_message,json=appendEntriesResponseMessage,proto3,oneof"`
+}
+
+type Wrapper_FsckReportMessage struct {
+	FsckReportMessage *FsckReport `protobuf:"bytes,14,opt,name=fsck_report_message,json=fsckReportMessage,proto3,oneof"`
+}
+
+type Wrapper_SamplesMessage struct {
+	SamplesMessage *Samples `protobuf:"bytes,15,opt,name=samples_message,json=samplesMessage,proto3,oneof"`
+}
+
+func (*Wrapper_RegistrationMessage) isWrapper_Msg() {}
+
+func (*Wrapper_HeartbeatMessage) isWrapper_Msg() {}
+
+func (*Wrapper_FilesMessage) isWrapper_Msg() {}
+
+func (*Wrapper_FileMessage) isWrapper_Msg() {}
+
+func (*Wrapper_StorageNodesMessage) isWrapper_Msg() {}
+
+func (*Wrapper_ActionRequestMessage) isWrapper_Msg() {}
+
+func (*Wrapper_ChunkMessage) isWrapper_Msg() {}
+
+func (*Wrapper_ComputationStatusMessage) isWrapper_Msg() {}
+
+func (*Wrapper_AckMessage) isWrapper_Msg() {}
+
+func (*Wrapper_VoteRequestMessage) isWrapper_Msg() {}
+
+func (*Wrapper_VoteResponseMessage) isWrapper_Msg() {}
+
+func (*Wrapper_AppendEntriesMessage) isWrapper_Msg() {}
+
+func (*Wrapper_AppendEntriesResponseMessage) isWrapper_Msg() {}
+
+func (*Wrapper_FsckReportMessage) isWrapper_Msg() {}
+
+func (*Wrapper_SamplesMessage) isWrapper_Msg() {}
+
+// keys of the input lines (text up to the first tab) picked at random from a chunk
+type Samples struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keys []string `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+}
+
+func (x *Samples) Reset() {
+	*x = Samples{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Samples) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Samples) ProtoMessage() {}
+
+func (x *Samples) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Samples.ProtoReflect.Descriptor instead.
+func (*Samples) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Samples) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type FsckReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Files         []*FsckFile `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"` // unhealthy files only
+	FilesChecked  int32       `protobuf:"varint,2,opt,name=files_checked,json=filesChecked,proto3" json:"files_checked,omitempty"`
+	ChunksChecked int64       `protobuf:"varint,3,opt,name=chunks_checked,json=chunksChecked,proto3" json:"chunks_checked,omitempty"`
+	Repairs       []string    `protobuf:"bytes,4,rep,name=repairs,proto3" json:"repairs,omitempty"` // what repair did or could not do
+}
+
+func (x *FsckReport) Reset() {
+	*x = FsckReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsckReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckReport) ProtoMessage() {}
+
+func (x *FsckReport) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckReport.ProtoReflect.Descriptor instead.
+func (*FsckReport) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FsckReport) GetFiles() []*FsckFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *FsckReport) GetFilesChecked() int32 {
+	if x != nil {
+		return x.FilesChecked
+	}
+	return 0
+}
+
+func (x *FsckReport) GetChunksChecked() int64 {
+	if x != nil {
+		return x.ChunksChecked
+	}
+	return 0
+}
+
+func (x *FsckReport) GetRepairs() []string {
+	if x != nil {
+		return x.Repairs
+	}
+	return nil
+}
+
+type FsckFile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FileName        string   `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Replication     int32    `protobuf:"varint,2,opt,name=replication,proto3" json:"replication,omitempty"`
+	StoragePolicy   string   `protobuf:"bytes,3,opt,name=storage_policy,json=storagePolicy,proto3" json:"storage_policy,omitempty"`
+	MissingChunks   []string `protobuf:"bytes,4,rep,name=missing_chunks,json=missingChunks,proto3" json:"missing_chunks,omitempty"` // known chunks without live replicas
+	UnderReplicated []string `protobuf:"bytes,5,rep,name=under_replicated,json=underReplicated,proto3" json:"under_replicated,omitempty"`
+	OverReplicated  []string `protobuf:"bytes,6,rep,name=over_replicated,json=overReplicated,proto3" json:"over_replicated,omitempty"`
+	CorruptReplicas []string `protobuf:"bytes,7,rep,name=corrupt_replicas,json=corruptReplicas,proto3" json:"corrupt_replicas,omitempty"`      // <chunk name>@<storage node uuid>
+	MissingSerials  []int32  `protobuf:"varint,8,rep,packed,name=missing_serials,json=missingSerials,proto3" json:"missing_serials,omitempty"` // data chunks no node ever reported
+}
+
+func (x *FsckFile) Reset() {
+	*x = FsckFile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsckFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckFile) ProtoMessage() {}
+
+func (x *FsckFile) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckFile.ProtoReflect.Descriptor instead.
+func (*FsckFile) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *FsckFile) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *FsckFile) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *FsckFile) GetStoragePolicy() string {
+	if x != nil {
+		return x.StoragePolicy
+	}
+	return ""
+}
+
+func (x *FsckFile) GetMissingChunks() []string {
+	if x != nil {
+		return x.MissingChunks
+	}
+	return nil
+}
+
+func (x *FsckFile) GetUnderReplicated() []string {
+	if x != nil {
+		return x.UnderReplicated
+	}
+	return nil
+}
+
+func (x *FsckFile) GetOverReplicated() []string {
+	if x != nil {
+		return x.OverReplicated
+	}
+	return nil
+}
+
+func (x *FsckFile) GetCorruptReplicas() []string {
+	if x != nil {
+		return x.CorruptReplicas
+	}
+	return nil
+}
+
+func (x *FsckFile) GetMissingSerials() []int32 {
+	if x != nil {
+		return x.MissingSerials
+	}
+	return nil
+}
+
+// Controller leader election and edit log replication (Raft-like)
+type VoteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Term      int64  `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	Candidate string `protobuf:"bytes,2,opt,name=candidate,proto3" json:"candidate,omitempty"` // hostname:port
+	LastIndex int64  `protobuf:"varint,3,opt,name=last_index,json=lastIndex,proto3" json:"last_index,omitempty"`
+	LastTerm  int64  `protobuf:"varint,4,opt,name=last_term,json=lastTerm,proto3" json:"last_term,omitempty"`
+}
+
+func (x *VoteRequest) Reset() {
+	*x = VoteRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoteRequest) ProtoMessage() {}
+
+func (x *VoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoteRequest.ProtoReflect.Descriptor instead.
+func (*VoteRequest) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *VoteRequest) GetTerm() int64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *VoteRequest) GetCandidate() string {
+	if x != nil {
+		return x.Candidate
+	}
+	return ""
+}
+
+func (x *VoteRequest) GetLastIndex() int64 {
+	if x != nil {
+		return x.LastIndex
+	}
+	return 0
+}
+
+func (x *VoteRequest) GetLastTerm() int64 {
+	if x != nil {
+		return x.LastTerm
+	}
+	return 0
+}
+
+type VoteResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Term    int64 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	Granted bool  `protobuf:"varint,2,opt,name=granted,proto3" json:"granted,omitempty"`
+}
+
+func (x *VoteResponse) Reset() {
+	*x = VoteResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoteResponse) ProtoMessage() {}
+
+func (x *VoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoteResponse.ProtoReflect.Descriptor instead.
+func (*VoteResponse) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *VoteResponse) GetTerm() int64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *VoteResponse) GetGranted() bool {
+	if x != nil {
+		return x.Granted
+	}
+	return false
+}
+
+type AppendEntries struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Term         int64           `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	Leader       string          `protobuf:"bytes,2,opt,name=leader,proto3" json:"leader,omitempty"` // hostname:port
+	PrevIndex    int64           `protobuf:"varint,3,opt,name=prev_index,json=prevIndex,proto3" json:"prev_index,omitempty"`
+	PrevTerm     int64           `protobuf:"varint,4,opt,name=prev_term,json=prevTerm,proto3" json:"prev_term,omitempty"`
+	Entries      []*EditLogEntry `protobuf:"bytes,5,rep,name=entries,proto3" json:"entries,omitempty"`                                // empty on plain leader heartbeats
+	LeaderCommit int64           `protobuf:"varint,6,opt,name=leader_commit,json=leaderCommit,proto3" json:"leader_commit,omitempty"` // entries up to this index are stored by a majority and can be applied
+}
+
+func (x *AppendEntries) Reset() {
+	*x = AppendEntries{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AppendEntries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppendEntries) ProtoMessage() {}
+
+func (x *AppendEntries) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppendEntries.ProtoReflect.Descriptor instead.
+func (*AppendEntries) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AppendEntries) GetTerm() int64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetLeader() string {
+	if x != nil {
+		return x.Leader
+	}
+	return ""
+}
+
+func (x *AppendEntries) GetPrevIndex() int64 {
+	if x != nil {
+		return x.PrevIndex
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetPrevTerm() int64 {
+	if x != nil {
+		return x.PrevTerm
+	}
+	return 0
+}
+
+func (x *AppendEntries) GetEntries() []*EditLogEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *AppendEntries) GetLeaderCommit() int64 {
+	if x != nil {
+		return x.LeaderCommit
+	}
+	return 0
+}
+
+type AppendEntriesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Term      int64 `protobuf:"varint,1,opt,name=term,proto3" json:"term,omitempty"`
+	Ok        bool  `protobuf:"varint,2,opt,name=ok,proto3" json:"ok,omitempty"`
+	LastIndex int64 `protobuf:"varint,3,opt,name=last_index,json=lastIndex,proto3" json:"last_index,omitempty"`
+}
+
+func (x *AppendEntriesResponse) Reset() {
+	*x = AppendEntriesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AppendEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppendEntriesResponse) ProtoMessage() {}
+
+func (x *AppendEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *Wrapper) GetActionRequestMessage() *ActionRequest {
-	if x, ok := x.GetMsg().(*Wrapper_ActionRequestMessage); ok {
-		return x.ActionRequestMessage
-	}
-	return nil
+// Deprecated: Use AppendEntriesResponse.ProtoReflect.Descriptor instead.
+func (*AppendEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *Wrapper) GetChunkMessage() *Chunk {
-	if x, ok := x.GetMsg().(*Wrapper_ChunkMessage); ok {
-		return x.ChunkMessage
+func (x *AppendEntriesResponse) GetTerm() int64 {
+	if x != nil {
+		return x.Term
 	}
-	return nil
+	return 0
 }
 
-func (x *Wrapper) GetComputationStatusMessage() *ComputationStatus {
-	if x, ok := x.GetMsg().(*Wrapper_ComputationStatusMessage); ok {
-		return x.ComputationStatusMessage
+func (x *AppendEntriesResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
 	}
-	return nil
+	return false
 }
 
-func (x *Wrapper) GetAckMessage() *Ack {
-	if x, ok := x.GetMsg().(*Wrapper_AckMessage); ok {
-		return x.AckMessage
+func (x *AppendEntriesResponse) GetLastIndex() int64 {
+	if x != nil {
+		return x.LastIndex
 	}
-	return nil
+	return 0
 }
 
-type isWrapper_Msg interface {
-	isWrapper_Msg()
-}
+// namespace change that storage node block reports can't rebuild
+type EditLogEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-type Wrapper_RegistrationMessage struct {
-	RegistrationMessage *Registration `protobuf:"bytes,1,opt,name=registration_message,json=registrationMessage,proto3,oneof"`
+	Index             int64          `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Term              int64          `protobuf:"varint,2,opt,name=term,proto3" json:"term,omitempty"`
+	Action            *ActionRequest `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	ComputeEngineAddr string         `protobuf:"bytes,4,opt,name=compute_engine_addr,json=computeEngineAddr,proto3" json:"compute_engine_addr,omitempty"` // compute engine registration
 }
 
-type Wrapper_HeartbeatMessage struct {
-	HeartbeatMessage *Heartbeat `protobuf:"bytes,2,opt,name=heartbeat_message,json=heartbeatMessage,proto3,oneof"`
+func (x *EditLogEntry) Reset() {
+	*x = EditLogEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_dfs_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-type Wrapper_FilesMessage struct {
-	FilesMessage *Files `protobuf:"bytes,3,opt,name=files_message,json=filesMessage,proto3,oneof"`
+func (x *EditLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-type Wrapper_FileMessage struct {
-	FileMessage *File `protobuf:"bytes,4,opt,name=file_message,json=fileMessage,proto3,oneof"`
-}
+func (*EditLogEntry) ProtoMessage() {}
 
-type Wrapper_StorageNodesMessage struct {
-	StorageNodesMessage *StorageNodes `protobuf:"bytes,5,opt,name=storage_nodes_message,json=storageNodesMessage,proto3,oneof"`
+func (x *EditLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_dfs_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-type Wrapper_ActionRequestMessage struct {
-	ActionRequestMessage *ActionRequest `protobuf:"bytes,6,opt,name=action_request_message,json=actionRequestMessage,proto3,oneof"`
+// Deprecated: Use EditLogEntry.ProtoReflect.Descriptor instead.
+func (*EditLogEntry) Descriptor() ([]byte, []int) {
+	return file_dfs_proto_rawDescGZIP(), []int{26}
 }
 
-type Wrapper_ChunkMessage struct {
-	ChunkMessage *Chunk `protobuf:"bytes,7,opt,name=chunk_message,json=chunkMessage,proto3,oneof"`
+func (x *EditLogEntry) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
 }
 
-type Wrapper_ComputationStatusMessage struct {
-	ComputationStatusMessage *ComputationStatus `protobuf:"bytes,8,opt,name=computation_status_message,json=computationStatusMessage,proto3,oneof"`
+func (x *EditLogEntry) GetTerm() int64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
 }
 
-type Wrapper_AckMessage struct {
-	AckMessage *Ack `protobuf:"bytes,9,opt,name=ack_message,json=ackMessage,proto3,oneof"`
+func (x *EditLogEntry) GetAction() *ActionRequest {
+	if x != nil {
+		return x.Action
+	}
+	return nil
 }
 
-func (*Wrapper_RegistrationMessage) isWrapper_Msg() {}
-
-func (*Wrapper_HeartbeatMessage) isWrapper_Msg() {}
-
-func (*Wrapper_FilesMessage) isWrapper_Msg() {}
-
-func (*Wrapper_FileMessage) isWrapper_Msg() {}
-
-func (*Wrapper_StorageNodesMessage) isWrapper_Msg() {}
-
-func (*Wrapper_ActionRequestMessage) isWrapper_Msg() {}
-
-func (*Wrapper_ChunkMessage) isWrapper_Msg() {}
-
-func (*Wrapper_ComputationStatusMessage) isWrapper_Msg() {}
-
-func (*Wrapper_AckMessage) isWrapper_Msg() {}
+func (x *EditLogEntry) GetComputeEngineAddr() string {
+	if x != nil {
+		return x.ComputeEngineAddr
+	}
+	return ""
+}
 
 var File_dfs_proto protoreflect.FileDescriptor
 
 var file_dfs_proto_rawDesc = []byte{
-	0x0a, 0x09, 0x64, 0x66, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x82, 0x03, 0x0a, 0x0d,
+	0x0a, 0x09, 0x64, 0x66, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf2, 0x05, 0x0a, 0x0d,
 	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a,
 	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x41, 0x63,
 	0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1b,
@@ -1213,134 +2738,386 @@ var file_dfs_proto_rawDesc = []byte{
 	0x72, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x75, 0x74, 0x70, 0x75,
 	0x74, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x0e, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x6e, 0x61, 0x6d, 0x65,
-	0x22, 0x34, 0x0a, 0x06, 0x50, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16,
-	0x0a, 0x06, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
-	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x22, 0x29, 0x0a, 0x0c, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64,
-	0x65, 0x22, 0x73, 0x0a, 0x09, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x1e,
-	0x0a, 0x06, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06,
-	0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x52, 0x06, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x12, 0x28,
-	0x0a, 0x0c, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x0b, 0x73, 0x74, 0x6f,
-	0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74,
-	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
-	0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x22, 0x82, 0x01, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73,
-	0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64,
-	0x12, 0x1a, 0x0a, 0x08, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x08, 0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a,
-	0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x0a, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
-	0x66, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x09, 0x66, 0x72, 0x65, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x22, 0x24, 0x0a, 0x05, 0x46,
-	0x69, 0x6c, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65,
-	0x73, 0x22, 0x54, 0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a,
-	0x07, 0x64, 0x69, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x64, 0x69, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b,
-	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x52,
-	0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x22, 0xbf, 0x02, 0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e,
-	0x6b, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1d,
-	0x0a, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a,
-	0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73,
-	0x65, 0x72, 0x69, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74,
-	0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x3d, 0x0a,
-	0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x06,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x2e, 0x53, 0x74, 0x6f,
-	0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c,
-	0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06,
-	0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6f, 0x66,
-	0x66, 0x73, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a,
-	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a,
-	0x65, 0x1a, 0x46, 0x0a, 0x11, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65,
-	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1b, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x68, 0x0a, 0x04, 0x4e, 0x6f, 0x64,
-	0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d,
-	0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x73, 0x74,
-	0x61, 0x74, 0x73, 0x22, 0x2b, 0x0a, 0x0c, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f,
-	0x64, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73,
-	0x22, 0x3a, 0x0a, 0x03, 0x41, 0x63, 0x6b, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72,
-	0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
-	0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xf7, 0x01, 0x0a,
-	0x11, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02,
-	0x6f, 0x6b, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0a, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x43, 0x0a, 0x0b, 0x66,
-	0x69, 0x6c, 0x65, 0x73, 0x5f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x22, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x45,
-	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x54, 0x61, 0x62, 0x6c, 0x65,
-	0x1a, 0x44, 0x0a, 0x0f, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1b, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xa3, 0x04, 0x0a, 0x07, 0x57, 0x72, 0x61, 0x70, 0x70,
-	0x65, 0x72, 0x12, 0x42, 0x0a, 0x14, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x0d, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48,
-	0x00, 0x52, 0x13, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x39, 0x0a, 0x11, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62,
-	0x65, 0x61, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0a, 0x2e, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x48, 0x00, 0x52,
-	0x10, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x12, 0x2d, 0x0a, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x73,
-	0x48, 0x00, 0x52, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x12, 0x2a, 0x0a, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x48, 0x00, 0x52,
-	0x0b, 0x66, 0x69, 0x6c, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x43, 0x0a, 0x15,
-	0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x5f, 0x6d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x53, 0x74,
-	0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x48, 0x00, 0x52, 0x13, 0x73, 0x74,
-	0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x12, 0x46, 0x0a, 0x16, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x0e, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x48, 0x00, 0x52, 0x14, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2d, 0x0a, 0x0d, 0x63, 0x68, 0x75,
-	0x6e, 0x6b, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x06, 0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x48, 0x00, 0x52, 0x0c, 0x63, 0x68, 0x75, 0x6e,
-	0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x52, 0x0a, 0x1a, 0x63, 0x6f, 0x6d, 0x70,
-	0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x6d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x43,
-	0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x48, 0x00, 0x52, 0x18, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x27, 0x0a, 0x0b,
-	0x61, 0x63, 0x6b, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x04, 0x2e, 0x41, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x0a, 0x61, 0x63, 0x6b, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x05, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x2a, 0x61, 0x0a, 0x0a,
-	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x06, 0x0a, 0x02, 0x4c, 0x53,
-	0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x47, 0x45, 0x54, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x50,
-	0x55, 0x54, 0x10, 0x02, 0x12, 0x06, 0x0a, 0x02, 0x52, 0x4d, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07,
-	0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4c, 0x55,
-	0x53, 0x54, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x53, 0x10, 0x05, 0x12, 0x11, 0x0a, 0x0d,
-	0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x53, 0x54, 0x4f, 0x52, 0x45, 0x10, 0x06, 0x2a,
-	0x22, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x07,
-	0x0a, 0x03, 0x4d, 0x41, 0x50, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x44, 0x55, 0x43,
-	0x45, 0x10, 0x01, 0x2a, 0x4e, 0x0a, 0x09, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x12, 0x10, 0x0a, 0x0c, 0x6a, 0x6f, 0x62, 0x5f, 0x61, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64,
-	0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x6a, 0x6f, 0x62, 0x5f, 0x6d, 0x61, 0x70, 0x70, 0x65, 0x72,
-	0x73, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x6a, 0x6f, 0x62, 0x5f, 0x72, 0x65, 0x64, 0x75, 0x63,
-	0x65, 0x72, 0x73, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x6a, 0x6f, 0x62, 0x5f, 0x64, 0x6f, 0x6e,
-	0x65, 0x10, 0x04, 0x42, 0x0c, 0x5a, 0x0a, 0x2e, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x12, 0x20, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x07, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x73, 0x18, 0x0d, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x07, 0x74, 0x61, 0x72, 0x67,
+	0x65, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x70,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73, 0x74, 0x6f,
+	0x72, 0x61, 0x67, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x61,
+	0x66, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73,
+	0x61, 0x66, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x70, 0x61, 0x69,
+	0x72, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x12,
+	0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x71, 0x75, 0x6f, 0x74, 0x61, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x12, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x14, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x15, 0x20,
+	0x03, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62,
+	0x5f, 0x69, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64,
+	0x22, 0xc4, 0x01, 0x0a, 0x06, 0x50, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x06, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x12, 0x28, 0x0a, 0x09, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x09, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e,
+	0x67, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72,
+	0x65, 0x64, 0x75, 0x63, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x72,
+	0x65, 0x64, 0x75, 0x63, 0x65, 0x72, 0x73, 0x12, 0x2e, 0x0a, 0x0b, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x50,
+	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x52, 0x0b, 0x70, 0x61, 0x72, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x22, 0x78, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x23, 0x0a, 0x0d,
+	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74,
+	0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x61, 0x72, 0x69, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x61, 0x72, 0x69, 0x65,
+	0x73, 0x22, 0x59, 0x0a, 0x09, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x69, 0x6e, 0x67, 0x12, 0x16,
+	0x0a, 0x06, 0x6d, 0x61, 0x70, 0x70, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x6d, 0x61, 0x70, 0x70, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x64, 0x75, 0x63, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x64, 0x75, 0x63, 0x65, 0x72,
+	0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x65, 0x72, 0x22, 0x74, 0x0a, 0x08,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x63, 0x6f, 0x6d, 0x62,
+	0x69, 0x6e, 0x65, 0x5f, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x5f, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x63, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x65,
+	0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x34, 0x0a, 0x16,
+	0x63, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x65, 0x5f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x14, 0x63, 0x6f,
+	0x6d, 0x62, 0x69, 0x6e, 0x65, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x73, 0x22, 0x49, 0x0a, 0x0c, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x19, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a,
+	0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x52, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x22, 0x73, 0x0a,
+	0x09, 0x48, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x12, 0x1e, 0x0a, 0x06, 0x43, 0x68,
+	0x75, 0x6e, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x52, 0x06, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x12, 0x28, 0x0a, 0x0c, 0x73, 0x74,
+	0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x0b, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x4e, 0x6f, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x73, 0x74, 0x61,
+	0x74, 0x73, 0x22, 0x8e, 0x02, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1e, 0x0a, 0x0a,
+	0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0a, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x75, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x70, 0x6c,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x72, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x65, 0x65,
+	0x5f, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x66, 0x72,
+	0x65, 0x65, 0x53, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63,
+	0x69, 0x74, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63,
+	0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x04, 0x75, 0x73, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c,
+	0x65, 0x12, 0x20, 0x0a, 0x05, 0x64, 0x69, 0x73, 0x6b, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0a, 0x2e, 0x44, 0x69, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x05, 0x64, 0x69,
+	0x73, 0x6b, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x09, 0x44, 0x69, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x64, 0x69, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x12,
+	0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x75,
+	0x73, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x44, 0x0a,
+	0x05, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x66, 0x69,
+	0x6c, 0x65, 0x73, 0x12, 0x1e, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x06, 0x71, 0x75, 0x6f,
+	0x74, 0x61, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x05, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x18, 0x0a,
+	0x07, 0x64, 0x69, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x64, 0x69, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x46,
+	0x69, 0x6c, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x62, 0x79, 0x74, 0x65, 0x73, 0x22, 0x9d, 0x01,
+	0x0a, 0x04, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69,
+	0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x69, 0x72,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x06, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x52, 0x06, 0x63, 0x68,
+	0x75, 0x6e, 0x6b, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x22, 0xef, 0x05,
+	0x0a, 0x05, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x3d, 0x0a, 0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x6e,
+	0x6f, 0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x43, 0x68, 0x75,
+	0x6e, 0x6b, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64,
+	0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69,
+	0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x66,
+	0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x65,
+	0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72,
+	0x69, 0x70, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x72, 0x69, 0x70,
+	0x65, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x72, 0x69, 0x70, 0x65, 0x5f, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x70, 0x65, 0x49,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x72, 0x69, 0x70, 0x65, 0x5f, 0x77,
+	0x69, 0x64, 0x74, 0x68, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69,
+	0x70, 0x65, 0x57, 0x69, 0x64, 0x74, 0x68, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x5f,
+	0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x61,
+	0x74, 0x61, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x61, 0x72, 0x69,
+	0x74, 0x79, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0c, 0x70, 0x61, 0x72, 0x69, 0x74, 0x79, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x68, 0x61, 0x72, 0x64, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x73, 0x68, 0x61, 0x72, 0x64, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x21, 0x0a, 0x0c,
+	0x73, 0x74, 0x72, 0x69, 0x70, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x73, 0x18, 0x10, 0x20, 0x03,
+	0x28, 0x03, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x70, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x63, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x61, 0x77, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x61, 0x77, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x1c, 0x0a, 0x09, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x18, 0x13, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x09, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x12, 0x1a,
+	0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f,
+	0x72, 0x72, 0x75, 0x70, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x6f, 0x72,
+	0x72, 0x75, 0x70, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x68,
+	0x75, 0x6e, 0x6b, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x1a, 0x46, 0x0a, 0x11, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1b,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e,
+	0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0xa6, 0x01, 0x0a, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1c, 0x0a, 0x05,
+	0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x12, 0x26, 0x0a, 0x0f, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x5f, 0x74, 0x6f, 0x5f, 0x64, 0x72,
+	0x61, 0x69, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x73, 0x54, 0x6f, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x22, 0x7a, 0x0a, 0x0c, 0x53, 0x74, 0x6f, 0x72,
+	0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05,
+	0x6e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x26, 0x0a, 0x09,
+	0x73, 0x61, 0x66, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x09, 0x2e, 0x53, 0x61, 0x66, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x08, 0x73, 0x61, 0x66, 0x65,
+	0x4d, 0x6f, 0x64, 0x65, 0x22, 0xa2, 0x01, 0x0a, 0x08, 0x53, 0x61, 0x66, 0x65, 0x4d, 0x6f, 0x64,
+	0x65, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x6d, 0x61, 0x6e, 0x75, 0x61, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0e, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x43, 0x68, 0x75, 0x6e,
+	0x6b, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x63,
+	0x68, 0x75, 0x6e, 0x6b, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x65, 0x78, 0x70,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74,
+	0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09,
+	0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x22, 0x3a, 0x0a, 0x03, 0x41, 0x63, 0x6b,
+	0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b,
+	0x12, 0x23, 0x0a, 0x0d, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xb5, 0x02, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x6f,
+	0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b, 0x12, 0x23, 0x0a, 0x0d, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x22, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x0a, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x43, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f, 0x74, 0x61,
+	0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2e, 0x46, 0x69,
+	0x6c, 0x65, 0x73, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0a, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x25, 0x0a, 0x08, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x65, 0x72, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x65, 0x72, 0x73, 0x52, 0x08, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x73,
+	0x12, 0x15, 0x0a, 0x06, 0x6a, 0x6f, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6a, 0x6f, 0x62, 0x49, 0x64, 0x1a, 0x44, 0x0a, 0x0f, 0x46, 0x69, 0x6c, 0x65, 0x73,
+	0x54, 0x61, 0x62, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1b, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e, 0x4e, 0x6f,
+	0x64, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc7, 0x07,
+	0x0a, 0x07, 0x57, 0x72, 0x61, 0x70, 0x70, 0x65, 0x72, 0x12, 0x42, 0x0a, 0x14, 0x72, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x13, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x39, 0x0a,
+	0x11, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0a, 0x2e, 0x48, 0x65, 0x61, 0x72, 0x74,
+	0x62, 0x65, 0x61, 0x74, 0x48, 0x00, 0x52, 0x10, 0x68, 0x65, 0x61, 0x72, 0x74, 0x62, 0x65, 0x61,
+	0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2d, 0x0a, 0x0d, 0x66, 0x69, 0x6c, 0x65,
+	0x73, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x06, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x48, 0x00, 0x52, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2a, 0x0a, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x5f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x05, 0x2e,
+	0x46, 0x69, 0x6c, 0x65, 0x48, 0x00, 0x52, 0x0b, 0x66, 0x69, 0x6c, 0x65, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x43, 0x0a, 0x15, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x6e,
+	0x6f, 0x64, 0x65, 0x73, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x48, 0x00, 0x52, 0x13, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4e, 0x6f, 0x64, 0x65,
+	0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x46, 0x0a, 0x16, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x14, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x2d, 0x0a, 0x0d, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x48,
+	0x00, 0x52, 0x0c, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x52, 0x0a, 0x1a, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48, 0x00, 0x52, 0x18, 0x63, 0x6f, 0x6d, 0x70, 0x75,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x27, 0x0a, 0x0b, 0x61, 0x63, 0x6b, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x04, 0x2e, 0x41, 0x63, 0x6b, 0x48, 0x00,
+	0x52, 0x0a, 0x61, 0x63, 0x6b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x40, 0x0a, 0x14,
+	0x76, 0x6f, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x56, 0x6f, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x48, 0x00, 0x52, 0x12, 0x76, 0x6f, 0x74, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x43,
+	0x0a, 0x15, 0x76, 0x6f, 0x74, 0x65, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x56, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x13,
+	0x76, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x46, 0x0a, 0x16, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x5f, 0x65, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72,
+	0x69, 0x65, 0x73, 0x48, 0x00, 0x52, 0x14, 0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x5f, 0x0a, 0x1f, 0x61,
+	0x70, 0x70, 0x65, 0x6e, 0x64, 0x5f, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x5f, 0x72, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74,
+	0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x1c,
+	0x61, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3d, 0x0a, 0x13,
+	0x66, 0x73, 0x63, 0x6b, 0x5f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x5f, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x46, 0x73, 0x63, 0x6b,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x48, 0x00, 0x52, 0x11, 0x66, 0x73, 0x63, 0x6b, 0x52, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x33, 0x0a, 0x0f, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x53, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x48, 0x00,
+	0x52, 0x0e, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x42, 0x05, 0x0a, 0x03, 0x6d, 0x73, 0x67, 0x22, 0x1d, 0x0a, 0x07, 0x53, 0x61, 0x6d, 0x70, 0x6c,
+	0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x22, 0x93, 0x01, 0x0a, 0x0a, 0x46, 0x73, 0x63, 0x6b, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x1f, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x46, 0x73, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x65, 0x52,
+	0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x5f,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x63,
+	0x68, 0x75, 0x6e, 0x6b, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0d, 0x63, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x70, 0x61, 0x69, 0x72, 0x73, 0x22, 0xbf, 0x02, 0x0a,
+	0x08, 0x46, 0x73, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c,
+	0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69,
+	0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x74, 0x6f, 0x72,
+	0x61, 0x67, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12,
+	0x25, 0x0a, 0x0e, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x68, 0x75, 0x6e, 0x6b,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x5f,
+	0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0f, 0x75, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65,
+	0x64, 0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x76, 0x65, 0x72, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63,
+	0x61, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x6f, 0x76, 0x65, 0x72,
+	0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x6f,
+	0x72, 0x72, 0x75, 0x70, 0x74, 0x5f, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18, 0x07,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x6f, 0x72, 0x72, 0x75, 0x70, 0x74, 0x52, 0x65, 0x70,
+	0x6c, 0x69, 0x63, 0x61, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67,
+	0x5f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x05, 0x52, 0x0e,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x73, 0x22, 0x7b,
+	0x0a, 0x0b, 0x56, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x65, 0x72,
+	0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12,
+	0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b,
+	0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x22, 0x3c, 0x0a, 0x0c, 0x56,
+	0x6f, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x65, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12,
+	0x18, 0x0a, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x67, 0x72, 0x61, 0x6e, 0x74, 0x65, 0x64, 0x22, 0xc5, 0x01, 0x0a, 0x0d, 0x41, 0x70,
+	0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x65, 0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12,
+	0x16, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x72, 0x65, 0x76, 0x5f,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x70, 0x72, 0x65,
+	0x76, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x72, 0x65, 0x76, 0x5f, 0x74,
+	0x65, 0x72, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70, 0x72, 0x65, 0x76, 0x54,
+	0x65, 0x72, 0x6d, 0x12, 0x27, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x45, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d,
+	0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0c, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x43, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x22, 0x5a, 0x0a, 0x15, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x69,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65,
+	0x72, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12, 0x0e,
+	0x0a, 0x02, 0x6f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x02, 0x6f, 0x6b, 0x12, 0x1d,
+	0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x90, 0x01,
+	0x0a, 0x0c, 0x45, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12, 0x26, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x2e, 0x0a, 0x13, 0x63, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x5f, 0x65, 0x6e, 0x67, 0x69,
+	0x6e, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x63,
+	0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x45, 0x6e, 0x67, 0x69, 0x6e, 0x65, 0x41, 0x64, 0x64, 0x72,
+	0x2a, 0xef, 0x01, 0x0a, 0x0a, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x06, 0x0a, 0x02, 0x4c, 0x53, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x47, 0x45, 0x54, 0x10, 0x01,
+	0x12, 0x07, 0x0a, 0x03, 0x50, 0x55, 0x54, 0x10, 0x02, 0x12, 0x06, 0x0a, 0x02, 0x52, 0x4d, 0x10,
+	0x03, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x10, 0x04, 0x12, 0x11,
+	0x0a, 0x0d, 0x43, 0x4c, 0x55, 0x53, 0x54, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x53, 0x10,
+	0x05, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x53, 0x54, 0x4f,
+	0x52, 0x45, 0x10, 0x06, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x45, 0x54, 0x52, 0x45, 0x50, 0x10, 0x07,
+	0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x50, 0x4c, 0x49, 0x43, 0x41, 0x54, 0x45, 0x10, 0x08, 0x12,
+	0x0d, 0x0a, 0x09, 0x53, 0x45, 0x54, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x10, 0x09, 0x12, 0x10,
+	0x0a, 0x0c, 0x44, 0x45, 0x43, 0x4f, 0x4d, 0x4d, 0x49, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x0a,
+	0x12, 0x11, 0x0a, 0x0d, 0x57, 0x48, 0x4f, 0x5f, 0x49, 0x53, 0x5f, 0x4c, 0x45, 0x41, 0x44, 0x45,
+	0x52, 0x10, 0x0b, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x41, 0x46, 0x45, 0x4d, 0x4f, 0x44, 0x45, 0x10,
+	0x0c, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x53, 0x43, 0x4b, 0x10, 0x0d, 0x12, 0x0c, 0x0a, 0x08, 0x53,
+	0x45, 0x54, 0x51, 0x55, 0x4f, 0x54, 0x41, 0x10, 0x0e, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x41, 0x4d,
+	0x50, 0x4c, 0x45, 0x10, 0x0f, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x49, 0x53, 0x43, 0x41, 0x52, 0x44,
+	0x10, 0x10, 0x2a, 0x22, 0x0a, 0x0b, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x07, 0x0a, 0x03, 0x4d, 0x41, 0x50, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45,
+	0x44, 0x55, 0x43, 0x45, 0x10, 0x01, 0x2a, 0x6f, 0x0a, 0x0f, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x65, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x0e, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x10, 0x00, 0x12, 0x18, 0x0a,
+	0x14, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x10, 0x02, 0x12, 0x19, 0x0a, 0x15,
+	0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f,
+	0x6f, 0x72, 0x64, 0x65, 0x72, 0x10, 0x03, 0x2a, 0x71, 0x0a, 0x09, 0x4a, 0x6f, 0x62, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x10, 0x0a, 0x0c, 0x6a, 0x6f, 0x62, 0x5f, 0x61, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x65, 0x64, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x6a, 0x6f, 0x62, 0x5f, 0x6d, 0x61,
+	0x70, 0x70, 0x65, 0x72, 0x73, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x6a, 0x6f, 0x62, 0x5f, 0x72,
+	0x65, 0x64, 0x75, 0x63, 0x65, 0x72, 0x73, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x6a, 0x6f, 0x62,
+	0x5f, 0x64, 0x6f, 0x6e, 0x65, 0x10, 0x04, 0x12, 0x0e, 0x0a, 0x0a, 0x6a, 0x6f, 0x62, 0x5f, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x64, 0x10, 0x05, 0x12, 0x11, 0x0a, 0x0d, 0x6a, 0x6f, 0x62, 0x5f, 0x75,
+	0x70, 0x6c, 0x6f, 0x61, 0x64, 0x69, 0x6e, 0x67, 0x10, 0x06, 0x42, 0x0c, 0x5a, 0x0a, 0x2e, 0x2f,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1355,61 +3132,94 @@ func file_dfs_proto_rawDescGZIP() []byte {
 	return file_dfs_proto_rawDescData
 }
 
-var file_dfs_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_dfs_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_dfs_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_dfs_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
 var file_dfs_proto_goTypes = []interface{}{
-	(ActionType)(0),           // 0: ActionType
-	(ComputeType)(0),          // 1: ComputeType
-	(JobStatus)(0),            // 2: JobStatus
-	(*ActionRequest)(nil),     // 3: ActionRequest
-	(*Plugin)(nil),            // 4: Plugin
-	(*Registration)(nil),      // 5: Registration
-	(*Heartbeat)(nil),         // 6: Heartbeat
-	(*Stats)(nil),             // 7: Stats
-	(*Files)(nil),             // 8: Files
-	(*File)(nil),              // 9: File
-	(*Chunk)(nil),             // 10: Chunk
-	(*Node)(nil),              // 11: Node
-	(*StorageNodes)(nil),      // 12: StorageNodes
-	(*Ack)(nil),               // 13: Ack
-	(*ComputationStatus)(nil), // 14: ComputationStatus
-	(*Wrapper)(nil),           // 15: Wrapper
-	nil,                       // 16: Chunk.StorageNodesEntry
-	nil,                       // 17: ComputationStatus.FilesTableEntry
+	(ActionType)(0),               // 0: ActionType
+	(ComputeType)(0),              // 1: ComputeType
+	(PartitionerType)(0),          // 2: PartitionerType
+	(JobStatus)(0),                // 3: JobStatus
+	(*ActionRequest)(nil),         // 4: ActionRequest
+	(*Plugin)(nil),                // 5: Plugin
+	(*Partitioner)(nil),           // 6: Partitioner
+	(*Streaming)(nil),             // 7: Streaming
+	(*Counters)(nil),              // 8: Counters
+	(*Registration)(nil),          // 9: Registration
+	(*Heartbeat)(nil),             // 10: Heartbeat
+	(*Stats)(nil),                 // 11: Stats
+	(*DiskStats)(nil),             // 12: DiskStats
+	(*Files)(nil),                 // 13: Files
+	(*Quota)(nil),                 // 14: Quota
+	(*File)(nil),                  // 15: File
+	(*Chunk)(nil),                 // 16: Chunk
+	(*Node)(nil),                  // 17: Node
+	(*StorageNodes)(nil),          // 18: StorageNodes
+	(*SafeMode)(nil),              // 19: SafeMode
+	(*Ack)(nil),                   // 20: Ack
+	(*ComputationStatus)(nil),     // 21: ComputationStatus
+	(*Wrapper)(nil),               // 22: Wrapper
+	(*Samples)(nil),               // 23: Samples
+	(*FsckReport)(nil),            // 24: FsckReport
+	(*FsckFile)(nil),              // 25: FsckFile
+	(*VoteRequest)(nil),           // 26: VoteRequest
+	(*VoteResponse)(nil),          // 27: VoteResponse
+	(*AppendEntries)(nil),         // 28: AppendEntries
+	(*AppendEntriesResponse)(nil), // 29: AppendEntriesResponse
+	(*EditLogEntry)(nil),          // 30: EditLogEntry
+	nil,                           // 31: Chunk.StorageNodesEntry
+	nil,                           // 32: ComputationStatus.FilesTableEntry
 }
 var file_dfs_proto_depIdxs = []int32{
 	0,  // 0: ActionRequest.type:type_name -> ActionType
-	10, // 1: ActionRequest.chunk:type_name -> Chunk
-	4,  // 2: ActionRequest.plugin:type_name -> Plugin
+	16, // 1: ActionRequest.chunk:type_name -> Chunk
+	5,  // 2: ActionRequest.plugin:type_name -> Plugin
 	1,  // 3: ActionRequest.compute_type:type_name -> ComputeType
-	11, // 4: ActionRequest.reducers:type_name -> Node
-	11, // 5: Registration.node:type_name -> Node
-	10, // 6: Heartbeat.Chunks:type_name -> Chunk
-	11, // 7: Heartbeat.storage_node:type_name -> Node
-	7,  // 8: Heartbeat.stats:type_name -> Stats
-	9,  // 9: Files.files:type_name -> File
-	10, // 10: File.chunks:type_name -> Chunk
-	16, // 11: Chunk.storage_nodes:type_name -> Chunk.StorageNodesEntry
-	7,  // 12: Node.stats:type_name -> Stats
-	11, // 13: StorageNodes.nodes:type_name -> Node
-	2,  // 14: ComputationStatus.status:type_name -> JobStatus
-	17, // 15: ComputationStatus.files_table:type_name -> ComputationStatus.FilesTableEntry
-	5,  // 16: Wrapper.registration_message:type_name -> Registration
-	6,  // 17: Wrapper.heartbeat_message:type_name -> Heartbeat
-	8,  // 18: Wrapper.files_message:type_name -> Files
-	9,  // 19: Wrapper.file_message:type_name -> File
-	12, // 20: Wrapper.storage_nodes_message:type_name -> StorageNodes
-	3,  // 21: Wrapper.action_request_message:type_name -> ActionRequest
-	10, // 22: Wrapper.chunk_message:type_name -> Chunk
-	14, // 23: Wrapper.computation_status_message:type_name -> ComputationStatus
-	13, // 24: Wrapper.ack_message:type_name -> Ack
-	11, // 25: Chunk.StorageNodesEntry.value:type_name -> Node
-	11, // 26: ComputationStatus.FilesTableEntry.value:type_name -> Node
-	27, // [27:27] is the sub-list for method output_type
-	27, // [27:27] is the sub-list for method input_type
-	27, // [27:27] is the sub-list for extension type_name
-	27, // [27:27] is the sub-list for extension extendee
-	0,  // [0:27] is the sub-list for field type_name
+	17, // 4: ActionRequest.reducers:type_name -> Node
+	17, // 5: ActionRequest.targets:type_name -> Node
+	7,  // 6: Plugin.streaming:type_name -> Streaming
+	6,  // 7: Plugin.partitioner:type_name -> Partitioner
+	2,  // 8: Partitioner.type:type_name -> PartitionerType
+	17, // 9: Registration.node:type_name -> Node
+	16, // 10: Registration.chunks:type_name -> Chunk
+	16, // 11: Heartbeat.Chunks:type_name -> Chunk
+	17, // 12: Heartbeat.storage_node:type_name -> Node
+	11, // 13: Heartbeat.stats:type_name -> Stats
+	12, // 14: Stats.disks:type_name -> DiskStats
+	15, // 15: Files.files:type_name -> File
+	14, // 16: Files.quotas:type_name -> Quota
+	16, // 17: File.chunks:type_name -> Chunk
+	31, // 18: Chunk.storage_nodes:type_name -> Chunk.StorageNodesEntry
+	11, // 19: Node.stats:type_name -> Stats
+	17, // 20: StorageNodes.nodes:type_name -> Node
+	19, // 21: StorageNodes.safe_mode:type_name -> SafeMode
+	3,  // 22: ComputationStatus.status:type_name -> JobStatus
+	32, // 23: ComputationStatus.files_table:type_name -> ComputationStatus.FilesTableEntry
+	8,  // 24: ComputationStatus.counters:type_name -> Counters
+	9,  // 25: Wrapper.registration_message:type_name -> Registration
+	10, // 26: Wrapper.heartbeat_message:type_name -> Heartbeat
+	13, // 27: Wrapper.files_message:type_name -> Files
+	15, // 28: Wrapper.file_message:type_name -> File
+	18, // 29: Wrapper.storage_nodes_message:type_name -> StorageNodes
+	4,  // 30: Wrapper.action_request_message:type_name -> ActionRequest
+	16, // 31: Wrapper.chunk_message:type_name -> Chunk
+	21, // 32: Wrapper.computation_status_message:type_name -> ComputationStatus
+	20, // 33: Wrapper.ack_message:type_name -> Ack
+	26, // 34: Wrapper.vote_request_message:type_name -> VoteRequest
+	27, // 35: Wrapper.vote_response_message:type_name -> VoteResponse
+	28, // 36: Wrapper.append_entries_message:type_name -> AppendEntries
+	29, // 37: Wrapper.append_entries_response_message:type_name -> AppendEntriesResponse
+	24, // 38: Wrapper.fsck_report_message:type_name -> FsckReport
+	23, // 39: Wrapper.samples_message:type_name -> Samples
+	25, // 40: FsckReport.files:type_name -> FsckFile
+	30, // 41: AppendEntries.entries:type_name -> EditLogEntry
+	4,  // 42: EditLogEntry.action:type_name -> ActionRequest
+	17, // 43: Chunk.StorageNodesEntry.value:type_name -> Node
+	17, // 44: ComputationStatus.FilesTableEntry.value:type_name -> Node
+	45, // [45:45] is the sub-list for method output_type
+	45, // [45:45] is the sub-list for method input_type
+	45, // [45:45] is the sub-list for extension type_name
+	45, // [45:45] is the sub-list for extension extendee
+	0,  // [0:45] is the sub-list for field type_name
 }
 
 func init() { file_dfs_proto_init() }
@@ -1443,7 +3253,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Registration); i {
+			switch v := v.(*Partitioner); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1455,7 +3265,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Heartbeat); i {
+			switch v := v.(*Streaming); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1467,7 +3277,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Stats); i {
+			switch v := v.(*Counters); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1479,7 +3289,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Files); i {
+			switch v := v.(*Registration); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1491,7 +3301,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*File); i {
+			switch v := v.(*Heartbeat); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1503,7 +3313,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Chunk); i {
+			switch v := v.(*Stats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1515,7 +3325,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Node); i {
+			switch v := v.(*DiskStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1527,7 +3337,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StorageNodes); i {
+			switch v := v.(*Files); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1539,7 +3349,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Ack); i {
+			switch v := v.(*Quota); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1551,7 +3361,7 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ComputationStatus); i {
+			switch v := v.(*File); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1563,6 +3373,78 @@ func file_dfs_proto_init() {
 			}
 		}
 		file_dfs_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Chunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Node); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageNodes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SafeMode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ack); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ComputationStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Wrapper); i {
 			case 0:
 				return &v.state
@@ -1574,8 +3456,104 @@ func file_dfs_proto_init() {
 				return nil
 			}
 		}
+		file_dfs_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Samples); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsckReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsckFile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VoteRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VoteResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AppendEntries); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AppendEntriesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_dfs_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EditLogEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	file_dfs_proto_msgTypes[12].OneofWrappers = []interface{}{
+	file_dfs_proto_msgTypes[18].OneofWrappers = []interface{}{
 		(*Wrapper_RegistrationMessage)(nil),
 		(*Wrapper_HeartbeatMessage)(nil),
 		(*Wrapper_FilesMessage)(nil),
@@ -1585,14 +3563,20 @@ func file_dfs_proto_init() {
 		(*Wrapper_ChunkMessage)(nil),
 		(*Wrapper_ComputationStatusMessage)(nil),
 		(*Wrapper_AckMessage)(nil),
+		(*Wrapper_VoteRequestMessage)(nil),
+		(*Wrapper_VoteResponseMessage)(nil),
+		(*Wrapper_AppendEntriesMessage)(nil),
+		(*Wrapper_AppendEntriesResponseMessage)(nil),
+		(*Wrapper_FsckReportMessage)(nil),
+		(*Wrapper_SamplesMessage)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_dfs_proto_rawDesc,
-			NumEnums:      3,
-			NumMessages:   15,
+			NumEnums:      4,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
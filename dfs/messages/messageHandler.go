@@ -2,7 +2,10 @@ package messages
 
 import (
 	"encoding/binary"
+	"errors"
 	"net"
+	"strconv"
+	"sync"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -10,6 +13,7 @@ import (
 type MessageHandler struct {
 	conn     net.Conn
 	IsClosed bool
+	sendMu   sync.Mutex // goroutines of a job share the status connection
 }
 
 const TCP = "tcp"
@@ -27,6 +31,41 @@ func GetMessageHandlerFor(addr string) (*MessageHandler, error) {
 	return NewMessageHandler(conn), nil
 }
 
+/**
+* Address of the leading controller among controllerAddrs. With a single
+* controller there is nobody to ask.
+ */
+func FindLeader(controllerAddrs []string) (string, error) {
+	if len(controllerAddrs) == 1 {
+		return controllerAddrs[0], nil
+	}
+	for _, addr := range controllerAddrs {
+		msgHandler, err := GetMessageHandlerFor(addr)
+		if err != nil {
+			continue
+		}
+		msgHandler.SendWhoIsLeaderRequest()
+		wrapper, err := msgHandler.Receive()
+		msgHandler.Close()
+		if err != nil {
+			continue
+		}
+		if ack, ok := wrapper.Msg.(*Wrapper_AckMessage); ok && ack.AckMessage.Ok {
+			return addr, nil
+		}
+	}
+	return "", errors.New("no controller is leading the cluster")
+}
+
+/** Connects to the leading controller */
+func GetLeaderMessageHandler(controllerAddrs []string) (*MessageHandler, error) {
+	addr, err := FindLeader(controllerAddrs)
+	if err != nil {
+		return nil, err
+	}
+	return GetMessageHandlerFor(addr)
+}
+
 func (m *MessageHandler) Close() error {
 	if err := m.conn.Close(); err != nil {
 		return err
@@ -70,6 +109,8 @@ func (m *MessageHandler) Send(wrapper *Wrapper) error {
 	if err != nil {
 		return err
 	}
+	m.sendMu.Lock()
+	defer m.sendMu.Unlock()
 	prefix := make([]byte, 8)
 	binary.LittleEndian.PutUint64(prefix, uint64(len(serialized)))
 	if err := m.writeN(prefix); err != nil {
@@ -98,13 +139,13 @@ func (m *MessageHandler) Redirect(wrapper *Wrapper) error {
 	return m.Send(wrapper)
 }
 
-func (m *MessageHandler) SendRegistrationMessage(uuid, hostname string, port int) error {
+func (m *MessageHandler) SendRegistrationMessage(uuid, hostname string, port int, chunks []*Chunk) error {
 	node := &Node{
 		Uuid:     uuid,
 		Hostname: hostname,
 		Port:     int32(port),
 	}
-	registrationMessage := &Registration{Node: node}
+	registrationMessage := &Registration{Node: node, Chunks: chunks}
 	wrapper := &Wrapper{
 		Msg: &Wrapper_RegistrationMessage{
 			RegistrationMessage: registrationMessage,
@@ -139,8 +180,131 @@ func (m *MessageHandler) SendLSRequest() error {
 	return m.sendActionRequest(ActionType_LS, "", "", nil)
 }
 
-func (m *MessageHandler) SendPUTRequest(filename string) error {
-	return m.sendActionRequest(ActionType_PUT, filename, "", nil)
+/** fileSize lets the controller check directory quotas before handing out nodes */
+func (m *MessageHandler) SendPUTRequest(filename string, replication int32, storagePolicy string, fileSize int64) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:          ActionType_PUT,
+				FileName:      filename,
+				Replication:   replication,
+				StoragePolicy: storagePolicy,
+				FileSize:      fileSize,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** 0 removes the corresponding limit */
+func (m *MessageHandler) SendSetQuotaRequest(dirname string, maxFiles, maxBytes int64) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:       ActionType_SETQUOTA,
+				FileName:   dirname,
+				QuotaFiles: maxFiles,
+				QuotaBytes: maxBytes,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+func (m *MessageHandler) SendSetPolicyRequest(dirname string, storagePolicy string) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:          ActionType_SETPOLICY,
+				FileName:      dirname,
+				StoragePolicy: storagePolicy,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** node is either the uuid or the hostname:port of the storage node */
+func (m *MessageHandler) SendDecommissionRequest(node string) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:     ActionType_DECOMMISSION,
+				FileName: node,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** action is "enter" or "leave" */
+func (m *MessageHandler) SendSafeModeRequest(action string) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:     ActionType_SAFEMODE,
+				SafeMode: action,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** Health report of the files under path; repair also fixes what can be fixed */
+func (m *MessageHandler) SendFsckRequest(path string, repair bool) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:     ActionType_FSCK,
+				FileName: path,
+				Repair:   repair,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+func (m *MessageHandler) SendSampleRequest(chunkName string, sampleSize int32) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:       ActionType_SAMPLE,
+				ChunkName:  chunkName,
+				SampleSize: sampleSize,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/**
+* attemptName is the output name of the map attempt, see compute_engine.AttemptOutputName.
+* Without one every intermediate file of the job goes.
+ */
+func (m *MessageHandler) SendDiscardRequest(jobId string, attemptName string) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:     ActionType_DISCARD,
+				JobId:    jobId,
+				FileName: attemptName,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+func (m *MessageHandler) SendSetRepRequest(filename string, replication int32) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:        ActionType_SETREP,
+				FileName:    filename,
+				Replication: replication,
+			},
+		},
+	}
+	return m.Send(wrapper)
 }
 
 func (m *MessageHandler) SendGETRequest(filename string) error {
@@ -163,30 +327,69 @@ func (m *MessageHandler) SendChunkUploadRequest(chunk *Chunk) error {
 	return m.sendActionRequest(ActionType_PUT, "", "", chunk)
 }
 
+/** Asks a Storage Node holding chunkName to copy it over to targets */
+func (m *MessageHandler) SendReplicateRequest(chunkName string, replication int32, targets []*Node) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:        ActionType_REPLICATE,
+				ChunkName:   chunkName,
+				Replication: replication,
+				Targets:     targets,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
 func (m *MessageHandler) SendClusterStatsRequest() error {
 	return m.sendActionRequest(ActionType_CLUSTER_STATS, "", "", nil)
 }
 
 func (m *MessageHandler) SendComputeRequest(
 	targetFilename string,
-	pluginName string,
+	plugin *Plugin,
 	outputFilename string,
-	plugin []byte,
 	computeType ComputeType,
 	reducers []*Node,
 ) error {
 	wrapper := &Wrapper{
 		Msg: &Wrapper_ActionRequestMessage{
 			ActionRequestMessage: &ActionRequest{
-				Type:     ActionType_COMPUTE,
-				FileName: targetFilename,
-				Plugin: &Plugin{
-					Name:   pluginName,
-					Plugin: plugin,
-				},
-				ComputeType: computeType,
-				Reducers:    reducers,
+				Type:           ActionType_COMPUTE,
+				FileName:       targetFilename,
+				Plugin:         plugin,
+				ComputeType:    computeType,
+				Reducers:       reducers,
+				OutputFilename: outputFilename,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** Map task of one chunk; partitions limits the shuffle to those partitions when re-running lost output */
+func (m *MessageHandler) SendMapRequest(
+	jobId string,
+	chunkName string,
+	plugin *Plugin,
+	outputFilename string,
+	reducers []*Node,
+	partitions []int32,
+	attempt int32,
+) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type:           ActionType_COMPUTE,
+				JobId:          jobId,
+				FileName:       chunkName,
+				Plugin:         plugin,
+				ComputeType:    ComputeType_MAP,
+				Reducers:       reducers,
 				OutputFilename: outputFilename,
+				Partitions:     partitions,
+				Attempt:        attempt,
 			},
 		},
 	}
@@ -194,23 +397,21 @@ func (m *MessageHandler) SendComputeRequest(
 }
 
 func (m *MessageHandler) SendReduceRequest(
+	jobId string,
 	targetFilenames []string,
-	pluginName string,
-	plugin []byte,
+	plugin *Plugin,
 	reducerNumber int32,
 	outputFilename string,
 ) error {
 	wrapper := &Wrapper{
 		Msg: &Wrapper_ActionRequestMessage{
 			ActionRequestMessage: &ActionRequest{
-				Type: ActionType_COMPUTE,
-				Plugin: &Plugin{
-					Name:   pluginName,
-					Plugin: plugin,
-				},
-				ComputeType: ComputeType_REDUCE,
-				FileNames:   targetFilenames,
-				ReducerNumber: reducerNumber,
+				Type:           ActionType_COMPUTE,
+				JobId:          jobId,
+				Plugin:         plugin,
+				ComputeType:    ComputeType_REDUCE,
+				FileNames:      targetFilenames,
+				ReducerNumber:  reducerNumber,
 				OutputFilename: outputFilename,
 			},
 		},
@@ -219,6 +420,7 @@ func (m *MessageHandler) SendReduceRequest(
 }
 
 func (m *MessageHandler) SendComputeStore(
+	jobId string,
 	filename string,
 	file []byte,
 ) error {
@@ -226,6 +428,7 @@ func (m *MessageHandler) SendComputeStore(
 		Msg: &Wrapper_ActionRequestMessage{
 			ActionRequestMessage: &ActionRequest{
 				Type:     ActionType_COMPUTE_STORE,
+				JobId:    jobId,
 				FileName: filename,
 				Data:     file,
 			},
@@ -268,11 +471,58 @@ func (m *MessageHandler) SendNodes(nodes []*Node) error {
 	return m.Send(wrapper)
 }
 
-func (m *MessageHandler) SendFilesMetadata(files []*File) error {
+/** PUT response: nodes to upload to and the storage policy chunks must follow */
+func (m *MessageHandler) SendUploadNodes(nodes []*Node, storagePolicy string) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_StorageNodesMessage{
+			StorageNodesMessage: &StorageNodes{
+				Nodes:         nodes,
+				StoragePolicy: storagePolicy,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** CLUSTER_STATS response: storage nodes plus the controller's safe mode state */
+func (m *MessageHandler) SendClusterStats(nodes []*Node, safeMode *SafeMode) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_StorageNodesMessage{
+			StorageNodesMessage: &StorageNodes{
+				Nodes:    nodes,
+				SafeMode: safeMode,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+func (m *MessageHandler) SendFsckReport(report *FsckReport) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_FsckReportMessage{
+			FsckReportMessage: report,
+		},
+	}
+	return m.Send(wrapper)
+}
+
+func (m *MessageHandler) SendSamples(keys []string) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_SamplesMessage{
+			SamplesMessage: &Samples{
+				Keys: keys,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+func (m *MessageHandler) SendFilesMetadata(files []*File, quotas []*Quota) error {
 	wrapper := &Wrapper{
 		Msg: &Wrapper_FilesMessage{
 			FilesMessage: &Files{
-				Files: files,
+				Files:  files,
+				Quotas: quotas,
 			},
 		},
 	}
@@ -305,7 +555,7 @@ func (m *MessageHandler) SendComputationStatus(
 	return m.Send(wrapper)
 }
 
-func (m *MessageHandler) SendMappersOutputTable(filesTable map[string]*Node) error {
+func (m *MessageHandler) SendMappersOutputTable(filesTable map[string]*Node, counters *Counters) error {
 	wrapper := &Wrapper{
 		Msg: &Wrapper_ComputationStatusMessage{
 			ComputationStatusMessage: &ComputationStatus{
@@ -313,6 +563,35 @@ func (m *MessageHandler) SendMappersOutputTable(filesTable map[string]*Node) err
 				Ok:           true,
 				ErrorMessage: "",
 				FilesTable:   filesTable,
+				Counters:     counters,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** ahead is the number of jobs that will start before this one */
+func (m *MessageHandler) SendJobQueued(jobId string, ahead int) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ComputationStatusMessage{
+			ComputationStatusMessage: &ComputationStatus{
+				Status:       JobStatus_job_queued,
+				Ok:           true,
+				ErrorMessage: strconv.Itoa(ahead) + " jobs ahead",
+				JobId:        jobId,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+func (m *MessageHandler) SendJobDone(counters *Counters) error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ComputationStatusMessage{
+			ComputationStatusMessage: &ComputationStatus{
+				Status:   JobStatus_job_done,
+				Ok:       true,
+				Counters: counters,
 			},
 		},
 	}
@@ -334,3 +613,43 @@ func (m *MessageHandler) sendAck(ok bool, msg string) {
 		},
 	})
 }
+
+func (m *MessageHandler) SendWhoIsLeaderRequest() error {
+	wrapper := &Wrapper{
+		Msg: &Wrapper_ActionRequestMessage{
+			ActionRequestMessage: &ActionRequest{
+				Type: ActionType_WHO_IS_LEADER,
+			},
+		},
+	}
+	return m.Send(wrapper)
+}
+
+/** Leader is the address of the leading controller, empty while there is none */
+func (m *MessageHandler) SendLeaderAck(isLeader bool, leader string) {
+	m.sendAck(isLeader, leader)
+}
+
+func (m *MessageHandler) SendVoteRequest(voteRequest *VoteRequest) error {
+	return m.Send(&Wrapper{
+		Msg: &Wrapper_VoteRequestMessage{VoteRequestMessage: voteRequest},
+	})
+}
+
+func (m *MessageHandler) SendVoteResponse(voteResponse *VoteResponse) error {
+	return m.Send(&Wrapper{
+		Msg: &Wrapper_VoteResponseMessage{VoteResponseMessage: voteResponse},
+	})
+}
+
+func (m *MessageHandler) SendAppendEntries(appendEntries *AppendEntries) error {
+	return m.Send(&Wrapper{
+		Msg: &Wrapper_AppendEntriesMessage{AppendEntriesMessage: appendEntries},
+	})
+}
+
+func (m *MessageHandler) SendAppendEntriesResponse(response *AppendEntriesResponse) error {
+	return m.Send(&Wrapper{
+		Msg: &Wrapper_AppendEntriesResponseMessage{AppendEntriesResponseMessage: response},
+	})
+}
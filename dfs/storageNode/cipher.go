@@ -0,0 +1,214 @@
+package storageNode
+
+import (
+	m "adfs/messages"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const MASTER_KEY_SIZE = 32 // AES-256
+const DATA_KEY_SIZE = 32
+
+// wrapped data keys live next to the chunks; dot dirs are skipped on scans
+const KEYS_DIR = "/.keys/"
+
+/**
+* Encrypts chunk data at rest (envelope encryption). Every file gets its own
+* data key, stored on disk wrapped by the cluster master key.
+ */
+type ChunkCipher interface {
+	Seal(chunk *m.Chunk) error
+	Open(chunk *m.Chunk) error
+	// drops the data key of a file once no chunk sealed with it is left
+	Forget(filename string) error
+}
+
+/** Used when no master key is configured: chunks are stored in plaintext */
+type PlainChunkCipher struct{}
+
+/** AES-GCM for both chunk data (data key) and data keys (master key) */
+type AESChunkCipher struct {
+	masterKey []byte
+	keysDir   string
+	dataKeys  map[string][]byte // [filename] unwrapped data key
+	mutex     sync.Mutex
+}
+
+/** Empty masterKeyFile disables encryption at rest */
+func NewChunkCipher(masterKeyFile, storageDir string) (ChunkCipher, error) {
+	if masterKeyFile == "" {
+		return &PlainChunkCipher{}, nil
+	}
+	masterKey, err := readMasterKey(masterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &AESChunkCipher{
+		masterKey: masterKey,
+		keysDir:   storageDir + KEYS_DIR,
+		dataKeys:  make(map[string][]byte),
+	}, nil
+}
+
+/** Keyfile holds the 32 byte master key either raw or hex encoded */
+func readMasterKey(masterKeyFile string) ([]byte, error) {
+	content, err := os.ReadFile(masterKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(content) == MASTER_KEY_SIZE {
+		return content, nil
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil || len(key) != MASTER_KEY_SIZE {
+		return nil, errors.New("master key file must contain a 32 byte key (raw or hex encoded)")
+	}
+	return key, nil
+}
+
+func (c *PlainChunkCipher) Seal(chunk *m.Chunk) error {
+	return nil
+}
+
+func (c *PlainChunkCipher) Open(chunk *m.Chunk) error {
+	if chunk.Encrypted {
+		return errors.New("chunk " + chunk.ChunkName + " is encrypted but no master key was provided")
+	}
+	return nil
+}
+
+func (c *PlainChunkCipher) Forget(filename string) error {
+	return nil
+}
+
+func (c *AESChunkCipher) Seal(chunk *m.Chunk) error {
+	if chunk.Encrypted {
+		return nil
+	}
+	dataKey, err := c.getDataKey(chunk.FileName, true)
+	if err != nil {
+		return err
+	}
+	// binding the chunk name stops a sealed chunk from being read back as another one
+	sealed, err := encrypt(dataKey, chunk.Data, []byte(chunk.ChunkName))
+	if err != nil {
+		return err
+	}
+	chunk.Data = sealed
+	chunk.Encrypted = true
+	return nil
+}
+
+func (c *AESChunkCipher) Open(chunk *m.Chunk) error {
+	if !chunk.Encrypted {
+		return nil
+	}
+	dataKey, err := c.getDataKey(chunk.FileName, false)
+	if err != nil {
+		return err
+	}
+	data, err := decrypt(dataKey, chunk.Data, []byte(chunk.ChunkName))
+	if err != nil {
+		return err
+	}
+	chunk.Data = data
+	chunk.Encrypted = false
+	return nil
+}
+
+/** Loads (and unwraps) the data key of filename, creating it on first use if allowed */
+func (c *AESChunkCipher) getDataKey(filename string, create bool) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if dataKey, present := c.dataKeys[filename]; present {
+		return dataKey, nil
+	}
+	keyPath := c.keysDir + keyFilename(filename)
+	wrapped, err := os.ReadFile(keyPath)
+	if err == nil {
+		dataKey, err := decrypt(c.masterKey, wrapped, []byte(filename))
+		if err != nil {
+			return nil, errors.New("cannot unwrap data key of " + filename + ": " + err.Error())
+		}
+		c.dataKeys[filename] = dataKey
+		return dataKey, nil
+	}
+	if !create || !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	dataKey := make([]byte, DATA_KEY_SIZE)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	wrapped, err = encrypt(c.masterKey, dataKey, []byte(filename))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(c.keysDir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyPath, wrapped, 0600); err != nil {
+		return nil, err
+	}
+	c.dataKeys[filename] = dataKey
+	return dataKey, nil
+}
+
+func (c *AESChunkCipher) Forget(filename string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.dataKeys, filename)
+	err := os.Remove(c.keysDir + keyFilename(filename))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+/** Filenames are DFS paths; hashing them gives a flat, safe name for the key file */
+func keyFilename(filename string) string {
+	sum := sha256.Sum256([]byte(filename))
+	return hex.EncodeToString(sum[:])
+}
+
+/** Output is nonce + ciphertext; aad is authenticated, not stored, and must be given again to decrypt */
+func encrypt(key, plaintext, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func decrypt(key, sealed, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("sealed data is too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,41 @@
+package storageNode
+
+import (
+	"adfs/helpers"
+	"errors"
+	"os"
+	"strings"
+)
+
+// dot files are skipped on scans, so the id never shows up as a chunk
+const NODE_ID_FILE = "/.node-id"
+
+/**
+* Returns the id stored in storageDir, generating and storing a new one on
+* first start. Reusing it lets the Controller recognize a restarted node.
+ */
+func LoadNodeId(storageDir string) (string, error) {
+	idPath := storageDir + NODE_ID_FILE
+	content, err := os.ReadFile(idPath)
+	if err == nil {
+		uuid := strings.TrimSpace(string(content))
+		if uuid == "" {
+			return "", errors.New(idPath + " is empty")
+		}
+		return uuid, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	uuid, err := helpers.NewUUID()
+	if err != nil {
+		return "", err
+	}
+	if err := helpers.CreatePaths(storageDir); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(idPath, []byte(uuid+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return uuid, nil
+}
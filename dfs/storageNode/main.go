@@ -1,37 +1,50 @@
 package storageNode
 
 import (
-	"adfs/helpers"
 	s "adfs/server"
 )
 
 type Config struct {
-	Port               int
-	ControllerHostname string
-	ControllerPort     int
-	StorageDir         string
-	PluginsDir         string
-	ComputeStorageDir  string
+	Port              int
+	ControllerAddrs   []string // every controller; the node talks to the leader
+	StorageDir        string
+	StorageDirs       []string // more than one spreads chunks across disks (JBOD)
+	StorageBackend    string   // local/memory/jbod
+	PluginsDir        string
+	ComputeStorageDir string
+	MasterKeyFile     string // enables encryption at rest when set
+	ReservedSpace     int64  // bytes kept free on every storage dir
 }
 
 func Init(config Config) {
-	uuid, uuidErr := helpers.NewUUID()
-	controllerAddr := helpers.GetAddr(config.ControllerHostname, config.ControllerPort)
+	uuid, uuidErr := LoadNodeId(config.StorageDir)
 	server, serverErr := s.NewServerAt(config.Port)
-	storageIO := NewStorageIO()
-	statsBoard := NewStatsBoard()
+	storageDirs := config.StorageDirs
+	if len(storageDirs) == 0 {
+		storageDirs = []string{config.StorageDir}
+	}
+	storageIO, storageIOErr := NewStorageIOFor(config.StorageBackend, storageDirs, config.ReservedSpace)
+	statsBoard := NewStatsBoard(storageIO)
+	chunkCipher, cipherErr := NewChunkCipher(config.MasterKeyFile, config.StorageDir)
 	if serverErr != nil {
 		panic(serverErr)
 	}
+	if cipherErr != nil {
+		panic(cipherErr)
+	}
+	if storageIOErr != nil {
+		panic(storageIOErr)
+	}
 	if uuidErr != nil {
 		panic(uuidErr)
 	}
 	storageNode := NewStorageNode(
 		uuid,
-		controllerAddr,
+		config.ControllerAddrs,
 		server,
 		storageIO,
 		statsBoard,
+		chunkCipher,
 		config.StorageDir,
 		config.PluginsDir,
 		config.ComputeStorageDir,
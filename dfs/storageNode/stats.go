@@ -2,9 +2,6 @@ package storageNode
 
 import (
 	m "adfs/messages"
-	"os"
-
-	"golang.org/x/sys/unix"
 )
 
 const ADD_DOWNLOADED = "add_downloaded"
@@ -28,13 +25,15 @@ type StatsBoardImpl struct {
 	downloaded int
 	uploaded   int
 	replicated int
-	freeSpace  int64
+	storageIO  StorageIO
 	updatesCh  chan string
 	quit       chan bool
 }
 
-func NewStatsBoard() StatsBoard {
+/** Disk figures come from the storage dirs behind storageIO */
+func NewStatsBoard(storageIO StorageIO) StatsBoard {
 	return &StatsBoardImpl{
+		storageIO: storageIO,
 		updatesCh: make(chan string),
 		quit:      make(chan bool),
 	}
@@ -72,21 +71,30 @@ func (sm *StatsBoardImpl) AddReplicated() {
 	sm.updatesCh <- ADD_REPLICATED
 }
 
+/** GB left for chunks across all storage dirs, reserve excluded */
 func (sm *StatsBoardImpl) GetFreeSpace() int {
-	var stat unix.Statfs_t
-	wd, _ := os.Getwd()
-	unix.Statfs(wd, &stat)
-	toGb := 1 << 30
-	// Available blocks * size per block = available space in bytes
-	return int((stat.Bavail * uint64(stat.Bsize)) / uint64(toGb))
+	var available int64 = 0
+	for _, disk := range sm.storageIO.DiskStats() {
+		available += disk.Available
+	}
+	return int(available >> 30)
 }
+
 func (sm *StatsBoardImpl) GetAll() *m.Stats {
-	return &m.Stats{
+	stats := &m.Stats{
 		Downloaded: int32(sm.downloaded),
 		Uploaded:   int32(sm.uploaded),
 		Replicated: int32(sm.replicated),
-		FreeSpace:  int32(sm.GetFreeSpace()),
+		Disks:      sm.storageIO.DiskStats(),
+	}
+	for _, disk := range stats.Disks {
+		stats.Capacity += disk.Capacity
+		stats.Used += disk.Used
+		stats.Reserved += disk.Reserved
+		stats.Available += disk.Available
 	}
+	stats.FreeSpace = int32(stats.Available >> 30)
+	return stats
 }
 
 func (sm *StatsBoardImpl) worker() {
@@ -3,62 +3,189 @@ package storageNode
 import (
 	"adfs/helpers"
 	m "adfs/messages"
+	"errors"
+	"fmt"
+	"hash/crc32"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
 	"google.golang.org/protobuf/proto"
 )
 
+// storage backends
+const LOCAL_BACKEND = "local"
+const MEMORY_BACKEND = "memory"
+const JBOD_BACKEND = "jbod"
+
+/** Chunk persistence. Chunks are addressed by chunk name; backends decide where they live */
 type StorageIO interface {
-	Persist(filename string, data []byte)
-	ScanMetadata(dir string) []*m.Chunk
-	Retrieve(filename string) ([]byte, error)
-	Delete(filename string) error
+	Persist(chunkName string, data []byte) error
+	ScanMetadata() []*m.Chunk
+	Retrieve(chunkName string) ([]byte, error)
+	Delete(chunkName string) error
+	DiskStats() []*m.DiskStats
+}
+
+// free space kept on every storage dir when none is configured
+const DEFAULT_RESERVED_SPACE int64 = 1 << 30
+
+var ErrStorageFull = errors.New("storage full")
+var ErrCorruptChunk = errors.New("corrupt chunk")
+
+/** Single storage dir on the local filesystem */
+type StorageIOImpl struct {
+	storageDir string
+	reserved   int64 // bytes kept free on the disk; Persist refuses chunks below it
 }
 
-type StorageIOImpl struct{}
+/** Chunks kept in memory; nothing survives a restart. Meant for tests */
+type MemoryStorageIO struct {
+	chunks map[string][]byte
+	mutex  sync.RWMutex
+}
 
-func NewStorageIO() StorageIO {
-	return &StorageIOImpl{}
+/** Just a bunch of disks: chunks spread across several storage dirs by free space */
+type JBODStorageIO struct {
+	disks     []*StorageIOImpl
+	locations map[string]*StorageIOImpl // [chunkName] disk holding it
+	mutex     sync.Mutex
+}
+
+/**
+* Picks the backend: memory on request, JBOD if more than one storage dir was
+* given. reserved bytes are kept free on every storage dir.
+ */
+func NewStorageIOFor(backend string, storageDirs []string, reserved int64) (StorageIO, error) {
+	switch backend {
+	case MEMORY_BACKEND:
+		return NewMemoryStorageIO(), nil
+	case LOCAL_BACKEND, JBOD_BACKEND, "":
+		if len(storageDirs) == 0 {
+			return nil, errors.New("no storage dir provided")
+		}
+		if len(storageDirs) == 1 && backend != JBOD_BACKEND {
+			return NewStorageIO(storageDirs[0], reserved), nil
+		}
+		return NewJBODStorageIO(storageDirs, reserved), nil
+	}
+	return nil, errors.New("unknown storage backend " + backend)
+}
+
+func NewStorageIO(storageDir string, reserved int64) StorageIO {
+	return &StorageIOImpl{storageDir: storageDir, reserved: reserved}
+}
+
+func NewMemoryStorageIO() StorageIO {
+	return &MemoryStorageIO{chunks: make(map[string][]byte)}
+}
+
+func NewJBODStorageIO(storageDirs []string, reserved int64) StorageIO {
+	disks := make([]*StorageIOImpl, len(storageDirs))
+	for i, dir := range storageDirs {
+		disks[i] = &StorageIOImpl{storageDir: dir, reserved: reserved}
+	}
+	return &JBODStorageIO{
+		disks:     disks,
+		locations: make(map[string]*StorageIOImpl),
+	}
 }
 
 /** Creates a file and all the folders in the path */
-func (sio *StorageIOImpl) Persist(filename string, data []byte) {
+func (sio *StorageIOImpl) Persist(chunkName string, data []byte) error {
+	if err := sio.checkSpace(int64(len(data))); err != nil {
+		return err
+	}
+	filename := sio.storageDir + chunkName
 	err := helpers.CreatePaths(helpers.GetPathFrom(filename))
 	if err != nil {
 		logrus.Error(err.Error())
-		return
+		return err
 	}
 	err = os.WriteFile(filename, data, os.ModePerm)
 	if err != nil {
 		logrus.Error(err.Error())
 	}
+	return err
 }
 
-/** This is not adding any value */
-func (sio *StorageIOImpl) Retrieve(filename string) ([]byte, error) {
-	return os.ReadFile(filename)
+func (sio *StorageIOImpl) Retrieve(chunkName string) ([]byte, error) {
+	return os.ReadFile(sio.storageDir + chunkName)
 }
 
-/** This is not adding any value */
-func (sio *StorageIOImpl) Delete(filename string) error {
-	return os.Remove(filename)
+func (sio *StorageIOImpl) Delete(chunkName string) error {
+	return os.Remove(sio.storageDir + chunkName)
 }
 
-func (sio *StorageIOImpl) ScanMetadata(storageDir string) []*m.Chunk {
+func (sio *StorageIOImpl) ScanMetadata() []*m.Chunk {
 	var localFiles []*m.Chunk
-	localFiles, err := sio.scanDir(localFiles, storageDir)
+	localFiles, err := sio.scanDir(localFiles, sio.storageDir)
 	if err != nil { // added this in case scanning happens while file is being deleted
 		<-time.After(1 * time.Second)
-		return sio.ScanMetadata(storageDir)
+		return sio.ScanMetadata()
 	}
 	return localFiles
 }
 
+/** Available bytes on the disk holding the storage dir */
+func (sio *StorageIOImpl) freeSpace() uint64 {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(sio.statfsDir(), &stat); err != nil {
+		return 0
+	}
+	return stat.Bavail * uint64(stat.Bsize)
+}
+
+/** Storage dir may not exist before the first chunk lands; its closest existing parent is on the same disk */
+func (sio *StorageIOImpl) statfsDir() string {
+	dir := sio.storageDir
+	for dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		dir = helpers.GetPathFrom(dir)
+	}
+	return "/"
+}
+
+func (sio *StorageIOImpl) checkSpace(size int64) error {
+	if int64(sio.freeSpace())-size < sio.reserved {
+		return fmt.Errorf("%w: %s has %d bytes free and keeps %d bytes in reserve",
+			ErrStorageFull, sio.storageDir, sio.freeSpace(), sio.reserved)
+	}
+	return nil
+}
+
+func (sio *StorageIOImpl) DiskStats() []*m.DiskStats {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(sio.statfsDir(), &stat); err != nil {
+		return []*m.DiskStats{{Dir: sio.storageDir, Reserved: sio.reserved}}
+	}
+	capacity := int64(stat.Blocks) * int64(stat.Bsize)
+	free := int64(stat.Bfree) * int64(stat.Bsize)
+	available := int64(stat.Bavail)*int64(stat.Bsize) - sio.reserved
+	if available < 0 {
+		available = 0
+	}
+	return []*m.DiskStats{{
+		Dir:       sio.storageDir,
+		Capacity:  capacity,
+		Used:      capacity - free,
+		Reserved:  sio.reserved,
+		Available: available,
+	}}
+}
+
 func (sio *StorageIOImpl) scanDir(localFiles []*m.Chunk, dirname string) ([]*m.Chunk, error) {
 	entries := helpers.GetDirEntries(dirname)
 	for _, f := range entries {
+		if strings.HasPrefix(f.Name(), ".") {
+			continue // node bookkeeping (e.g. wrapped data keys), not chunks
+		}
 		subDir := dirname + "/" + f.Name()
 		if f.IsDir() {
 			subDirFiles, err := sio.scanDir(localFiles, subDir)
@@ -67,23 +194,156 @@ func (sio *StorageIOImpl) scanDir(localFiles []*m.Chunk, dirname string) ([]*m.C
 			}
 			localFiles = append(localFiles, subDirFiles...)
 		} else {
-			chunk, err := readProtoMetadata(subDir)
+			data, err := os.ReadFile(subDir)
 			if err != nil {
 				return nil, err
 			}
+			chunk, err := readProtoMetadata(data)
+			if err != nil {
+				chunkName := filepath.Clean("/" + strings.TrimPrefix(subDir, sio.storageDir))
+				chunk = corruptChunk(chunkName, err)
+			}
 			localFiles = append(localFiles, chunk)
 		}
 	}
 	return localFiles, nil
 }
 
-func readProtoMetadata(filename string) (*m.Chunk, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, err
+func (mio *MemoryStorageIO) Persist(chunkName string, data []byte) error {
+	mio.mutex.Lock()
+	defer mio.mutex.Unlock()
+	copied := make([]byte, len(data))
+	copy(copied, data)
+	mio.chunks[chunkName] = copied
+	return nil
+}
+
+func (mio *MemoryStorageIO) Retrieve(chunkName string) ([]byte, error) {
+	mio.mutex.RLock()
+	defer mio.mutex.RUnlock()
+	data, present := mio.chunks[chunkName]
+	if !present {
+		return nil, os.ErrNotExist
 	}
+	return data, nil
+}
+
+func (mio *MemoryStorageIO) Delete(chunkName string) error {
+	mio.mutex.Lock()
+	defer mio.mutex.Unlock()
+	if _, present := mio.chunks[chunkName]; !present {
+		return os.ErrNotExist
+	}
+	delete(mio.chunks, chunkName)
+	return nil
+}
+
+/** No fixed capacity: only what is held is reported */
+func (mio *MemoryStorageIO) DiskStats() []*m.DiskStats {
+	mio.mutex.RLock()
+	defer mio.mutex.RUnlock()
+	var used int64 = 0
+	for _, data := range mio.chunks {
+		used += int64(len(data))
+	}
+	return []*m.DiskStats{{Dir: MEMORY_BACKEND, Used: used}}
+}
+
+func (mio *MemoryStorageIO) ScanMetadata() []*m.Chunk {
+	mio.mutex.RLock()
+	defer mio.mutex.RUnlock()
+	chunks := make([]*m.Chunk, 0)
+	for chunkName, data := range mio.chunks {
+		chunk, err := readProtoMetadata(data)
+		if err != nil {
+			chunk = corruptChunk(chunkName, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+/** Overwrites in place if the chunk exists, otherwise goes to the disk with more free space */
+func (jio *JBODStorageIO) Persist(chunkName string, data []byte) error {
+	disk := jio.locate(chunkName)
+	if disk == nil {
+		disk = jio.emptiestDisk()
+	}
+	if err := disk.Persist(chunkName, data); err != nil {
+		return err
+	}
+	jio.mutex.Lock()
+	jio.locations[chunkName] = disk
+	jio.mutex.Unlock()
+	return nil
+}
+
+func (jio *JBODStorageIO) Retrieve(chunkName string) ([]byte, error) {
+	disk := jio.locate(chunkName)
+	if disk == nil {
+		return nil, os.ErrNotExist
+	}
+	return disk.Retrieve(chunkName)
+}
+
+func (jio *JBODStorageIO) Delete(chunkName string) error {
+	disk := jio.locate(chunkName)
+	if disk == nil {
+		return os.ErrNotExist
+	}
+	jio.mutex.Lock()
+	delete(jio.locations, chunkName)
+	jio.mutex.Unlock()
+	return disk.Delete(chunkName)
+}
+
+func (jio *JBODStorageIO) DiskStats() []*m.DiskStats {
+	stats := make([]*m.DiskStats, 0)
+	for _, disk := range jio.disks {
+		stats = append(stats, disk.DiskStats()...)
+	}
+	return stats
+}
+
+func (jio *JBODStorageIO) ScanMetadata() []*m.Chunk {
+	chunks := make([]*m.Chunk, 0)
+	for _, disk := range jio.disks {
+		chunks = append(chunks, disk.ScanMetadata()...)
+	}
+	return chunks
+}
+
+/** Disk holding chunkName. Falls back to checking every disk (e.g. after a restart) */
+func (jio *JBODStorageIO) locate(chunkName string) *StorageIOImpl {
+	jio.mutex.Lock()
+	defer jio.mutex.Unlock()
+	if disk, present := jio.locations[chunkName]; present {
+		return disk
+	}
+	for _, disk := range jio.disks {
+		if _, err := os.Stat(disk.storageDir + chunkName); err == nil {
+			jio.locations[chunkName] = disk
+			return disk
+		}
+	}
+	return nil
+}
+
+func (jio *JBODStorageIO) emptiestDisk() *StorageIOImpl {
+	emptiest := jio.disks[0]
+	mostFreeSpace := emptiest.freeSpace()
+	for _, disk := range jio.disks[1:] {
+		if freeSpace := disk.freeSpace(); freeSpace > mostFreeSpace {
+			emptiest = disk
+			mostFreeSpace = freeSpace
+		}
+	}
+	return emptiest
+}
+
+func readProtoMetadata(data []byte) (*m.Chunk, error) {
 	chunk := &m.Chunk{}
-	err = proto.Unmarshal(data, chunk)
+	err := proto.Unmarshal(data, chunk)
 	if err != nil {
 		return nil, err
 	} else {
@@ -92,12 +352,40 @@ func readProtoMetadata(filename string) (*m.Chunk, error) {
 		// controller will add this node as owner of chunk.
 		// So we're sending all the info BUT the actual data
 		return &m.Chunk{
-			FileName:  chunk.FileName,
-			ChunkName: chunk.ChunkName,
-			Serial:    chunk.Serial,
-			Size:      chunk.Size,
-			Offset:    chunk.Offset,
-			FileSize:  chunk.FileSize,
+			FileName:     chunk.FileName,
+			ChunkName:    chunk.ChunkName,
+			Serial:       chunk.Serial,
+			Size:         chunk.Size,
+			Offset:       chunk.Offset,
+			FileSize:     chunk.FileSize,
+			Replication:  chunk.Replication,
+			Stripe:       chunk.Stripe,
+			StripeIndex:  chunk.StripeIndex,
+			StripeWidth:  chunk.StripeWidth,
+			DataShards:   chunk.DataShards,
+			ParityShards: chunk.ParityShards,
+			ShardSize:    chunk.ShardSize,
+			StripeSizes:  chunk.StripeSizes,
+			Codec:        chunk.Codec,
+			RawSize:      chunk.RawSize,
+			TotalChunks:  chunk.TotalChunks,
+			Checksum:     chunk.Checksum,
+			Encrypted:    chunk.Encrypted,
+			Corrupt:      verifyChecksum(chunk) != nil,
 		}, nil
 	}
 }
+
+/** Stored data must match the checksum taken when it was persisted */
+func verifyChecksum(chunk *m.Chunk) error {
+	if chunk.Checksum != 0 && crc32.ChecksumIEEE(chunk.Data) != chunk.Checksum {
+		return fmt.Errorf("%w: %s does not match its checksum", ErrCorruptChunk, chunk.ChunkName)
+	}
+	return nil
+}
+
+/** Unreadable replica; block reports flag it so the controller stops relying on it */
+func corruptChunk(chunkName string, err error) *m.Chunk {
+	logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Corrupt chunk")
+	return &m.Chunk{ChunkName: chunkName, Corrupt: true}
+}
@@ -0,0 +1,245 @@
+package storageNode
+
+import (
+	m "adfs/messages"
+	"errors"
+	"hash/crc32"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func marshalChunk(t *testing.T, chunk *m.Chunk) []byte {
+	t.Helper()
+	chunk.Checksum = crc32.ChecksumIEEE(chunk.Data)
+	data, err := proto.Marshal(chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestMemoryStorageIOPersistRetrieveDelete(t *testing.T) {
+	storageIO := NewMemoryStorageIO()
+	data := []byte("some chunk")
+	if err := storageIO.Persist("/in/a.txt-0", data); err != nil {
+		t.Fatal(err)
+	}
+	data[0] = 'S' // the backend keeps its own copy
+
+	retrieved, err := storageIO.Retrieve("/in/a.txt-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(retrieved) != "some chunk" {
+		t.Errorf("retrieved %q, want %q", retrieved, "some chunk")
+	}
+
+	if err := storageIO.Delete("/in/a.txt-0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storageIO.Retrieve("/in/a.txt-0"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("retrieve after delete: got %v, want %v", err, os.ErrNotExist)
+	}
+	if err := storageIO.Delete("/in/a.txt-0"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("second delete: got %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestMemoryStorageIOScanMetadata(t *testing.T) {
+	storageIO := NewMemoryStorageIO()
+	healthy := marshalChunk(t, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-0", Data: []byte("abc")})
+	storageIO.Persist("/in/a.txt-0", healthy)
+	storageIO.Persist("/in/a.txt-1", []byte{0xff, 0xff, 0xff}) // not a chunk at all
+	tampered := &m.Chunk{}
+	proto.Unmarshal(marshalChunk(t, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-2", Data: []byte("def")}), tampered)
+	tampered.Data = []byte("xyz")
+	bytes, _ := proto.Marshal(tampered)
+	storageIO.Persist("/in/a.txt-2", bytes)
+
+	chunks := make(map[string]*m.Chunk)
+	for _, chunk := range storageIO.ScanMetadata() {
+		chunks[chunk.ChunkName] = chunk
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("scanned %d chunks, want 3", len(chunks))
+	}
+	if chunk := chunks["/in/a.txt-0"]; chunk.Corrupt || chunk.FileName != "/in/a.txt" || chunk.Data != nil {
+		t.Errorf("healthy chunk reported as %v", chunk)
+	}
+	if !chunks["/in/a.txt-1"].Corrupt {
+		t.Error("unreadable chunk not reported as corrupt")
+	}
+	if !chunks["/in/a.txt-2"].Corrupt {
+		t.Error("chunk failing its checksum not reported as corrupt")
+	}
+}
+
+func TestCheckSpaceRefusesChunksBelowReserve(t *testing.T) {
+	storageIO := NewStorageIO(t.TempDir(), math.MaxInt64/2)
+	err := storageIO.Persist("/in/a.txt-0", []byte("abc"))
+	if !errors.Is(err, ErrStorageFull) {
+		t.Fatalf("got %v, want %v", err, ErrStorageFull)
+	}
+	if _, err := storageIO.Retrieve("/in/a.txt-0"); err == nil {
+		t.Error("refused chunk was written")
+	}
+
+	if err := NewStorageIO(t.TempDir(), 0).Persist("/in/a.txt-0", []byte("abc")); err != nil {
+		t.Errorf("no reserve: got %v", err)
+	}
+}
+
+/** Storage node over memory, driven through the same handlers as incoming connections */
+func newTestStorageNode(t *testing.T, chunkCipher ChunkCipher) (*StorageNodeImpl, StorageIO) {
+	t.Helper()
+	storageIO := NewMemoryStorageIO()
+	statsBoard := NewStatsBoard(storageIO)
+	statsBoard.Start()
+	t.Cleanup(statsBoard.Stop)
+	dir := t.TempDir()
+	sn := NewStorageNode("n1", []string{"localhost:0"}, nil, storageIO, statsBoard, chunkCipher, dir, dir, dir)
+	return sn.(*StorageNodeImpl), storageIO
+}
+
+/** Runs handler against one end of a pipe and returns what it sent back */
+func exchange(t *testing.T, handler func(*m.MessageHandler)) *m.Wrapper {
+	t.Helper()
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		handler(m.NewMessageHandler(server))
+		server.Close()
+	}()
+	wrapper, err := m.NewMessageHandler(client).Receive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wrapper
+}
+
+func putChunk(t *testing.T, sn *StorageNodeImpl, chunk *m.Chunk) {
+	t.Helper()
+	// listing an owner keeps the node from replicating the chunk
+	chunk.StorageNodes = map[string]*m.Node{"n1": {Uuid: "n1"}}
+	wrapper := exchange(t, func(mh *m.MessageHandler) { sn.handlePutRequest(mh, chunk) })
+	if ack := wrapper.GetAckMessage(); ack == nil || !ack.Ok {
+		t.Fatalf("put %s: %v", chunk.ChunkName, wrapper)
+	}
+}
+
+func getChunk(t *testing.T, sn *StorageNodeImpl, chunkName string) *m.Wrapper {
+	t.Helper()
+	return exchange(t, func(mh *m.MessageHandler) { sn.handleGetRequest(mh, chunkName) })
+}
+
+func TestStorageNodePutGetRemove(t *testing.T) {
+	sn, storageIO := newTestStorageNode(t, &PlainChunkCipher{})
+	putChunk(t, sn, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-0", Data: []byte("abc")})
+
+	chunk := getChunk(t, sn, "/in/a.txt-0").GetChunkMessage()
+	if chunk == nil || string(chunk.Data) != "abc" {
+		t.Fatalf("got %v, want chunk with data abc", chunk)
+	}
+
+	sn.handleRemoveRequest("/in/a.txt-0")
+	if len(storageIO.ScanMetadata()) != 0 {
+		t.Error("chunk still stored after remove")
+	}
+	if ack := getChunk(t, sn, "/in/a.txt-0").GetAckMessage(); ack == nil || ack.Ok {
+		t.Error("get of a removed chunk did not fail")
+	}
+}
+
+func TestStorageNodeRefusesCorruptChunk(t *testing.T) {
+	sn, storageIO := newTestStorageNode(t, &PlainChunkCipher{})
+	putChunk(t, sn, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-0", Data: []byte("abc")})
+	stored, _ := storageIO.Retrieve("/in/a.txt-0")
+	chunk := &m.Chunk{}
+	proto.Unmarshal(stored, chunk)
+	chunk.Data = []byte("abd")
+	bytes, _ := proto.Marshal(chunk)
+	storageIO.Persist("/in/a.txt-0", bytes)
+
+	if ack := getChunk(t, sn, "/in/a.txt-0").GetAckMessage(); ack == nil || ack.Ok {
+		t.Error("corrupt chunk was served")
+	}
+}
+
+func TestStorageNodeDropsDataKeyWithLastChunk(t *testing.T) {
+	masterKeyFile := filepath.Join(t.TempDir(), "master.key")
+	os.WriteFile(masterKeyFile, make([]byte, MASTER_KEY_SIZE), 0600)
+	storageDir := t.TempDir()
+	chunkCipher, err := NewChunkCipher(masterKeyFile, storageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn, _ := newTestStorageNode(t, chunkCipher)
+	keyPath := storageDir + KEYS_DIR + keyFilename("/in/a.txt")
+
+	putChunk(t, sn, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-0", Data: []byte("abc")})
+	putChunk(t, sn, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-1", Data: []byte("def")})
+	if chunk := getChunk(t, sn, "/in/a.txt-1").GetChunkMessage(); chunk == nil || string(chunk.Data) != "def" {
+		t.Fatalf("got %v, want decrypted chunk with data def", chunk)
+	}
+
+	sn.handleRemoveRequest("/in/a.txt-0")
+	if _, err := os.Stat(keyPath); err != nil {
+		t.Fatalf("data key removed while a chunk still uses it: %v", err)
+	}
+	sn.handleRemoveRequest("/in/a.txt-1")
+	if _, err := os.Stat(keyPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("data key kept after the last chunk was removed: %v", err)
+	}
+}
+
+func TestSealedChunkOnlyOpensUnderItsName(t *testing.T) {
+	masterKeyFile := filepath.Join(t.TempDir(), "master.key")
+	os.WriteFile(masterKeyFile, make([]byte, MASTER_KEY_SIZE), 0600)
+	chunkCipher, err := NewChunkCipher(masterKeyFile, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-0", Data: []byte("abc")}
+	if err := chunkCipher.Seal(chunk); err != nil {
+		t.Fatal(err)
+	}
+	swapped := proto.Clone(chunk).(*m.Chunk)
+	swapped.ChunkName = "/in/a.txt-1"
+	if err := chunkCipher.Open(swapped); err == nil {
+		t.Error("chunk sealed as /in/a.txt-0 opened as /in/a.txt-1")
+	}
+	if err := chunkCipher.Open(chunk); err != nil || string(chunk.Data) != "abc" {
+		t.Errorf("got %q, %v; want abc", chunk.Data, err)
+	}
+}
+
+func TestRestartedStorageNodeDropsDataKeyWithLastChunk(t *testing.T) {
+	masterKeyFile := filepath.Join(t.TempDir(), "master.key")
+	os.WriteFile(masterKeyFile, make([]byte, MASTER_KEY_SIZE), 0600)
+	storageDir := t.TempDir()
+	chunkCipher, err := NewChunkCipher(masterKeyFile, storageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sn, storageIO := newTestStorageNode(t, chunkCipher)
+	putChunk(t, sn, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-0", Data: []byte("abc")})
+	putChunk(t, sn, &m.Chunk{FileName: "/in/a.txt", ChunkName: "/in/a.txt-1", Data: []byte("def")})
+
+	// a new node over the same storage learns what it holds from the stored chunks
+	dir := t.TempDir()
+	restarted := NewStorageNode("n1", []string{"localhost:0"}, nil, storageIO, NewStatsBoard(storageIO), chunkCipher, dir, dir, dir).(*StorageNodeImpl)
+	keyPath := storageDir + KEYS_DIR + keyFilename("/in/a.txt")
+	restarted.handleRemoveRequest("/in/a.txt-0")
+	if _, err := os.Stat(keyPath); err != nil {
+		t.Fatalf("data key removed while a chunk still uses it: %v", err)
+	}
+	restarted.handleRemoveRequest("/in/a.txt-1")
+	if _, err := os.Stat(keyPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("data key kept after the last chunk was removed: %v", err)
+	}
+}
@@ -2,17 +2,22 @@ package storageNode
 
 import (
 	c "adfs/client"
+	"adfs/common"
+	"adfs/compression"
 	"adfs/compute_engine"
+	extsort "adfs/external_sort"
 	"adfs/helpers"
 	m "adfs/messages"
 	s "adfs/server"
-	"bufio"
+	"bytes"
 	"errors"
+	"hash/crc32"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -20,7 +25,6 @@ import (
 )
 
 const HEARTBEAT_DELAY_S = 5
-const CHUNK_REPLICAS = 2
 
 type StorageNode interface {
 	Start()
@@ -32,10 +36,16 @@ type StorageNode interface {
 
 type StorageNodeImpl struct {
 	uuid               string
-	controllerAddr     string
+	controllerAddrs    []string // every controller
+	controllerAddr     string   // leader this node reports to
 	server             s.Server
 	storageIO          StorageIO
 	statsBoard         StatsBoard
+	chunkCipher        ChunkCipher
+	keysMutex          sync.RWMutex       // PUTs sealing chunks vs. RMs dropping the keys they use
+	sealedMutex        sync.Mutex         // sealedChunks and sealedCounts; PUTs update them concurrently
+	sealedChunks       map[string]string  // [chunkName] file whose data key sealed the chunk
+	sealedCounts       map[string]int     // [filename] chunks stored here under its data key
 	replicas           map[string]*m.Node // membership table
 	heartbeatScheduler *time.Ticker
 	heartbeatStatusCh  chan bool
@@ -47,32 +57,43 @@ type StorageNodeImpl struct {
 
 func NewStorageNode(
 	uuid string,
-	controllerAddr string,
+	controllerAddrs []string,
 	server s.Server,
 	storageIO StorageIO,
 	statsBoard StatsBoard,
+	chunkCipher ChunkCipher,
 	storageDir string,
 	pluginsDir string,
 	computeStorageDir string,
 ) StorageNode {
-	return &StorageNodeImpl{
+	sn := &StorageNodeImpl{
 		uuid:              uuid,
-		controllerAddr:    controllerAddr,
+		controllerAddrs:   controllerAddrs,
+		controllerAddr:    controllerAddrs[0],
 		server:            server,
 		storageIO:         storageIO,
 		statsBoard:        statsBoard,
+		chunkCipher:       chunkCipher,
 		replicas:          make(map[string]*m.Node, 0),
 		replicasCh:        make(chan []*m.Node),
 		storageDir:        storageDir,
 		pluginsDir:        pluginsDir,
 		computeStorageDir: computeStorageDir,
+		sealedChunks:      make(map[string]string),
+		sealedCounts:      make(map[string]int),
 	}
+	for _, chunk := range storageIO.ScanMetadata() {
+		if chunk.Encrypted {
+			sn.addSealed(chunk)
+		}
+	}
+	return sn
 }
 
 func (sn *StorageNodeImpl) Start() {
 	logrus.WithFields(logrus.Fields{"DFS Storage": sn.storageDir, "Plugins Storage": sn.pluginsDir, "Compute Storage": sn.computeStorageDir}).Info("Storage DIRS")
+	sn.Heartbeats() // before registering, so that Stop interrupts the retries
 	sn.Register()
-	sn.Heartbeats()
 	go sn.worker()
 	sn.statsBoard.Start()
 	logrus.WithFields(logrus.Fields{"PORT": sn.server.GetPort(), "UUID": sn.uuid}).Info("Storage Node Started")
@@ -83,21 +104,45 @@ func (sn *StorageNodeImpl) Stop() {
 	sn.stopHeartbeats()
 }
 
+/** Controllers may still be starting (or electing a leader): keeps trying until one takes us */
 func (sn *StorageNodeImpl) Register() {
+	sn.reconnect()
+}
+
+/** Registration carries the full block report so the Controller can rebuild its index */
+func (sn *StorageNodeImpl) register() error {
 	logrus.WithFields(logrus.Fields{"controllerAddr": sn.controllerAddr}).Info("Sending registration message")
 	msgHandler, err := m.GetMessageHandlerFor(sn.controllerAddr)
-	defer msgHandler.Close()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"error": err.Error()}).Error("Could not connect to Controller. Reason:")
-		os.Exit(1)
+		return err
 	}
+	defer msgHandler.Close()
 	hostname := sn.server.GetHostname()
 	port := sn.server.GetPort()
-	if e := msgHandler.SendRegistrationMessage(sn.uuid, hostname, port); e != nil {
-		logrus.Error("Could not register to Controller")
-		os.Exit(1)
+	if e := msgHandler.SendRegistrationMessage(sn.uuid, hostname, port, sn.storageIO.ScanMetadata()); e != nil {
+		return e
 	}
 	logrus.Info("Registered successfully to Controller")
+	return nil
+}
+
+/**
+* Finds the leading controller again after losing the current one (restart,
+* failover, or it forgot about us) and re-registers with a full block report.
+* Backs off between attempts. Returns false if the node is stopped meanwhile.
+ */
+func (sn *StorageNodeImpl) reconnect() bool {
+	return helpers.RetryWithBackoff("Controller", func() error {
+		leader, err := m.FindLeader(sn.controllerAddrs)
+		if err != nil {
+			return err
+		}
+		if leader != sn.controllerAddr {
+			logrus.WithFields(logrus.Fields{"from": sn.controllerAddr, "to": leader}).Info("Controller failover")
+		}
+		sn.controllerAddr = leader
+		return sn.register()
+	}, sn.heartbeatStatusCh)
 }
 
 func (sn *StorageNodeImpl) handleConnection(msgHandler *m.MessageHandler) {
@@ -127,7 +172,9 @@ func (sn *StorageNodeImpl) handleConnection(msgHandler *m.MessageHandler) {
 			case m.ActionType_RM:
 				sn.handleRemoveRequest(chunkName)
 			case m.ActionType_PUT:
-				sn.handlePutRequest(chunk)
+				sn.handlePutRequest(msgHandler, chunk)
+			case m.ActionType_REPLICATE:
+				sn.handleReplicateRequest(chunkName, actionRequest.Replication, actionRequest.Targets)
 			case m.ActionType_COMPUTE:
 				if computeType == m.ComputeType_MAP {
 					go sn.handleMapRequest(msgHandler, actionRequest)
@@ -135,16 +182,20 @@ func (sn *StorageNodeImpl) handleConnection(msgHandler *m.MessageHandler) {
 					filenames := actionRequest.FileNames
 					reducerNumber := actionRequest.ReducerNumber
 					outputFilename := actionRequest.OutputFilename
-					go sn.handleReduceRequest(msgHandler, filenames, plugin, reducerNumber, outputFilename)
+					go sn.handleReduceRequest(msgHandler, actionRequest.JobId, filenames, plugin, reducerNumber, outputFilename)
 				} else {
 					logrus.Error("Invalid compute type")
 					msgHandler.Close()
 					return
 				}
+			case m.ActionType_SAMPLE:
+				sn.handleSampleRequest(msgHandler, chunkName, actionRequest.SampleSize)
+			case m.ActionType_DISCARD:
+				sn.handleDiscardRequest(msgHandler, actionRequest.JobId, filename)
 			case m.ActionType_COMPUTE_STORE:
 				filename := actionRequest.FileName
 				data := actionRequest.Data
-				go sn.storeMapperOutput(filename, data)
+				go sn.storeMapperOutput(msgHandler, actionRequest.JobId, filename, data)
 			}
 		case nil:
 			msgHandler.Close()
@@ -157,13 +208,19 @@ func (sn *StorageNodeImpl) handleConnection(msgHandler *m.MessageHandler) {
 }
 
 func (sn *StorageNodeImpl) handleGetRequest(messageHandler *m.MessageHandler, chunkName string) {
-	file, err := sn.storageIO.Retrieve(sn.storageDir + chunkName)
+	file, err := sn.storageIO.Retrieve(chunkName)
 	if err != nil {
 		messageHandler.SendFailAck(err.Error())
 		return
 	}
 	chunk := &m.Chunk{}
 	err = proto.Unmarshal(file, chunk)
+	if err == nil {
+		err = verifyChecksum(chunk)
+	}
+	if err == nil {
+		err = sn.chunkCipher.Open(chunk)
+	}
 	if err != nil {
 		messageHandler.SendFailAck(err.Error())
 	} else {
@@ -172,19 +229,82 @@ func (sn *StorageNodeImpl) handleGetRequest(messageHandler *m.MessageHandler, ch
 	}
 }
 
+/** The data key of an encrypted file goes with the last of its chunks stored here */
 func (sn *StorageNodeImpl) handleRemoveRequest(chunkName string) {
-	sn.storageIO.Delete(sn.storageDir + chunkName)
+	sn.keysMutex.Lock()
+	defer sn.keysMutex.Unlock()
+	sn.storageIO.Delete(chunkName)
+	filename, last := sn.removeSealed(chunkName)
+	if !last {
+		return
+	}
+	if err := sn.chunkCipher.Forget(filename); err != nil {
+		logrus.WithFields(logrus.Fields{"Filename": filename, "ErrorMsg": err.Error()}).Error("Error removing data key")
+	}
 }
 
-func (sn *StorageNodeImpl) handlePutRequest(chunk *m.Chunk) {
-	bytes, _ := proto.Marshal(chunk)
-	sn.storageIO.Persist(sn.storageDir+chunk.ChunkName, bytes)
+func (sn *StorageNodeImpl) addSealed(chunk *m.Chunk) {
+	sn.sealedMutex.Lock()
+	defer sn.sealedMutex.Unlock()
+	if _, present := sn.sealedChunks[chunk.ChunkName]; present {
+		return // overwritten copy
+	}
+	sn.sealedChunks[chunk.ChunkName] = chunk.FileName
+	sn.sealedCounts[chunk.FileName]++
+}
+
+/** Forgets an encrypted chunk; last tells whether it was the final one of its file here */
+func (sn *StorageNodeImpl) removeSealed(chunkName string) (filename string, last bool) {
+	sn.sealedMutex.Lock()
+	defer sn.sealedMutex.Unlock()
+	filename, present := sn.sealedChunks[chunkName]
+	if !present {
+		return "", false
+	}
+	delete(sn.sealedChunks, chunkName)
+	sn.sealedCounts[filename]--
+	if sn.sealedCounts[filename] > 0 {
+		return filename, false
+	}
+	delete(sn.sealedCounts, filename)
+	return filename, true
+}
+
+/** Every PUT is acked so senders learn about refused chunks (e.g. full disks) */
+func (sn *StorageNodeImpl) handlePutRequest(msgHandler *m.MessageHandler, chunk *m.Chunk) {
+	// replicas are sent in plaintext; each node seals chunks with its own keys
+	sealed := proto.Clone(chunk).(*m.Chunk)
+	if err := sn.sealAndPersist(sealed); err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunk.ChunkName, "ErrorMsg": err.Error()}).Error("Error persisting chunk")
+		msgHandler.SendFailAck("Storage Node " + sn.uuid + " refused " + chunk.ChunkName + ": " + err.Error())
+		return
+	}
+	msgHandler.SendSuccessAck()
 	sn.statsBoard.AddUploaded()
 	if chunk.StorageNodes == nil || len(chunk.StorageNodes) == 0 { // Replicate!
 		sn.replicate(chunk)
 	}
 }
 
+/** A removal can't drop the data key between sealing a chunk and storing it */
+func (sn *StorageNodeImpl) sealAndPersist(chunk *m.Chunk) error {
+	sn.keysMutex.RLock()
+	defer sn.keysMutex.RUnlock()
+	if err := sn.chunkCipher.Seal(chunk); err != nil {
+		return errors.New("could not encrypt: " + err.Error())
+	}
+	chunk.Checksum = crc32.ChecksumIEEE(chunk.Data)
+	bytes, _ := proto.Marshal(chunk)
+	if err := sn.storageIO.Persist(chunk.ChunkName, bytes); err != nil {
+		return err
+	}
+	if chunk.Encrypted {
+		sn.addSealed(chunk)
+	}
+	return nil
+}
+
+/** Sends chunk to other nodes until it reaches its replication factor (this node included) */
 func (sn *StorageNodeImpl) replicate(chunk *m.Chunk) {
 	chunk.StorageNodes = make(map[string]*m.Node, 0)
 	chunk.StorageNodes[sn.uuid] = &m.Node{
@@ -193,12 +313,16 @@ func (sn *StorageNodeImpl) replicate(chunk *m.Chunk) {
 		Port:     int32(sn.server.GetPort()),
 		Stats:    sn.statsBoard.GetAll(),
 	}
-	if len(sn.replicas) <= CHUNK_REPLICAS {
+	if chunk.Replication <= 0 {
+		chunk.Replication = common.DEFAULT_REPLICATION
+	}
+	extraCopies := int(chunk.Replication) - 1
+	if len(sn.replicas) <= extraCopies {
 		for _, replica := range sn.replicas {
 			sn.replicateAndUpdateStats(replica, chunk)
 		}
 	} else {
-		// getting 3 random replicas
+		// getting random replicas
 		// code turned out to be more confusing than should be
 		replicas := make([]string, len(sn.replicas))
 		i := 0
@@ -207,7 +331,7 @@ func (sn *StorageNodeImpl) replicate(chunk *m.Chunk) {
 			i++
 		}
 		isSelected := make(map[int]bool)
-		for len(isSelected) < CHUNK_REPLICAS {
+		for len(isSelected) < extraCopies {
 			rand.Seed(time.Now().UnixNano())
 			randIdx := rand.Intn(len(sn.replicas))
 			if _, present := isSelected[randIdx]; !present {
@@ -225,10 +349,55 @@ func (sn *StorageNodeImpl) replicateAndUpdateStats(node *m.Node, chunk *m.Chunk)
 		logrus.Error(err.Error())
 		return
 	}
+	defer msgHandler.Close()
 	msgHandler.SendChunkUploadRequest(chunk)
+	wrapper, _ := msgHandler.Receive()
+	if ack, ok := wrapper.Msg.(*m.Wrapper_AckMessage); !ok || !ack.AckMessage.Ok {
+		errorMsg := "no ack"
+		if ok {
+			errorMsg = ack.AckMessage.ErrorMessage
+		}
+		logrus.WithFields(logrus.Fields{"ChunkName": chunk.ChunkName, "Replica": node.Uuid, "ErrorMsg": errorMsg}).Error("Replica refused chunk")
+		return
+	}
 	sn.statsBoard.AddReplicated()
 }
 
+/** Controller asks for extra copies of a local chunk on the given targets */
+func (sn *StorageNodeImpl) handleReplicateRequest(chunkName string, replication int32, targets []*m.Node) {
+	file, err := sn.storageIO.Retrieve(chunkName)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Cannot replicate missing chunk")
+		return
+	}
+	chunk := &m.Chunk{}
+	if err = proto.Unmarshal(file, chunk); err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Error unmarshalling chunk")
+		return
+	}
+	if err = verifyChecksum(chunk); err != nil {
+		// copying it would spread the damage; the controller finds healthy copies elsewhere
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Cannot replicate corrupt chunk")
+		return
+	}
+	if err = sn.chunkCipher.Open(chunk); err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Error decrypting chunk")
+		return
+	}
+	chunk.Replication = replication
+	// a non-empty owners table keeps targets from replicating any further
+	chunk.StorageNodes = map[string]*m.Node{
+		sn.uuid: {
+			Uuid:     sn.uuid,
+			Hostname: sn.server.GetHostname(),
+			Port:     int32(sn.server.GetPort()),
+		},
+	}
+	for _, target := range targets {
+		sn.replicateAndUpdateStats(target, chunk)
+	}
+}
+
 func sendStatus(computeEngineConn *m.MessageHandler, computeType m.ComputeType) func(ok bool, err string) {
 	var jobStatus m.JobStatus
 	if computeType == m.ComputeType_MAP {
@@ -259,85 +428,222 @@ func (sn *StorageNodeImpl) handleMapRequest(
 	computeType := actionRequest.ComputeType
 	chunkName := actionRequest.FileName
 	updateComputeStatus := sendStatus(computeEngineConn, computeType)
-	chunkPath := sn.storageDir + chunkName
 
-	/**
-	* we need to extract data from local chunk protos and store it somewhere
-	* for mapper to read - I'm sorry for this
-	 */
-
-	/** Get local chunk */
-	file, err := os.ReadFile(chunkPath)
+	/** Mappers read the chunk from memory: decrypted data never touches the disk */
+	data, err := sn.readChunkText(chunkName)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"ChunkName": chunkName}).Error("Error reading local chunk")
-		updateComputeStatus(false, "Error reading local chunk")
-		return
-	}
-
-	/** Parse local byte chunk into actually Chunk message */
-	chunk := &m.Chunk{}
-	err = proto.Unmarshal(file, chunk)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{"ChunkName": chunkPath, "ErrorMsg": err.Error()}).Error("Error unmarshalling chunk")
-		updateComputeStatus(false, "Error reading local chunk")
-		return
-	}
-
-	/** Persist data (this is absurd) to compute storage dir */
-	dataPath := sn.computeStorageDir + chunkName
-	os.MkdirAll(filepath.Dir(dataPath), os.ModePerm)
-	err = os.WriteFile(dataPath, chunk.Data, os.ModePerm)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{"DataPath": dataPath, "ErrorMsg": err.Error()}).Error("Copying chunk data to Compute Storage dir")
-		updateComputeStatus(false, "Error handling local chunks")
+		updateComputeStatus(false, err.Error())
 		return
 	}
+	jobDir := sn.jobComputeDir(actionRequest.JobId)
+	attemptName := compute_engine.AttemptOutputName(chunkName, actionRequest.Attempt)
 
-	/** Persist plugin only if it doesn't exist */
-	pluginName := helpers.GetFilename(chunkName) + "-" + helpers.GetFilename(plugin.Name)
-	pluginDir := sn.pluginsDir + "/" + pluginName + "-" + helpers.GetFilename(chunkName)
-	if _, err := os.Stat(pluginDir); errors.Is(err, os.ErrNotExist) {
+	/** Persist plugin only if it doesn't exist; streaming jobs have none */
+	pluginName := helpers.GetFilename(plugin.Name) + "-" + attemptName
+	pluginDir := sn.jobPluginsDir(actionRequest.JobId) + "/" + pluginName
+	if _, err := os.Stat(pluginDir); plugin.Streaming == nil && errors.Is(err, os.ErrNotExist) {
 		logrus.WithFields(logrus.Fields{"PluginName": pluginName}).Info("Persisting plugin")
-		sn.storageIO.Persist(pluginDir, plugin.Plugin)
+		persistPlugin(pluginDir, plugin.Plugin)
 	}
 
 	/** Preparing Compute Engine */
-	context := compute_engine.NewContext(sn.computeStorageDir)
+	context := compute_engine.NewContext(jobDir)
 	context.SetNodeUuid(sn.uuid)
-	context.SetComputeOutputFilename(chunkName)
+	context.SetJobId(actionRequest.JobId)
+	context.SetComputeOutputFilename(attemptName)
 	context.SetReducerNodes(actionRequest.Reducers)
+	partitioner, err := compute_engine.NewPartitioner(plugin.Partitioner, len(actionRequest.Reducers))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Invalid partitioner")
+		updateComputeStatus(false, err.Error())
+		return
+	}
+	context.SetPartitioner(partitioner)
 	computeEngine := compute_engine.NewComputeEngine(context)
+	/** A failed attempt must not leave partitions its retry would append to */
+	shuffled := false
+	defer func() {
+		if shuffled {
+			return // local reducers read their partitions in place
+		}
+		for path := range context.GetMapperOutputFiles() {
+			os.Remove(path)
+		}
+	}()
 
 	logrus.WithFields(logrus.Fields{"Phase": computeType.String()}).Info("Starting Run Mapper")
 	/** Compute Engine Ready for Execution */
-	err = computeEngine.RunMapper(pluginDir, dataPath)
+	if plugin.Streaming != nil {
+		err = computeEngine.RunStreamingMapper(plugin.Streaming.Mapper, bytes.NewReader(data))
+	} else {
+		err = computeEngine.RunMapper(pluginDir, bytes.NewReader(data))
+	}
 	if err != nil {
 		logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Mapper phase error")
 		updateComputeStatus(false, err.Error())
 		return
 	}
+	/** Combiners shrink each partition before it goes through the network */
+	var counters *m.Counters
+	if plugin.Streaming != nil && plugin.Streaming.Combiner != "" {
+		counters, err = computeEngine.RunStreamingCombiner(plugin.Streaming.Combiner)
+	} else if plugin.Streaming == nil && plugin.Combine {
+		counters, err = computeEngine.RunCombiner(pluginDir)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkName, "ErrorMsg": err.Error()}).Error("Combiner error")
+		updateComputeStatus(false, err.Error())
+		return
+	}
 	logrus.WithFields(logrus.Fields{"Phase": computeType.String()}).Info("Starting Shuffling")
-	filesTable := computeEngine.Shuffle()
+	filesTable, err := computeEngine.Shuffle(actionRequest.Partitions)
+	if err != nil {
+		updateComputeStatus(false, err.Error())
+		return
+	}
+	shuffled = true
 	logrus.WithFields(logrus.Fields{"ReducerNodes": actionRequest.Reducers}).Info("Shuffling complete")
 
 	/** Notify resource manager of the mapper output files */
-	computeEngineConn.SendMappersOutputTable(filesTable)
+	computeEngineConn.SendMappersOutputTable(filesTable, counters)
 	logrus.Info("Sent mappers output files table to Resource Manager")
 
 	/** We are done! */
-	os.Remove(dataPath)  // perhaps we could have deferred it right under declaration
 	os.Remove(pluginDir) // perhaps we could have deferred it right under declaration
 	logrus.WithFields(logrus.Fields{"Chunk": chunkName, "Job": computeType.String()}).Info("Compute Complete")
 }
 
+/** Local chunk data as mappers and samplers read it: verified, decrypted and decompressed */
+func (sn *StorageNodeImpl) readChunkText(chunkName string) ([]byte, error) {
+	chunkPath := sn.storageDir + chunkName
+	/** Get local chunk */
+	file, err := sn.storageIO.Retrieve(chunkName)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkName}).Error("Error reading local chunk")
+		return nil, errors.New("Error reading local chunk")
+	}
+
+	/** Parse local byte chunk into actually Chunk message */
+	chunk := &m.Chunk{}
+	err = proto.Unmarshal(file, chunk)
+	if err == nil {
+		err = verifyChecksum(chunk)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkPath, "ErrorMsg": err.Error()}).Error("Error unmarshalling chunk")
+		return nil, errors.New("Error reading local chunk")
+	}
+	if err = sn.chunkCipher.Open(chunk); err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkPath, "ErrorMsg": err.Error()}).Error("Error decrypting chunk")
+		return nil, errors.New("Error decrypting local chunk")
+	}
+
+	/** Mapper reads plain text */
+	data, err := compression.Decompress(chunk.Codec, chunk.Data)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"ChunkName": chunkPath, "Codec": chunk.Codec, "ErrorMsg": err.Error()}).Error("Error decompressing chunk")
+		return nil, errors.New("Error decompressing local chunk")
+	}
+	return data, nil
+}
+
+/**
+* Answers with up to sampleSize keys picked uniformly at random (reservoir
+* sampling) from the lines of a local chunk. The key of a line is its text up
+* to the first tab.
+ */
+func (sn *StorageNodeImpl) handleSampleRequest(msgHandler *m.MessageHandler, chunkName string, sampleSize int32) {
+	data, err := sn.readChunkText(chunkName)
+	if err != nil {
+		msgHandler.SendFailAck(err.Error())
+		return
+	}
+	keys := make([]string, 0, sampleSize)
+	scanner := extsort.NewScanner(bytes.NewReader(data))
+	for seen := 0; scanner.Scan(); seen++ {
+		key, _, _ := strings.Cut(scanner.Text(), "\t")
+		if len(keys) < int(sampleSize) {
+			keys = append(keys, key)
+		} else if i := rand.Intn(seen + 1); i < len(keys) {
+			keys[i] = key
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		msgHandler.SendFailAck(err.Error())
+		return
+	}
+	msgHandler.SendSamples(keys)
+}
+
+/** Plugins are executables, not chunks: they go straight to the plugins dir */
+func persistPlugin(pluginPath string, plugin []byte) {
+	if err := helpers.CreatePaths(helpers.GetPathFrom(pluginPath)); err != nil {
+		logrus.Error(err.Error())
+		return
+	}
+	if err := os.WriteFile(pluginPath, plugin, os.ModePerm); err != nil {
+		logrus.Error(err.Error())
+	}
+}
+
+/**
+* Each job keeps its intermediate files (chunk copies, mapper outputs, merge
+* files, plugins) under its own directories, named after the job id.
+ */
+func (sn *StorageNodeImpl) jobComputeDir(jobId string) string {
+	return sn.computeStorageDir + "/" + helpers.GetFilename(jobId)
+}
+
+func (sn *StorageNodeImpl) jobPluginsDir(jobId string) string {
+	return sn.pluginsDir + "/" + helpers.GetFilename(jobId)
+}
+
+/**
+* Drops the mapper output files a map attempt shuffled here once another
+* attempt of its task won, or every file of the job once it has ended.
+ */
+func (sn *StorageNodeImpl) handleDiscardRequest(msgHandler *m.MessageHandler, jobId string, attemptName string) {
+	if helpers.GetFilename(jobId) == "" {
+		msgHandler.SendFailAck("Discard needs a job id")
+		return
+	}
+	if attemptName == "" {
+		logrus.WithFields(logrus.Fields{"JobId": jobId}).Info("Discarding job files")
+		os.RemoveAll(sn.jobComputeDir(jobId))
+		os.RemoveAll(sn.jobPluginsDir(jobId))
+		msgHandler.SendSuccessAck()
+		return
+	}
+	pattern := sn.jobComputeDir(jobId) + "/" + helpers.GetFilename(attemptName) + compute_engine.PARTITION_SUFFIX + "*"
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		msgHandler.SendFailAck(err.Error())
+		return
+	}
+	for _, path := range paths {
+		logrus.WithFields(logrus.Fields{"Filename": path}).Info("Discarding mapper output file")
+		os.Remove(path)
+	}
+	msgHandler.SendSuccessAck()
+}
+
+/** Acks once the file is on disk: the reducer may be started right after */
 func (sn *StorageNodeImpl) storeMapperOutput(
+	msgHandler *m.MessageHandler,
+	jobId string,
 	filename string,
 	data []byte,
 ) {
 	logrus.WithFields(logrus.Fields{"Filename": filename}).Info("Persisting Mapper output file")
-	helpers.CreatePaths(sn.computeStorageDir)
-	filename = sn.computeStorageDir + "/" + filename
-	os.WriteFile(filename, data, os.ModePerm)
+	jobDir := sn.jobComputeDir(jobId)
+	helpers.CreatePaths(jobDir)
+	filename = jobDir + "/" + filename
+	if err := os.WriteFile(filename, data, os.ModePerm); err != nil {
+		logrus.WithFields(logrus.Fields{"Filename": filename, "ErrorMsg": err.Error()}).Error("Error persisting Mapper output file")
+		msgHandler.SendFailAck(err.Error())
+		return
+	}
+	msgHandler.SendSuccessAck()
 }
 
 /*
@@ -351,182 +657,145 @@ TODO: refactor this massive function into smaller functions
 */
 func (sn *StorageNodeImpl) handleReduceRequest(
 	computeEngineConn *m.MessageHandler,
+	jobId string,
 	filenames []string,
 	plugin *m.Plugin,
 	reducerNumber int32,
 	outputFilename string,
 ) {
 	/** converted to absolute paths */
+	jobDir := sn.jobComputeDir(jobId)
 	for i, filename := range filenames {
-		filenames[i] = sn.computeStorageDir + "/" + filename
+		filenames[i] = jobDir + "/" + filename
 	}
 	logrus.Info("Initiating Reduce Phase")
 	/* TODO: check if filenames is empty */
 	defer computeEngineConn.Close()
 	updateComputeStatus := sendStatus(computeEngineConn, m.ComputeType_REDUCE)
 
-	logrus.Info("Initiating merge of Mappers Output")
-	/* Maps a mapper output file scanner to the next key in the file */
-	mergeMap := make(map[*bufio.Scanner]string)
-
-	/* Create a scanner for each mapper output file and read the first line */
-	logrus.WithFields(logrus.Fields{"Filenames": filenames}).Info("Opening mapper outputs")
-	files := []*os.File{}
-	for _, filename := range filenames {
-		file, err := os.Open(filename)
-		files = append(files, file)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{"Filename": filename}).Error("Cannot open file")
-			updateComputeStatus(false, err.Error())
-			return
-		}
-
-		scanner := bufio.NewScanner(file)
-		buf := make([]byte, 0, 64*1024)
-		scanner.Buffer(buf, 1024*1024)
-		scanner.Scan()
-		mergeMap[scanner] = scanner.Text()
-	}
-
-	mergedFilePath := sn.computeStorageDir + plugin.Name + "-mergefile-" + strconv.Itoa(int(reducerNumber))
+	logrus.WithFields(logrus.Fields{"Filenames": filenames}).Info("Initiating merge of Mappers Output")
+	helpers.CreatePaths(jobDir) // a reducer without input got no shuffled file
+	mergedFilePath := jobDir + "/" + helpers.GetFilename(plugin.Name) + "-mergefile-" + strconv.Itoa(int(reducerNumber))
 	mergedFile, err := os.Create(mergedFilePath)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"Filename": mergedFile}).Error("Cannot create merged file")
+		logrus.WithFields(logrus.Fields{"Filename": mergedFilePath}).Error("Cannot create merged file")
 		updateComputeStatus(false, err.Error())
 		return
 	}
-
-	/* Merge the Mapper output files into a single file */
-	for len(mergeMap) != 0 {
-		var smallestScanner *bufio.Scanner
-		var smallestText string
-		for scanner, text := range mergeMap {
-			if smallestText == "" || strings.Compare(text, smallestText) == -1 {
-				smallestText = text
-				smallestScanner = scanner
-			}
-		}
-		if smallestText != "" {
-			mergedFile.WriteString(smallestText + "\n")
-		}
-		if smallestScanner.Scan() {
-			/* There are more lines in this file, update mergeMap */
-			mergeMap[smallestScanner] = smallestScanner.Text()
-		} else {
-			/* There are no more lines in this file, remove it from mergeMap */
-			delete(mergeMap, smallestScanner)
-		}
-	}
-	for _, file := range files {
-		file.Close()
-	}
+	err = extsort.MergeFiles(filenames, mergedFile)
 	mergedFile.Close()
-
-	// TODO: move sorting to separate method
-	sortedFilePath := sn.computeStorageDir + plugin.Name + "-sortfile-" + strconv.Itoa(int(reducerNumber))
-	sortedFile, err := os.Create(sortedFilePath)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"Filename": sortedFile}).Error("Cannot create sorted file")
+		logrus.WithFields(logrus.Fields{"ErrorMsg": err.Error()}).Error("Cannot merge mapper outputs")
 		updateComputeStatus(false, err.Error())
 		return
 	}
-	mergedFile, _ = os.Open(mergedFilePath)
-	scanner := bufio.NewScanner(mergedFile)
-	var currentOutputKey string
-	var outputLine string
-	for scanner.Scan() {
-		inputLine := scanner.Text()
-		i := 0
-		for j, v := range inputLine { // get index of last tab
-			if string(v) == "\t" {
-				i = j
-			}
-		}
-		inputLineKey := inputLine[:i]
-		inputLineValue := inputLine[i+1:]
-
-		// TODO: create a slice to store values list instead of string concatenation
-		if strings.Compare(inputLineKey, currentOutputKey) == 0 {
-			outputLine += "\t" + inputLineValue
-		} else {
-			currentOutputKey = inputLineKey
-			if outputLine != "" {
-				sortedFile.WriteString(outputLine + "\n")
-			}
-			outputLine = inputLineKey + "\t" + inputLineValue
-		}
-	}
+
 	for _, filename := range filenames {
 		logrus.WithFields(logrus.Fields{"Filename": filename}).Info("Deleting output mapper file")
 		os.Remove(filename)
 	}
-	mergedFile.Close()
-	sortedFile.Close()
 	logrus.Info("Merged mappers output files")
 
+	/** Streaming reducers read the merged pairs; plugins get each key with all its values */
+	sortedFilePath := jobDir + "/" + helpers.GetFilename(plugin.Name) + "-sortfile-" + strconv.Itoa(int(reducerNumber))
+	if plugin.Streaming == nil {
+		if err := compute_engine.GroupByKey(mergedFilePath, sortedFilePath); err != nil {
+			logrus.WithFields(logrus.Fields{"Filename": sortedFilePath, "ErrorMsg": err.Error()}).Error("Cannot create sorted file")
+			updateComputeStatus(false, err.Error())
+			return
+		}
+	}
+
 	/** Persist plugin only if it doesn't exist */
-	pluginDir := sn.pluginsDir + plugin.Name + "-reducer-" + strconv.Itoa(int(reducerNumber))
-	if _, err := os.Stat(pluginDir); errors.Is(err, os.ErrNotExist) {
+	pluginDir := sn.jobPluginsDir(jobId) + "/" + helpers.GetFilename(plugin.Name) + "-reducer-" + strconv.Itoa(int(reducerNumber))
+	if _, err := os.Stat(pluginDir); plugin.Streaming == nil && errors.Is(err, os.ErrNotExist) {
 		logrus.WithFields(logrus.Fields{"Path": pluginDir}).Info("New plugin")
-		sn.storageIO.Persist(pluginDir, plugin.Plugin)
+		persistPlugin(pluginDir, plugin.Plugin)
 	}
 
 	/** Preparing Compute Engine */
-	context := compute_engine.NewContext(sn.computeStorageDir)
-	outputFilePath := outputFilename + "-" + strconv.Itoa(int(reducerNumber))
+	context := compute_engine.NewContext(jobDir)
+	outputFilePath := compute_engine.ReducerOutputName(outputFilename, reducerNumber)
 	context.SetComputeOutputFilename(outputFilePath)
 	computeEngine := compute_engine.NewComputeEngine(context)
 
 	/** Compute Engine Ready for Execution */
 	logrus.WithFields(logrus.Fields{"Phase": m.ComputeType_REDUCE.String()}).Info("Running reduce compute")
-	reduceErr := computeEngine.RunReducer(pluginDir, sortedFilePath)
-
-	if reduceErr == nil {
-		logrus.Info("Reducer completed successfully")
-		updateComputeStatus(true, "")
+	// reducers append to their output: drop whatever a failed attempt left
+	os.Remove(context.GetComputeOutputFilename())
+	defer os.Remove(context.GetComputeOutputFilename())
+	var reduceErr error
+	if plugin.Streaming != nil {
+		reduceErr = computeEngine.RunStreamingReducer(plugin.Streaming.Reducer, mergedFilePath)
 	} else {
-		logrus.WithFields(logrus.Fields{"Error": reduceErr.Error()}).Error("Error in reducer")
-		updateComputeStatus(false, reduceErr.Error())
+		reduceErr = computeEngine.RunReducer(pluginDir, sortedFilePath)
 	}
 
-	logrus.WithFields(logrus.Fields{"File": outputFilePath}).Info("Compute complete!")
-
 	/** Cleanup intermediate files */
 	os.Remove(mergedFilePath)
 	os.Remove(sortedFilePath)
 	os.Remove(pluginDir)
 
-	/* Upload reducer output to DFS */
-	msgHandler, err := m.GetMessageHandlerFor(sn.controllerAddr)
-	if err != nil {
-		logrus.Error("Controller down. Going to standby mode.")
-		sn.stopHeartbeats()
+	if reduceErr != nil {
+		logrus.WithFields(logrus.Fields{"Error": reduceErr.Error()}).Error("Error in reducer")
+		updateComputeStatus(false, reduceErr.Error())
+		return
+	}
+	logrus.WithFields(logrus.Fields{"File": outputFilePath}).Info("Compute complete!")
+
+	/* Upload reducer output to DFS; the task is only done once the output is there */
+	if err := sn.uploadReducerOutput(computeEngineConn, context.GetComputeOutputFilename(), outputFilePath); err != nil {
+		logrus.WithFields(logrus.Fields{"File": outputFilePath, "ErrorMsg": err.Error()}).Error("Reducer output not uploaded")
+		updateComputeStatus(false, "Reducer output not uploaded: "+err.Error())
 		return
 	}
-	msgHandler.SendPUTRequest(outputFilePath)
+	logrus.Info("Reducer completed successfully")
+	updateComputeStatus(true, "")
+}
+
+/**
+* Reducers emitting nothing still leave an (empty) output file. Once the
+* controller takes remotePath the compute engine hears of it: a retry has to
+* remove whatever part of the output this attempt got to upload.
+ */
+func (sn *StorageNodeImpl) uploadReducerOutput(computeEngineConn *m.MessageHandler, localPath, remotePath string) error {
+	output, err := os.OpenFile(localPath, os.O_CREATE|os.O_RDONLY, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	output.Close()
+	msgHandler, err := m.GetLeaderMessageHandler(sn.controllerAddrs)
+	if err != nil {
+		return errors.New("controller down")
+	}
+	defer msgHandler.Close()
+	var outputSize int64 = 0
+	if fileInfo, err := os.Stat(localPath); err == nil {
+		outputSize = fileInfo.Size()
+	}
+	msgHandler.SendPUTRequest(remotePath, 0, "", outputSize)
 	wrapper, _ := msgHandler.Receive()
 
 	switch msg := wrapper.Msg.(type) {
 	case *m.Wrapper_AckMessage:
-		errorMsg := msg.AckMessage.ErrorMessage
-		logrus.Error(errorMsg)
+		return errors.New(msg.AckMessage.ErrorMessage)
 	case *m.Wrapper_StorageNodesMessage:
+		computeEngineConn.SendComputationStatus(m.JobStatus_job_uploading, true, "")
 		storageNodes := msg.StorageNodesMessage.Nodes
-		chunkinator := c.NewChunkinator(context.GetComputeOutputFilename(), outputFilePath)
-		uploader := c.NewUploader(storageNodes, chunkinator)
-		err := uploader.Upload()
+		chunkinator, err := c.NewChunkinatorFor(localPath, remotePath, 0, msg.StorageNodesMessage.StoragePolicy, "")
 		if err != nil {
-			logrus.Error("Upload error! " + err.Error())
-		} else {
-			logrus.Info("Reducer output uploaded to DFS successfully")
+			return err
 		}
-	case nil:
-		if err := msgHandler.Close(); err != nil {
-			logrus.Error(err.Error())
+		uploader := c.NewUploader(storageNodes, chunkinator)
+		if err := uploader.Upload(); err != nil {
+			return err
 		}
-	default:
-		logrus.Error("Unrecognized response from server")
+		logrus.Info("Reducer output uploaded to DFS successfully")
+		return nil
+	case nil:
+		return errors.New("no response from controller")
 	}
+	return errors.New("unrecognized response from server")
 }
 
 func (sn *StorageNodeImpl) worker() {
@@ -535,7 +804,16 @@ func (sn *StorageNodeImpl) worker() {
 		case <-sn.heartbeatStatusCh: // stops heartbeats
 			return
 		case <-sn.heartbeatScheduler.C:
-			sn.handleHeartbeat()
+			if err := sn.handleHeartbeat(); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"controllerAddr": sn.controllerAddr,
+					"error":          err.Error(),
+				}).Warn("Lost Controller. Reconnecting...")
+				if !sn.reconnect() {
+					return // stopped while reconnecting
+				}
+				continue
+			}
 			logrus.WithFields(logrus.Fields{"controllerAddr": sn.controllerAddr}).Info("Sent heartbeat")
 		case replicas := <-sn.replicasCh:
 			sn.handleReplicaTable(replicas)
@@ -548,38 +826,43 @@ func (sn *StorageNodeImpl) Heartbeats() {
 	sn.heartbeatScheduler = time.NewTicker(HEARTBEAT_DELAY_S * time.Second)
 	sn.heartbeatStatusCh = make(chan bool)
 }
-func (sn *StorageNodeImpl) handleHeartbeat() {
+
+/** Errors mean the Controller is gone or no longer takes our heartbeats */
+func (sn *StorageNodeImpl) handleHeartbeat() error {
 	msgHandler, err := m.GetMessageHandlerFor(sn.controllerAddr)
 	if err != nil {
-		logrus.Error("Controller down. Going to standby mode.")
-		sn.stopHeartbeats()
-		return
+		return err
 	}
+	defer msgHandler.Close()
 	e := msgHandler.SendHeartbeat(
 		sn.uuid,
 		sn.server.GetHostname(),
 		sn.server.GetPort(),
-		sn.storageIO.ScanMetadata(sn.storageDir),
+		sn.storageIO.ScanMetadata(),
 		sn.statsBoard.GetAll(),
 	)
 	if e != nil {
-		sn.stopHeartbeats()
-		logrus.Error("Controller down. Going to standby mode.")
-		return
+		return e
 	}
 
 	// expects in response information about other storageIO nodes in cluster
 	// * ideally, implement timeout here
 	wrapper, err := msgHandler.Receive()
+	if err != nil {
+		return err
+	}
 	switch msg := wrapper.Msg.(type) {
 	case *m.Wrapper_StorageNodesMessage:
 		go func() {
 			sn.replicasCh <- msg.StorageNodesMessage.Nodes
 		}()
+	case *m.Wrapper_AckMessage:
+		// controller turned standby or restarted and does not know us anymore
+		return errors.New(msg.AckMessage.ErrorMessage)
 	default:
 		logrus.Error("Expected Online StorageIO Nodes back from Heartbeat")
 	}
-	msgHandler.Close()
+	return nil
 }
 
 func (sn *StorageNodeImpl) handleReplicaTable(replicas []*m.Node) {